@@ -1,16 +1,76 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"nexa/internal/api"
+	"nexa/internal/config"
 	"nexa/internal/database"
+	"nexa/internal/logger"
+	"nexa/internal/tracing"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/rs/zerolog/log"
 )
 
 func main() {
-	db := database.ConnectDB()
-	if db != nil {
-		fmt.Println("Successful connection!")
+	if err := run(); err != nil {
+		log.Error().Err(err).Msg("server exited with error")
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	logger.Init(cfg.IsProduction(), cfg.LogLevel)
+
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	db, err := database.ConnectDB(ctx, cfg.Database)
+	if err != nil {
+		return err
+	}
+	log.Info().Msg("Successful connection!")
+
+	if cfg.Database.AutoMigrate {
+		if err := database.Migrate(ctx, db); err != nil {
+			db.Close()
+			return err
+		}
+	}
+
+	server, err := api.NewServer(cfg, db)
+	if err != nil {
+		db.Close()
+		return err
 	}
 
-	api.SetupRoutes(db)
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.Start()
+	}()
+
+	select {
+	case err = <-serverErr:
+	case <-ctx.Done():
+		log.Info().Msg("Sinal de encerramento recebido, finalizando servidor...")
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.API.ShutdownTimeout)
+	defer cancel()
+
+	return errors.Join(err, server.Shutdown(shutdownCtx), shutdownTracing(shutdownCtx))
 }
@@ -1,34 +1,228 @@
 package api
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"nexa/internal/config"
 	"nexa/internal/handler"
-	"os"
+	"nexa/internal/handler/middleware"
+	"nexa/internal/i18n"
+	"nexa/internal/jobs"
+	"nexa/internal/metrics"
+	"nexa/internal/storage"
+	"nexa/internal/tracing"
+	"nexa/internal/utils"
+	"sync"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/jackc/pgx/v5"
-	"github.com/joho/godotenv"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
 )
 
-func SetupRoutes(db *pgx.Conn) {
-	_ = godotenv.Load()
-	port := os.Getenv("API_PORT")
+// Server concentra o app Fiber e as dependências cujo ciclo de vida ele
+// controla: pool do banco, servidor de e-mail e workers em segundo plano.
+type Server struct {
+	app        *fiber.App
+	cfg        *config.Config
+	db         *pgxpool.Pool
+	mailServer *utils.MailServer
+	storage    storage.ObjectStorage
+	dataExport *jobs.DataExport
+	recurring  *jobs.RecurringTransactions
 
-	app := fiber.New()
-	app.Use(cors.New())
+	workersCtx    context.Context
+	stopWorkers   context.CancelFunc
+	workers       sync.WaitGroup
+	shutdownOnce  sync.Once
+	shutdownError error
+}
+
+func NewServer(cfg *config.Config, db *pgxpool.Pool) (*Server, error) {
+	objectStorage, err := storage.New(cfg.Storage, cfg.Cloudinary)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize storage: %w", err)
+	}
+
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+
+	s := &Server{
+		app: fiber.New(fiber.Config{
+			ErrorHandler: utils.ErrorHandler,
+			// Folga sobre o maior upload aceito (imaging.Banner.MaxBytes).
+			BodyLimit: 10 << 20,
+		}),
+		cfg:         cfg,
+		db:          db,
+		mailServer:  utils.InitMailServer(cfg.SMTP),
+		storage:     objectStorage,
+		workersCtx:  workersCtx,
+		stopWorkers: stopWorkers,
+	}
+
+	s.dataExport = jobs.NewDataExport(db, objectStorage, s.mailServer, cfg.Export)
+	s.recurring = jobs.NewRecurringTransactions(db, cfg.Recurring)
+
+	metrics.RegisterDBPool(db)
+	s.setupRoutes()
+
+	return s, nil
+}
 
-	userHandler := handler.NewUserHandler(db)
+func (s *Server) setupRoutes() {
+	s.app.Use(middleware.RequestID)
+	s.app.Use(metrics.Middleware)
+	s.app.Use(tracing.Middleware)
+	s.app.Use(middleware.RequestLogger)
+	s.app.Use(cors.New())
+	s.app.Use(i18n.Middleware)
 
-	app.Get("/", func(c *fiber.Ctx) error {
+	authHandler := handler.NewUserAuthenticationHandler(s.db, s.mailServer, s.cfg)
+	userHandler := handler.NewUserHandler(s.db, s.cfg, s.storage, authHandler)
+	healthHandler := handler.NewHealthHandler(s.db, s.mailServer)
+	dataExportHandler := handler.NewDataExportHandler(s.db, s.dataExport.Notify)
+	statementImportHandler := handler.NewStatementImportHandler(s.db)
+	transactionExportHandler := handler.NewTransactionExportHandler(s.db)
+	recurringTransactionHandler := handler.NewRecurringTransactionHandler(s.db, s.recurring.Notify)
+	transferHandler := handler.NewTransferHandler(s.db)
+	walletHandler := handler.NewWalletHandler(s.db)
+	transactionHandler := handler.NewTransactionHandler(s.db)
+	reportHandler := handler.NewReportHandler(s.db)
+	exchangeRateHandler := handler.NewExchangeRateHandler(s.db)
+	requireAuth := middleware.NewJWTMiddleware(s.cfg.JWT.Secret, authHandler.UserRepository, authHandler.SettingsRepository)
+
+	s.app.Get("/", func(c *fiber.Ctx) error {
 		return c.SendString("🚀 Nexa API rodando com sucesso!")
 	})
 
-	app.Post("/user", userHandler.RegisterUser)
-	app.Post("/auth/login", userHandler.LoginUser)
+	s.app.Get("/healthz", healthHandler.Health)
+	s.app.Get("/readyz", healthHandler.Ready)
+	s.app.Get("/version", healthHandler.Version)
+	s.app.Get("/metrics", metrics.Handler())
+
+	s.app.Static(utils.AvatarPresetsRoute, "assets/avatars")
+	s.app.Static(utils.BannerPresetsRoute, "assets/banners")
+	if local, ok := s.storage.(*storage.LocalStorage); ok {
+		s.app.Static(storage.LocalRoute, local.Dir)
+	}
+
+	s.app.Post("/user", userHandler.RegisterUser)
+	s.app.Post("/auth/login", userHandler.LoginUser)
+
+	s.app.Get("/me", requireAuth, userHandler.GetMe)
+	s.app.Patch("/me", requireAuth, userHandler.UpdateMe)
+	s.app.Delete("/me", requireAuth, userHandler.DeactivateMe)
+	s.app.Post("/me/photo", requireAuth, userHandler.UploadUserImage)
+	s.app.Post("/me/banner", requireAuth, userHandler.UploadUserBanner)
+	s.app.Post("/me/email", requireAuth, userHandler.RequestEmailChange)
+	s.app.Post("/me/email/confirm", requireAuth, userHandler.ConfirmEmailChange)
+	s.app.Post("/me/export", requireAuth, dataExportHandler.RequestExport)
+	s.app.Get("/me/export/:id", requireAuth, dataExportHandler.GetExport)
+	s.app.Post("/me/imports", requireAuth, statementImportHandler.UploadStatement)
+	s.app.Post("/me/imports/:id/preview", requireAuth, statementImportHandler.PreviewImport)
+	s.app.Post("/me/imports/:id/commit", requireAuth, statementImportHandler.CommitImport)
+	s.app.Post("/me/wallets", requireAuth, walletHandler.CreateWallet)
+	s.app.Get("/me/wallets", requireAuth, walletHandler.ListWallets)
+	s.app.Post("/me/wallets/:walletId/transactions", requireAuth, transactionHandler.CreateTransaction)
+	s.app.Get("/me/wallets/:walletId/flow", requireAuth, walletHandler.GetMonthFlow)
+	s.app.Get("/me/wallets/:walletId/budgets", requireAuth, walletHandler.ListBudgets)
+	s.app.Get("/me/wallets/:walletId/transactions/export", requireAuth, transactionExportHandler.ExportTransactions)
+	s.app.Post("/me/recurring", requireAuth, recurringTransactionHandler.CreateRecurring)
+	s.app.Get("/me/recurring", requireAuth, recurringTransactionHandler.ListRecurring)
+	s.app.Delete("/me/recurring/:id", requireAuth, recurringTransactionHandler.StopRecurring)
+	s.app.Get("/me/recurring/:id/occurrences", requireAuth, recurringTransactionHandler.ListOccurrences)
+	s.app.Put("/me/recurring/:id/occurrences/:date", requireAuth, recurringTransactionHandler.UpdateOccurrence)
+	s.app.Post("/me/transfers", requireAuth, transferHandler.CreateTransfer)
+	s.app.Get("/me/transfers", requireAuth, transferHandler.ListTransfers)
+	s.app.Get("/me/transfers/:id", requireAuth, transferHandler.GetTransfer)
+	s.app.Patch("/me/transfers/:id", requireAuth, transferHandler.UpdateTransfer)
+	s.app.Delete("/me/transfers/:id", requireAuth, transferHandler.DeleteTransfer)
+	s.app.Get("/me/reports/net-worth", requireAuth, reportHandler.GetNetWorth)
+	s.app.Get("/exchange-rates", requireAuth, exchangeRateHandler.GetRate)
+	s.app.Get("/users/availability", userHandler.CheckAvailability)
+	s.app.Get("/users/:username", userHandler.GetPublicProfile)
+
+	if s.cfg.Admin.Enabled() {
+		requireAdmin := middleware.NewAdminKeyMiddleware(s.cfg.Admin.APIKey)
+		s.app.Put("/admin/exchange-rates", requireAdmin, exchangeRateHandler.UpsertRates)
+		s.app.Post("/admin/exchange-rates/import", requireAdmin, exchangeRateHandler.ImportRates)
+	}
+}
+
+// Go executa um worker em segundo plano. O contexto recebido é cancelado no
+// Shutdown, que aguarda o retorno de todos os workers.
+func (s *Server) Go(name string, worker func(ctx context.Context)) {
+	s.workers.Add(1)
+	go func() {
+		defer s.workers.Done()
+		worker(s.workersCtx)
+		log.Info().Str("worker", name).Msg("worker stopped")
+	}()
+}
+
+// startWorkers registra os jobs periódicos da aplicação.
+func (s *Server) startWorkers() {
+	s.Go("account-deletion", jobs.NewAccountDeletion(s.db, s.storage, s.cfg.Account).Run)
+	s.Go("data-export", s.dataExport.Run)
+	s.Go("recurring-transactions", s.recurring.Run)
+}
+
+// Start inicia os workers e bloqueia até o servidor parar. Retorna nil quando
+// a parada vem do Shutdown.
+func (s *Server) Start() error {
+	s.startWorkers()
+
+	log.Info().Str("port", s.cfg.API.Port).Msg("Servidor rodando")
+	if err := s.app.Listen(":" + s.cfg.API.Port); err != nil {
+		return fmt.Errorf("failed to start server: %w", err)
+	}
+
+	return nil
+}
+
+// Shutdown para de aceitar conexões, drena as requisições em andamento,
+// encerra os workers e fecha e-mail e banco, nessa ordem.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.shutdownOnce.Do(func() {
+		var errs []error
+
+		if err := s.app.ShutdownWithContext(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to drain http server: %w", err))
+		}
+
+		s.stopWorkers()
+		if err := waitGroupWithContext(ctx, &s.workers); err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop workers: %w", err))
+		}
+
+		if s.mailServer != nil {
+			if err := s.mailServer.Close(ctx); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		s.db.Close()
+
+		s.shutdownError = errors.Join(errs...)
+	})
+
+	return s.shutdownError
+}
+
+func waitGroupWithContext(ctx context.Context, wg *sync.WaitGroup) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
 
-	log.Printf("Servidor rodando na porta %s", port)
-	log.Fatal(app.Listen(":" + port))
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 // func SetupRoutes(client *mongo.Client) {
@@ -0,0 +1,414 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // o fuso do agendador não depende do tzdata do sistema
+
+	"github.com/joho/godotenv"
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v3"
+)
+
+// Config reúne toda a configuração da aplicação. A ordem de precedência é:
+// valores padrão < arquivo YAML opcional (CONFIG_FILE) < variáveis de ambiente
+// (incluindo as carregadas do .env).
+type Config struct {
+	Env        string           `yaml:"env" env:"APP_ENV"`
+	LogLevel   string           `yaml:"logLevel" env:"LOG_LEVEL"`
+	API        APIConfig        `yaml:"api"`
+	Database   DatabaseConfig   `yaml:"database"`
+	SMTP       SMTPConfig       `yaml:"smtp"`
+	Cloudinary CloudinaryConfig `yaml:"cloudinary"`
+	JWT        JWTConfig        `yaml:"jwt"`
+	Keys       KeysConfig       `yaml:"keys"`
+	Password   PasswordConfig   `yaml:"password"`
+	Tracing    TracingConfig    `yaml:"tracing"`
+	Storage    StorageConfig    `yaml:"storage"`
+	Account    AccountConfig    `yaml:"account"`
+	Export     ExportConfig     `yaml:"export"`
+	Recurring  RecurringConfig  `yaml:"recurring"`
+	Admin      AdminConfig      `yaml:"admin"`
+}
+
+type APIConfig struct {
+	Port            string        `yaml:"port" env:"API_PORT" required:"true"`
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout" env:"API_SHUTDOWN_TIMEOUT"`
+}
+
+type DatabaseConfig struct {
+	User     string `yaml:"user" env:"DB_USER" required:"true"`
+	Password string `yaml:"password" env:"DB_PASSWORD" required:"true"`
+	Host     string `yaml:"host" env:"DB_URL" required:"true"`
+	Port     string `yaml:"port" env:"DB_PORT" required:"true"`
+	Name     string `yaml:"name" env:"DB_NAME" required:"true"`
+
+	AutoMigrate bool `yaml:"autoMigrate" env:"DB_AUTO_MIGRATE"`
+}
+
+func (d DatabaseConfig) ConnString() string {
+	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s", d.User, d.Password, d.Host, d.Port, d.Name)
+}
+
+type SMTPConfig struct {
+	Server   string `yaml:"server" env:"SMTP_SERVER"`
+	Port     string `yaml:"port" env:"SMTP_PORT"`
+	User     string `yaml:"user" env:"SMTP_USER"`
+	Password string `yaml:"password" env:"SMTP_PASSWORD"`
+	From     string `yaml:"from" env:"SMTP_FROM"`
+}
+
+func (s SMTPConfig) Enabled() bool {
+	return s.Server != ""
+}
+
+type CloudinaryConfig struct {
+	CloudName string `yaml:"cloudName" env:"CLOUDINARY_CLOUD_NAME"`
+	APIKey    string `yaml:"apiKey" env:"CLOUDINARY_API_KEY"`
+	APISecret string `yaml:"apiSecret" env:"CLOUDINARY_API_SECRET"`
+}
+
+func (c CloudinaryConfig) Enabled() bool {
+	return c.CloudName != ""
+}
+
+type JWTConfig struct {
+	Secret string `yaml:"secret" env:"JWT_SECRET" required:"true"`
+}
+
+type KeysConfig struct {
+	PrivateKey string `yaml:"privateKey" env:"PRIVATE_KEY"`
+	PublicKey  string `yaml:"publicKey" env:"PUBLIC_KEY"`
+}
+
+type PasswordConfig struct {
+	Algorithm         string `yaml:"algorithm" env:"PASSWORD_HASH_ALGORITHM"`
+	Argon2MemoryKiB   int    `yaml:"argon2MemoryKiB" env:"ARGON2_MEMORY_KIB"`
+	Argon2Iterations  int    `yaml:"argon2Iterations" env:"ARGON2_ITERATIONS"`
+	Argon2Parallelism int    `yaml:"argon2Parallelism" env:"ARGON2_PARALLELISM"`
+	BcryptCost        int    `yaml:"bcryptCost" env:"BCRYPT_COST"`
+}
+
+// TracingConfig controla o OpenTelemetry. Exporter aceita "none", "stdout"
+// (depuração local) ou "otlp" (HTTP, em Endpoint).
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter" env:"OTEL_TRACES_EXPORTER"`
+	Endpoint    string  `yaml:"endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	Insecure    bool    `yaml:"insecure" env:"OTEL_EXPORTER_OTLP_INSECURE"`
+	ServiceName string  `yaml:"serviceName" env:"OTEL_SERVICE_NAME"`
+	SampleRatio float64 `yaml:"sampleRatio" env:"OTEL_TRACES_SAMPLE_RATIO"`
+}
+
+// StorageConfig escolhe onde ficam as imagens enviadas. Driver aceita
+// "cloudinary", "local" ou "s3"; vazio usa o Cloudinary quando configurado e,
+// caso contrário, o disco local.
+type StorageConfig struct {
+	Driver    string   `yaml:"driver" env:"STORAGE_DRIVER"`
+	LocalDir  string   `yaml:"localDir" env:"STORAGE_LOCAL_DIR"`
+	PublicURL string   `yaml:"publicURL" env:"STORAGE_PUBLIC_URL"`
+	S3        S3Config `yaml:"s3"`
+}
+
+type S3Config struct {
+	Endpoint        string `yaml:"endpoint" env:"S3_ENDPOINT"`
+	Region          string `yaml:"region" env:"S3_REGION"`
+	Bucket          string `yaml:"bucket" env:"S3_BUCKET"`
+	AccessKeyID     string `yaml:"accessKeyID" env:"S3_ACCESS_KEY_ID"`
+	SecretAccessKey string `yaml:"secretAccessKey" env:"S3_SECRET_ACCESS_KEY"`
+}
+
+// AccountConfig controla a exclusão de contas (LGPD): contas desativadas podem
+// ser reativadas por DeletionGraceDays dias e depois são apagadas pelo job que
+// roda a cada DeletionJobInterval.
+type AccountConfig struct {
+	DeletionGraceDays   int           `yaml:"deletionGraceDays" env:"ACCOUNT_DELETION_GRACE_DAYS"`
+	DeletionJobInterval time.Duration `yaml:"deletionJobInterval" env:"ACCOUNT_DELETION_JOB_INTERVAL"`
+}
+
+func (a AccountConfig) DeletionGracePeriod() time.Duration {
+	return time.Duration(a.DeletionGraceDays) * 24 * time.Hour
+}
+
+// ExportConfig controla a exportação de dados (LGPD): o link do arquivo vale
+// por LinkTTL e a fila é verificada a cada PollInterval, além de ser acordada
+// a cada novo pedido.
+type ExportConfig struct {
+	LinkTTL      time.Duration `yaml:"linkTTL" env:"DATA_EXPORT_LINK_TTL"`
+	PollInterval time.Duration `yaml:"pollInterval" env:"DATA_EXPORT_POLL_INTERVAL"`
+}
+
+// RecurringConfig controla o agendador de lançamentos recorrentes: a cada
+// PollInterval ele lança as ocorrências vencidas até o dia de hoje no fuso
+// Timezone.
+type RecurringConfig struct {
+	PollInterval time.Duration `yaml:"pollInterval" env:"RECURRING_POLL_INTERVAL"`
+	Timezone     string        `yaml:"timezone" env:"RECURRING_TIMEZONE"`
+}
+
+func (r RecurringConfig) Location() (*time.Location, error) {
+	return time.LoadLocation(r.Timezone)
+}
+
+// AdminConfig protege as rotas administrativas (/admin), que exigem APIKey no
+// cabeçalho X-Admin-Key. Sem APIKey, essas rotas não são registradas.
+type AdminConfig struct {
+	APIKey string `yaml:"apiKey" env:"ADMIN_API_KEY"`
+}
+
+func (a AdminConfig) Enabled() bool {
+	return a.APIKey != ""
+}
+
+// maxS3LinkTTL é a validade máxima de uma URL pré-assinada no S3 (SigV4).
+const maxS3LinkTTL = 7 * 24 * time.Hour
+
+// minAdminKeyLength evita chaves administrativas fáceis de adivinhar.
+const minAdminKeyLength = 32
+
+func Default() *Config {
+	return &Config{
+		Env:      "development",
+		LogLevel: "info",
+		API: APIConfig{
+			ShutdownTimeout: 15 * time.Second,
+		},
+		Database: DatabaseConfig{
+			AutoMigrate: true,
+		},
+		Password: PasswordConfig{
+			Algorithm:         "argon2id",
+			Argon2MemoryKiB:   64 * 1024,
+			Argon2Iterations:  3,
+			Argon2Parallelism: 2,
+			BcryptCost:        10,
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			ServiceName: "nexa-api",
+			SampleRatio: 1,
+		},
+		Account: AccountConfig{
+			DeletionGraceDays:   30,
+			DeletionJobInterval: time.Hour,
+		},
+		Export: ExportConfig{
+			LinkTTL:      7 * 24 * time.Hour,
+			PollInterval: time.Minute,
+		},
+		Recurring: RecurringConfig{
+			PollInterval: 15 * time.Minute,
+			Timezone:     "America/Sao_Paulo",
+		},
+		Storage: StorageConfig{
+			LocalDir: "uploads",
+			S3: S3Config{
+				Region: "us-east-1",
+			},
+		},
+	}
+}
+
+func (c *Config) IsProduction() bool {
+	return c.Env == "production"
+}
+
+// Load monta a configuração uma única vez na inicialização. No Render as
+// variáveis já vêm do ambiente, então o .env não é lido.
+func Load() (*Config, error) {
+	if os.Getenv("RENDER") == "" {
+		_ = godotenv.Load()
+	}
+
+	cfg := Default()
+
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if err := loadYAML(cfg, path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := applyEnv(reflect.ValueOf(cfg).Elem()); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func loadYAML(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func applyEnv(v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct {
+			if err := applyEnv(field); err != nil {
+				return err
+			}
+			continue
+		}
+
+		key := t.Field(i).Tag.Get("env")
+		raw, ok := os.LookupEnv(key)
+		if key == "" || !ok || raw == "" {
+			continue
+		}
+
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(raw)
+		case reflect.Int64:
+			if field.Type() == durationType {
+				d, err := time.ParseDuration(raw)
+				if err != nil {
+					return fmt.Errorf("invalid value for %s: %q is not a duration", key, raw)
+				}
+				field.SetInt(int64(d))
+				continue
+			}
+			n, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid value for %s: %q is not an integer", key, raw)
+			}
+			field.SetInt(n)
+		case reflect.Int:
+			n, err := strconv.Atoi(raw)
+			if err != nil {
+				return fmt.Errorf("invalid value for %s: %q is not an integer", key, raw)
+			}
+			field.SetInt(int64(n))
+		case reflect.Float64:
+			f, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				return fmt.Errorf("invalid value for %s: %q is not a number", key, raw)
+			}
+			field.SetFloat(f)
+		case reflect.Bool:
+			b, err := strconv.ParseBool(raw)
+			if err != nil {
+				return fmt.Errorf("invalid value for %s: %q is not a boolean", key, raw)
+			}
+			field.SetBool(b)
+		}
+	}
+
+	return nil
+}
+
+// Validate reporta de uma vez todos os valores obrigatórios ausentes e os
+// grupos opcionais configurados pela metade.
+func (c *Config) Validate() error {
+	var missing []string
+	collectMissing(reflect.ValueOf(c).Elem(), &missing)
+
+	var errs []error
+	if len(missing) > 0 {
+		errs = append(errs, fmt.Errorf("missing required configuration: %s", strings.Join(missing, ", ")))
+	}
+
+	if c.SMTP.Enabled() && (c.SMTP.Port == "" || c.SMTP.From == "") {
+		errs = append(errs, errors.New("SMTP_SERVER is set but SMTP_PORT or SMTP_FROM is missing"))
+	}
+
+	if c.Cloudinary.Enabled() && (c.Cloudinary.APIKey == "" || c.Cloudinary.APISecret == "") {
+		errs = append(errs, errors.New("CLOUDINARY_CLOUD_NAME is set but CLOUDINARY_API_KEY or CLOUDINARY_API_SECRET is missing"))
+	}
+
+	if c.Password.Algorithm != "argon2id" && c.Password.Algorithm != "bcrypt" {
+		errs = append(errs, fmt.Errorf("invalid PASSWORD_HASH_ALGORITHM: %q (expected argon2id or bcrypt)", c.Password.Algorithm))
+	}
+
+	// O hasher argon2id é montado mesmo com bcrypt como padrão (verifica hashes
+	// antigos), então os dois grupos de parâmetros são sempre validados.
+	if c.Password.Argon2Iterations < 1 || int64(c.Password.Argon2Iterations) > math.MaxUint32 {
+		errs = append(errs, fmt.Errorf("invalid ARGON2_ITERATIONS: %d (must be at least 1)", c.Password.Argon2Iterations))
+	}
+	if c.Password.Argon2Parallelism < 1 || c.Password.Argon2Parallelism > math.MaxUint8 {
+		errs = append(errs, fmt.Errorf("invalid ARGON2_PARALLELISM: %d (expected 1 to 255)", c.Password.Argon2Parallelism))
+	}
+	if c.Password.Argon2MemoryKiB < 8*c.Password.Argon2Parallelism || int64(c.Password.Argon2MemoryKiB) > math.MaxUint32 {
+		errs = append(errs, fmt.Errorf("invalid ARGON2_MEMORY_KIB: %d (must be at least 8 KiB per lane of ARGON2_PARALLELISM)", c.Password.Argon2MemoryKiB))
+	}
+	if c.Password.BcryptCost < bcrypt.MinCost || c.Password.BcryptCost > bcrypt.MaxCost {
+		errs = append(errs, fmt.Errorf("invalid BCRYPT_COST: %d (expected %d to %d)", c.Password.BcryptCost, bcrypt.MinCost, bcrypt.MaxCost))
+	}
+
+	if c.Tracing.Exporter == "otlp" && c.Tracing.Endpoint == "" {
+		errs = append(errs, errors.New("OTEL_TRACES_EXPORTER is otlp but OTEL_EXPORTER_OTLP_ENDPOINT is missing"))
+	}
+
+	if c.Account.DeletionGraceDays < 0 || c.Account.DeletionJobInterval <= 0 {
+		errs = append(errs, errors.New("ACCOUNT_DELETION_GRACE_DAYS must not be negative and ACCOUNT_DELETION_JOB_INTERVAL must be positive"))
+	}
+
+	if c.Export.LinkTTL <= 0 || c.Export.PollInterval <= 0 {
+		errs = append(errs, errors.New("DATA_EXPORT_LINK_TTL and DATA_EXPORT_POLL_INTERVAL must be positive"))
+	}
+
+	if c.Recurring.PollInterval <= 0 {
+		errs = append(errs, errors.New("RECURRING_POLL_INTERVAL must be positive"))
+	}
+	if _, err := c.Recurring.Location(); err != nil {
+		errs = append(errs, fmt.Errorf("invalid RECURRING_TIMEZONE: %q", c.Recurring.Timezone))
+	}
+
+	if c.Admin.Enabled() && len(c.Admin.APIKey) < minAdminKeyLength {
+		errs = append(errs, fmt.Errorf("ADMIN_API_KEY must have at least %d characters", minAdminKeyLength))
+	}
+
+	switch c.Storage.Driver {
+	case "", "local":
+	case "cloudinary":
+		if !c.Cloudinary.Enabled() {
+			errs = append(errs, errors.New("STORAGE_DRIVER is cloudinary but CLOUDINARY_CLOUD_NAME is missing"))
+		}
+	case "s3":
+		if c.Storage.S3.Endpoint == "" || c.Storage.S3.Bucket == "" || c.Storage.S3.AccessKeyID == "" || c.Storage.S3.SecretAccessKey == "" {
+			errs = append(errs, errors.New("STORAGE_DRIVER is s3 but S3_ENDPOINT, S3_BUCKET, S3_ACCESS_KEY_ID or S3_SECRET_ACCESS_KEY is missing"))
+		}
+		if c.Export.LinkTTL > maxS3LinkTTL {
+			errs = append(errs, fmt.Errorf("DATA_EXPORT_LINK_TTL must not exceed %s with STORAGE_DRIVER=s3 (presigned URL limit)", maxS3LinkTTL))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("invalid STORAGE_DRIVER: %q (expected cloudinary, local or s3)", c.Storage.Driver))
+	}
+
+	return errors.Join(errs...)
+}
+
+func collectMissing(v reflect.Value, missing *[]string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct {
+			collectMissing(field, missing)
+			continue
+		}
+
+		if t.Field(i).Tag.Get("required") == "true" && field.IsZero() {
+			*missing = append(*missing, t.Field(i).Tag.Get("env"))
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package currency
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ErrInvalidRatesFile indica um arquivo de cotações fora do formato.
+var ErrInvalidRatesFile = errors.New("invalid exchange rates file")
+
+// ParseRatesCSV lê um arquivo de cotações com as colunas date (AAAA-MM-DD),
+// base, quote e rate, separadas por "," ou ";". O cabeçalho é opcional. Com
+// ";", a cotação pode usar vírgula decimal.
+func ParseRatesCSV(content []byte) ([]ExchangeRate, error) {
+	content = bytes.TrimPrefix(content, []byte("\ufeff"))
+
+	firstLine, _, _ := bytes.Cut(content, []byte("\n"))
+	reader := csv.NewReader(bytes.NewReader(content))
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = 4
+	reader.TrimLeadingSpace = true
+
+	var rates []ExchangeRate
+	for line := 1; ; line++ {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRatesFile, err)
+		}
+
+		if line == 1 && strings.EqualFold(strings.TrimSpace(fields[0]), "date") {
+			continue
+		}
+
+		rate, err := parseRateRecord(fields)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidRatesFile, line, err)
+		}
+		rates = append(rates, rate)
+	}
+
+	if len(rates) == 0 {
+		return nil, fmt.Errorf("%w: no rates", ErrInvalidRatesFile)
+	}
+
+	return rates, nil
+}
+
+func parseRateRecord(fields []string) (ExchangeRate, error) {
+	date, err := time.Parse("2006-01-02", strings.TrimSpace(fields[0]))
+	if err != nil {
+		return ExchangeRate{}, fmt.Errorf("invalid date %q", fields[0])
+	}
+
+	base, quote := Normalize(fields[1]), Normalize(fields[2])
+	if base == "" || quote == "" || base == quote {
+		return ExchangeRate{}, fmt.Errorf("invalid currency pair %q/%q", fields[1], fields[2])
+	}
+
+	rate, err := ParseRate(fields[3])
+	if err != nil {
+		return ExchangeRate{}, fmt.Errorf("invalid rate %q", fields[3])
+	}
+
+	return ExchangeRate{Base: base, Quote: quote, Date: date, Rate: rate}, nil
+}
@@ -0,0 +1,71 @@
+package currency
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseRatesCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "comma with header",
+			content: "date,base,quote,rate\n2026-01-02,USD,BRL,5.4321\n2026-01-03, EUR, BRL, 6.1\n",
+			want:    []string{"2026-01-02 USD/BRL 5.4321", "2026-01-03 EUR/BRL 6.1"},
+		},
+		{
+			name:    "semicolon with decimal comma and BOM",
+			content: "\ufeff2026-01-02;usd;brl;5,4321\r\n2026-01-03;ARS;USD;0,00105\r\n",
+			want:    []string{"2026-01-02 USD/BRL 5.4321", "2026-01-03 ARS/USD 0.00105"},
+		},
+		{
+			name:    "header without trailing newline",
+			content: "Date;Base;Quote;Rate\n2026-01-02;USD;BRL;5",
+			want:    []string{"2026-01-02 USD/BRL 5"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rates, err := ParseRatesCSV([]byte(tt.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rates) != len(tt.want) {
+				t.Fatalf("got %d rates, want %d", len(rates), len(tt.want))
+			}
+			for i, rate := range rates {
+				got := rate.Date.Format(time.DateOnly) + " " + rate.Base + "/" + rate.Quote + " " + rate.Rate.String()
+				if got != tt.want[i] {
+					t.Errorf("rate %d = %q, want %q", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseRatesCSVInvalid(t *testing.T) {
+	tests := map[string]string{
+		"empty":                "",
+		"header only":          "date,base,quote,rate\n",
+		"invalid date":         "02/01/2026,USD,BRL,5\n",
+		"same currency":        "2026-01-02,USD,USD,1\n",
+		"unknown currency":     "2026-01-02,USD,XXX,1\n",
+		"no minor unit":        "2026-01-02,USD,JPY,150\n",
+		"three minor digits":   "2026-01-02,KWD,USD,3.25\n",
+		"negative rate":        "2026-01-02,USD,BRL,-5\n",
+		"rate rounds to zero":  "2026-01-02,USD,BRL,0.00000000001\n",
+		"missing column":       "2026-01-02,USD,BRL\n",
+		"header on later line": "2026-01-02,USD,BRL,5\ndate,base,quote,rate\n",
+	}
+
+	for name, content := range tests {
+		if _, err := ParseRatesCSV([]byte(content)); !errors.Is(err, ErrInvalidRatesFile) {
+			t.Errorf("%s: err = %v, want ErrInvalidRatesFile", name, err)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+// Package currency trata códigos ISO 4217 e cotações entre moedas.
+package currency
+
+import (
+	"strings"
+)
+
+// Default é a moeda de carteiras e usuários sem outra escolha.
+const Default = "BRL"
+
+// codes são os códigos ISO 4217 em circulação (sem metais e fundos) com duas
+// casas decimais. money.Amount e as colunas NUMERIC(14, 2) guardam centavos,
+// então moedas sem casas (JPY, CLP, KRW...) ou com três (KWD, BHD, OMR...)
+// ficam de fora: uma conversão para elas seria arredondada no lugar errado.
+var codes = map[string]bool{}
+
+func init() {
+	for _, code := range strings.Fields(`
+		AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BMD BND BOB BRL BSD BTN BWP BYN BZD CAD CDF
+		CHF CNY COP CRC CUP CVE CZK DKK DOP DZD EGP ERN ETB EUR FJD FKP GBP GEL GHS GIP GMD GTQ GYD HKD HNL
+		HTG HUF IDR ILS INR IRR JMD KES KGS KHR KPW KYD KZT LAK LBP LKR LRD LSL MAD MDL MGA MKD MMK MNT MOP
+		MRU MUR MVR MWK MXN MYR MZN NAD NGN NIO NOK NPR NZD PAB PEN PGK PHP PKR PLN QAR RON RSD RUB SAR SBD
+		SCR SDG SEK SGD SHP SLE SOS SRD SSP STN SVC SYP SZL THB TJS TMT TOP TRY TTD TWD TZS UAH USD UYU UZS
+		VES WST XCD XCG YER ZAR ZMW ZWG`) {
+		codes[code] = true
+	}
+}
+
+// Normalize devolve o código em maiúsculas, ou "" se não for ISO 4217.
+func Normalize(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if !codes[code] {
+		return ""
+	}
+	return code
+}
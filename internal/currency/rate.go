@@ -0,0 +1,214 @@
+package currency
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/big"
+	"nexa/internal/money"
+	"strings"
+	"time"
+)
+
+// rateScale é o número de casas decimais guardadas (NUMERIC(20, 10)).
+const rateScale = 10
+
+var ErrInvalidRate = errors.New("invalid exchange rate")
+
+// Rate é uma cotação exata, sem float: 1 unidade da moeda base vale Rate
+// unidades da moeda cotada.
+type Rate struct {
+	value *big.Rat
+}
+
+// ParseRate aceita "5.4321" ou "5,4321"; a cotação precisa ser positiva
+// depois de arredondada a rateScale casas ("0.00000000001" vira zero).
+func ParseRate(s string) (Rate, error) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", ".")
+	value, ok := new(big.Rat).SetString(s)
+	if !ok || strings.ContainsAny(s, "/eE") {
+		return Rate{}, ErrInvalidRate
+	}
+
+	value = roundRat(value, rateScale)
+	if value.Sign() <= 0 {
+		return Rate{}, ErrInvalidRate
+	}
+
+	return Rate{value: value}, nil
+}
+
+// One é a cotação de uma moeda para ela mesma.
+func One() Rate {
+	return Rate{value: big.NewRat(1, 1)}
+}
+
+// Implied é a cotação efetiva de uma conversão já feita (converted / amount),
+// como numa transferência em que o usuário informa os dois valores.
+func Implied(amount, converted money.Amount) Rate {
+	if amount <= 0 || converted <= 0 {
+		return Rate{}
+	}
+	return Rate{value: roundRat(big.NewRat(int64(converted), int64(amount)), rateScale)}
+}
+
+func (r Rate) IsZero() bool {
+	return r.value == nil || r.value.Sign() == 0
+}
+
+// Inverse é a cotação no sentido oposto (quote -> base).
+func (r Rate) Inverse() Rate {
+	if r.IsZero() {
+		return r
+	}
+	return Rate{value: roundRat(new(big.Rat).Inv(r.value), rateScale)}
+}
+
+// Mul encadeia cotações: (A -> B) * (B -> C) = (A -> C).
+func (r Rate) Mul(other Rate) Rate {
+	if r.IsZero() || other.IsZero() {
+		return Rate{}
+	}
+	return Rate{value: roundRat(new(big.Rat).Mul(r.value, other.value), rateScale)}
+}
+
+// Convert aplica a cotação ao valor, arredondando ao centavo (metade para
+// longe do zero).
+func (r Rate) Convert(amount money.Amount) money.Amount {
+	if r.IsZero() {
+		return 0
+	}
+
+	cents := new(big.Rat).Mul(new(big.Rat).SetInt64(int64(amount)), r.value)
+	return money.Amount(roundRat(cents, 0).Num().Int64())
+}
+
+func (r Rate) String() string {
+	if r.value == nil {
+		return "0"
+	}
+
+	s := r.value.FloatString(rateScale)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+func (r *Rate) UnmarshalJSON(data []byte) error {
+	parsed, err := ParseRate(strings.Trim(string(data), `"`))
+	if err != nil {
+		return err
+	}
+
+	*r = parsed
+	return nil
+}
+
+// Scan lê colunas NUMERIC, que o pgx entrega como texto.
+func (r *Rate) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*r = Rate{}
+		return nil
+	case string:
+		parsed, err := ParseRate(v)
+		if err != nil {
+			return fmt.Errorf("cannot scan %q into currency.Rate: %w", v, err)
+		}
+		*r = parsed
+		return nil
+	case []byte:
+		return r.Scan(string(v))
+	default:
+		return fmt.Errorf("cannot scan %T into currency.Rate", src)
+	}
+}
+
+func (r Rate) Value() (driver.Value, error) {
+	return r.String(), nil
+}
+
+// roundRat arredonda para scale casas decimais, metade para longe do zero.
+func roundRat(value *big.Rat, scale int) *big.Rat {
+	factor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)
+	scaled := new(big.Rat).Mul(value, new(big.Rat).SetInt(factor))
+
+	num, den := scaled.Num(), scaled.Denom()
+	quotient, remainder := new(big.Int).QuoRem(num, den, new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2)).Cmp(den) >= 0 {
+		if num.Sign() < 0 {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+
+	return new(big.Rat).SetFrac(quotient, factor)
+}
+
+// ExchangeRate é a cotação Base -> Quote válida a partir de Date.
+type ExchangeRate struct {
+	Base  string    `json:"base"`
+	Quote string    `json:"quote"`
+	Date  time.Time `json:"date"`
+	Rate  Rate      `json:"rate"`
+}
+
+// Resolve encontra a cotação from -> to entre as cotações dadas: direta,
+// inversa ou cruzada por uma moeda intermediária (ex.: ARS -> USD -> BRL).
+// Date é a da cotação mais antiga usada. rates deve ter no máximo uma cotação
+// por par, a mais recente que vale na data desejada.
+func Resolve(rates []ExchangeRate, from, to string) (ExchangeRate, bool) {
+	if from == to {
+		return ExchangeRate{Base: from, Quote: to, Rate: One()}, true
+	}
+
+	// edges[a][b] é a cotação a -> b, nos dois sentidos de cada par.
+	edges := map[string]map[string]ExchangeRate{}
+	add := func(base, quote string, rate ExchangeRate) {
+		if edges[base] == nil {
+			edges[base] = map[string]ExchangeRate{}
+		}
+		// Um par cadastrado nos dois sentidos: vale a cotação mais recente.
+		if existing, ok := edges[base][quote]; !ok || rate.Date.After(existing.Date) {
+			edges[base][quote] = rate
+		}
+	}
+	for _, rate := range rates {
+		if rate.Rate.IsZero() {
+			continue
+		}
+		add(rate.Base, rate.Quote, rate)
+		add(rate.Quote, rate.Base, ExchangeRate{Base: rate.Quote, Quote: rate.Base, Date: rate.Date, Rate: rate.Rate.Inverse()})
+	}
+
+	if direct, ok := edges[from][to]; ok {
+		return direct, true
+	}
+
+	var best ExchangeRate
+	bestPivot := ""
+	for pivot, first := range edges[from] {
+		second, ok := edges[pivot][to]
+		if !ok {
+			continue
+		}
+
+		date := first.Date
+		if second.Date.Before(date) {
+			date = second.Date
+		}
+		// Entre vários caminhos, o de cotações mais recentes; no empate, a
+		// moeda intermediária em ordem alfabética, para o resultado não
+		// depender da ordem do mapa.
+		if bestPivot == "" || date.After(best.Date) || (date.Equal(best.Date) && pivot < bestPivot) {
+			best = ExchangeRate{Base: from, Quote: to, Date: date, Rate: first.Rate.Mul(second.Rate)}
+			bestPivot = pivot
+		}
+	}
+
+	return best, bestPivot != ""
+}
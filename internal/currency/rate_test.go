@@ -0,0 +1,169 @@
+package currency
+
+import (
+	"math/big"
+	"nexa/internal/money"
+	"testing"
+	"time"
+)
+
+func mustRate(t *testing.T, s string) Rate {
+	t.Helper()
+
+	rate, err := ParseRate(s)
+	if err != nil {
+		t.Fatalf("ParseRate(%q): %v", s, err)
+	}
+	return rate
+}
+
+func day(d int) time.Time {
+	return time.Date(2026, 1, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestRoundRat(t *testing.T) {
+	tests := []struct {
+		num, den int64
+		scale    int
+		want     string
+	}{
+		{1, 3, 2, "0.33"},
+		{2, 3, 2, "0.67"},
+		{-1, 3, 2, "-0.33"},
+		{125, 1000, 2, "0.13"},
+		{-125, 1000, 2, "-0.13"},
+		{12345, 10, 0, "1235"},
+		{-12345, 10, 0, "-1235"},
+		{5, 100_000_000_000, 10, "0.0000000001"},
+		{4999, 100_000_000_000_000, 10, "0"},
+		{54321, 10000, 10, "5.4321"},
+	}
+
+	for _, tt := range tests {
+		got := roundRat(big.NewRat(tt.num, tt.den), tt.scale)
+		if want, _ := new(big.Rat).SetString(tt.want); got.Cmp(want) != 0 {
+			t.Errorf("roundRat(%d/%d, %d) = %s, want %s", tt.num, tt.den, tt.scale, got.FloatString(tt.scale), tt.want)
+		}
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"5.4321", "5.4321"},
+		{"5,4321", "5.4321"},
+		{" 1 ", "1"},
+		{"5.43210000001", "5.4321"},
+		{"0.00000000005", "0.0000000001"},
+	}
+	for _, tt := range tests {
+		if got := mustRate(t, tt.input).String(); got != tt.want {
+			t.Errorf("ParseRate(%q) = %s, want %s", tt.input, got, tt.want)
+		}
+	}
+
+	// Positiva antes de arredondar, zero depois.
+	for _, input := range []string{"", "abc", "0", "-1", "-0.00000000001", "0.00000000004", "1e3", "1/3"} {
+		if _, err := ParseRate(input); err != ErrInvalidRate {
+			t.Errorf("ParseRate(%q): err = %v, want ErrInvalidRate", input, err)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		rate   string
+		amount money.Amount
+		want   money.Amount
+	}{
+		{"5.4321", 1000, 5432},
+		{"0.5", 1, 1},
+		{"0.5", -1, -1},
+		{"0.2", 100000, 20000},
+	}
+
+	for _, tt := range tests {
+		if got := mustRate(t, tt.rate).Convert(tt.amount); got != tt.want {
+			t.Errorf("Rate(%s).Convert(%s) = %s, want %s", tt.rate, tt.amount, got, tt.want)
+		}
+	}
+
+	if got := (Rate{}).Convert(1000); got != 0 {
+		t.Errorf("zero rate converted to %s", got)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	rates := []ExchangeRate{
+		{Base: "USD", Quote: "BRL", Date: day(2), Rate: mustRate(t, "5")},
+		{Base: "EUR", Quote: "USD", Date: day(1), Rate: mustRate(t, "1.1")},
+		{Base: "ARS", Quote: "USD", Date: day(3), Rate: mustRate(t, "0.001")},
+		{Base: "GBP", Quote: "MXN", Date: day(3), Rate: Rate{}},
+	}
+
+	tests := []struct {
+		name     string
+		rates    []ExchangeRate
+		from, to string
+		want     string
+		date     time.Time
+		found    bool
+	}{
+		{name: "same currency", rates: rates, from: "BRL", to: "BRL", want: "1", found: true},
+		{name: "direct", rates: rates, from: "USD", to: "BRL", want: "5", date: day(2), found: true},
+		{name: "inverse", rates: rates, from: "BRL", to: "USD", want: "0.2", date: day(2), found: true},
+		{name: "cross", rates: rates, from: "EUR", to: "BRL", want: "5.5", date: day(1), found: true},
+		{name: "cross through inverses", rates: rates, from: "BRL", to: "ARS", want: "200", date: day(2), found: true},
+		{name: "missing pair", rates: rates, from: "BRL", to: "CHF", found: false},
+		{name: "zero rate is ignored", rates: rates, from: "GBP", to: "MXN", found: false},
+		{
+			name: "pair in both directions uses the latest",
+			rates: []ExchangeRate{
+				{Base: "USD", Quote: "BRL", Date: day(2), Rate: mustRate(t, "5")},
+				{Base: "BRL", Quote: "USD", Date: day(5), Rate: mustRate(t, "0.25")},
+			},
+			from: "USD", to: "BRL", want: "4", date: day(5), found: true,
+		},
+		{
+			name: "most recent path",
+			rates: append([]ExchangeRate{
+				{Base: "EUR", Quote: "GBP", Date: day(4), Rate: mustRate(t, "0.85")},
+				{Base: "GBP", Quote: "BRL", Date: day(4), Rate: mustRate(t, "6.5")},
+			}, rates...),
+			from: "EUR", to: "BRL", want: "5.525", date: day(4), found: true,
+		},
+		{
+			name: "tie broken by pivot code",
+			rates: []ExchangeRate{
+				{Base: "EUR", Quote: "USD", Date: day(1), Rate: mustRate(t, "1.1")},
+				{Base: "USD", Quote: "BRL", Date: day(1), Rate: mustRate(t, "5")},
+				{Base: "EUR", Quote: "GBP", Date: day(1), Rate: mustRate(t, "0.8")},
+				{Base: "GBP", Quote: "BRL", Date: day(1), Rate: mustRate(t, "7")},
+			},
+			from: "EUR", to: "BRL", want: "5.6", date: day(1), found: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := Resolve(tt.rates, tt.from, tt.to)
+			if found != tt.found {
+				t.Fatalf("found = %v, want %v", found, tt.found)
+			}
+			if !found {
+				return
+			}
+			if got.Base != tt.from || got.Quote != tt.to {
+				t.Errorf("pair = %s/%s, want %s/%s", got.Base, got.Quote, tt.from, tt.to)
+			}
+			if got.Rate.String() != tt.want {
+				t.Errorf("rate = %s, want %s", got.Rate, tt.want)
+			}
+			if !got.Date.Equal(tt.date) {
+				t.Errorf("date = %s, want %s", got.Date.Format(time.DateOnly), tt.date.Format(time.DateOnly))
+			}
+		})
+	}
+}
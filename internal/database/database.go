@@ -3,31 +3,32 @@ package database
 import (
 	"context"
 	"fmt"
-	"os"
+	"nexa/internal/config"
+	"nexa/internal/tracing"
+	"time"
 
-	"github.com/jackc/pgx/v5"
-	"github.com/joho/godotenv"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-func ConnectDB() *pgx.Conn {
-	if os.Getenv("RENDER") == "" {
-		_ = godotenv.Load()
+func ConnectDB(ctx context.Context, cfg config.DatabaseConfig) (*pgxpool.Pool, error) {
+	poolConfig, err := pgxpool.ParseConfig(cfg.ConnString())
+	if err != nil {
+		return nil, fmt.Errorf("invalid database configuration: %w", err)
 	}
+	poolConfig.ConnConfig.Tracer = tracing.QueryTracer{}
 
-	user := os.Getenv("DB_USER")
-	password := os.Getenv("DB_PASSWORD")
-	url := os.Getenv("DB_URL")
-	port := os.Getenv("DB_PORT")
-	name := os.Getenv("DB_NAME")
-
-	connString := fmt.Sprintf("postgres://%s:%s@%s:%s/%s", user, password, url, port, name)
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, fmt.Errorf("connection failed: %w", err)
+	}
 
-	conn, err := pgx.Connect(context.Background(), connString)
+	pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
 
-	if err != nil {
-		fmt.Printf("Connection failed: %v", err)
-		return nil
+	if err := pool.Ping(pingCtx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("connection failed: %w", err)
 	}
 
-	return conn
+	return pool, nil
 }
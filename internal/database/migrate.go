@@ -0,0 +1,187 @@
+package database
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+type Migration struct {
+	Version string
+	SQL     string
+}
+
+// Migrations lista os arquivos embarcados em ordem. A versão é o nome do
+// arquivo sem extensão (ex.: 0001_baseline).
+func Migrations() ([]Migration, error) {
+	entries, err := migrationsFS.ReadDir("migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	migrations := make([]Migration, 0, len(entries))
+	for _, entry := range entries {
+		content, err := migrationsFS.ReadFile(path.Join("migrations", entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		migrations = append(migrations, Migration{
+			Version: strings.TrimSuffix(entry.Name(), path.Ext(entry.Name())),
+			SQL:     string(content),
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// migrationLockKey identifica o advisory lock das migrações no Postgres.
+const migrationLockKey int64 = 0x6e657861 // "nexa"
+
+// Migrate aplica as migrações pendentes. Réplicas que sobem juntas disputam
+// um advisory lock de sessão numa conexão dedicada, então só uma aplica os
+// arquivos e as demais, ao obter o lock, já não encontram pendências.
+func Migrate(ctx context.Context, db *pgxpool.Pool) error {
+	conn, err := db.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire migration connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", migrationLockKey); err != nil {
+		return fmt.Errorf("failed to lock migrations: %w", err)
+	}
+	defer func() {
+		// Sem o unlock, a conexão voltaria ao pool ainda com o lock; fechá-la
+		// encerra a sessão e libera o lock.
+		if _, err := conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockKey); err != nil {
+			_ = conn.Conn().Close(context.Background())
+		}
+	}()
+
+	if err := ensureMigrationsTable(ctx, conn); err != nil {
+		return err
+	}
+
+	pending, err := pendingMigrations(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range pending {
+		tx, err := conn.Begin(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to begin migration %s: %w", migration.Version, err)
+		}
+
+		if _, err := tx.Exec(ctx, migration.SQL); err != nil {
+			_ = tx.Rollback(ctx)
+			return fmt.Errorf("failed to apply migration %s: %w", migration.Version, err)
+		}
+
+		if _, err := tx.Exec(ctx, "INSERT INTO db_nexa.schema_migrations (version) VALUES ($1)", migration.Version); err != nil {
+			_ = tx.Rollback(ctx)
+			return fmt.Errorf("failed to record migration %s: %w", migration.Version, err)
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return fmt.Errorf("failed to commit migration %s: %w", migration.Version, err)
+		}
+	}
+
+	return nil
+}
+
+// querier é o que as migrações usam do banco; atendido pelo pool e por uma
+// conexão dedicada.
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// PendingMigrations retorna as versões embarcadas que ainda não foram aplicadas.
+func PendingMigrations(ctx context.Context, db *pgxpool.Pool) ([]string, error) {
+	pending, err := pendingMigrations(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]string, 0, len(pending))
+	for _, migration := range pending {
+		versions = append(versions, migration.Version)
+	}
+
+	return versions, nil
+}
+
+func pendingMigrations(ctx context.Context, db querier) ([]Migration, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var exists bool
+	if err := db.QueryRow(ctx, "SELECT to_regclass('db_nexa.schema_migrations') IS NOT NULL").Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to check migrations table: %w", err)
+	}
+	if !exists {
+		return migrations, nil
+	}
+
+	rows, err := db.Query(ctx, "SELECT version FROM db_nexa.schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("failed to scan applied migration: %w", err)
+		}
+		applied[version] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list applied migrations: %w", err)
+	}
+
+	var pending []Migration
+	for _, migration := range migrations {
+		if !applied[migration.Version] {
+			pending = append(pending, migration)
+		}
+	}
+
+	return pending, nil
+}
+
+func ensureMigrationsTable(ctx context.Context, db querier) error {
+	_, err := db.Exec(ctx, `
+		CREATE SCHEMA IF NOT EXISTS db_nexa;
+		CREATE TABLE IF NOT EXISTS db_nexa.schema_migrations (
+			version    TEXT PRIMARY KEY,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create migrations table: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,24 @@
+-- Estrutura existente antes do controle de migrações. Idempotente para bancos
+-- que já foram criados manualmente.
+CREATE SCHEMA IF NOT EXISTS db_nexa;
+
+CREATE TABLE IF NOT EXISTS db_nexa.tb_user (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name        VARCHAR(200) NOT NULL,
+    username    VARCHAR(50),
+    email       VARCHAR(255) NOT NULL,
+    password    TEXT NOT NULL,
+    photo_url   TEXT NOT NULL DEFAULT '',
+    score       INTEGER NOT NULL DEFAULT 0,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_login  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    is_active   BOOLEAN NOT NULL DEFAULT false
+);
+
+CREATE TABLE IF NOT EXISTS db_nexa.tb_user_authentication_token (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id     UUID NOT NULL REFERENCES db_nexa.tb_user (id) ON DELETE CASCADE,
+    code        VARCHAR(16) NOT NULL,
+    expires_at  TIMESTAMPTZ NOT NULL,
+    fails       INTEGER NOT NULL DEFAULT 0
+);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS db_nexa.tb_settings (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id     UUID NOT NULL UNIQUE REFERENCES db_nexa.tb_user (id) ON DELETE CASCADE,
+    theme       VARCHAR(20) NOT NULL DEFAULT 'light'
+);
+
+ALTER TABLE db_nexa.tb_settings ADD COLUMN IF NOT EXISTS language VARCHAR(10) NOT NULL DEFAULT 'pt-BR';
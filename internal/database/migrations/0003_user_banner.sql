@@ -0,0 +1 @@
+ALTER TABLE db_nexa.tb_user ADD COLUMN IF NOT EXISTS banner TEXT NOT NULL DEFAULT '';
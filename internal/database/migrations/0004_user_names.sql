@@ -0,0 +1,51 @@
+-- Alinha tb_user com o modelo: nome separado em first_name/last_name (name
+-- continua como nome de exibição), username obrigatório e unicidade de e-mail
+-- e username sem diferenciar maiúsculas de minúsculas.
+ALTER TABLE db_nexa.tb_user ADD COLUMN IF NOT EXISTS first_name VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE db_nexa.tb_user ADD COLUMN IF NOT EXISTS last_name VARCHAR(100) NOT NULL DEFAULT '';
+
+UPDATE db_nexa.tb_user
+SET first_name = split_part(btrim(name), ' ', 1),
+    last_name = btrim(substr(btrim(name), length(split_part(btrim(name), ' ', 1)) + 1))
+WHERE first_name = '';
+
+UPDATE db_nexa.tb_user
+SET username = 'user_' || substr(replace(id::text, '-', ''), 1, 12)
+WHERE username IS NULL OR btrim(username) = '';
+
+-- E-mails que só diferem por maiúsculas ou espaços seriam contas duplicadas
+-- para o índice único abaixo. Qual conta fica é decisão de quem opera a base,
+-- então a migração para e lista os e-mails em conflito.
+DO $$
+DECLARE
+    colliding TEXT;
+BEGIN
+    SELECT string_agg(email, ', ' ORDER BY email) INTO colliding
+    FROM (
+        SELECT lower(btrim(email)) AS email
+        FROM db_nexa.tb_user
+        GROUP BY lower(btrim(email))
+        HAVING count(*) > 1
+    ) duplicated;
+
+    IF colliding IS NOT NULL THEN
+        RAISE EXCEPTION 'tb_user has emails that differ only by case or spaces; merge or rename these accounts before migrating: %', colliding;
+    END IF;
+END $$;
+
+UPDATE db_nexa.tb_user SET email = lower(btrim(email)), username = lower(btrim(username));
+
+-- Usernames repetidos (sem diferenciar maiúsculas): o cadastro mais antigo
+-- mantém o nome e os demais ganham um sufixo derivado do id.
+UPDATE db_nexa.tb_user u
+SET username = left(u.username, 23) || '_' || substr(replace(u.id::text, '-', ''), 1, 6)
+FROM (
+    SELECT id, row_number() OVER (PARTITION BY username ORDER BY created_at, id) AS position
+    FROM db_nexa.tb_user
+) ranked
+WHERE ranked.id = u.id AND ranked.position > 1;
+
+ALTER TABLE db_nexa.tb_user ALTER COLUMN username SET NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_tb_user_email ON db_nexa.tb_user (lower(email));
+CREATE UNIQUE INDEX IF NOT EXISTS ux_tb_user_username ON db_nexa.tb_user (lower(username));
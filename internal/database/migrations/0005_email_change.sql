@@ -0,0 +1,12 @@
+-- Troca de e-mail: o novo endereço fica em pending_email até ser confirmado
+-- com o código enviado a ele. sessions_revoked_at invalida JWTs emitidos antes.
+ALTER TABLE db_nexa.tb_user ADD COLUMN IF NOT EXISTS pending_email VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE db_nexa.tb_user ADD COLUMN IF NOT EXISTS sessions_revoked_at TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS db_nexa.tb_email_change_token (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id     UUID NOT NULL REFERENCES db_nexa.tb_user (id) ON DELETE CASCADE,
+    code        VARCHAR(16) NOT NULL,
+    expires_at  TIMESTAMPTZ NOT NULL,
+    fails       INTEGER NOT NULL DEFAULT 0
+);
@@ -0,0 +1,18 @@
+-- Desativação de conta e exclusão definitiva (LGPD). O job apaga a linha de
+-- tb_user; tokens, configurações e dados financeiros saem em cascata, por
+-- isso toda tabela com user_id deve usar ON DELETE CASCADE.
+ALTER TABLE db_nexa.tb_user ADD COLUMN IF NOT EXISTS deactivated_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS ix_tb_user_deactivated_at
+    ON db_nexa.tb_user (deactivated_at)
+    WHERE deactivated_at IS NOT NULL;
+
+-- Registro anônimo de auditoria: prova que a exclusão aconteceu sem guardar
+-- nenhum dado pessoal. subject_hash é o SHA-256 do id do usuário.
+CREATE TABLE IF NOT EXISTS db_nexa.tb_deleted_user (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    subject_hash    CHAR(64) NOT NULL UNIQUE,
+    created_month   DATE NOT NULL,
+    deactivated_at  TIMESTAMPTZ NOT NULL,
+    deleted_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,79 @@
+-- Dados financeiros do modelo (assets/Modelo_MER_Nexa_v3.svg). Tudo pende
+-- de tb_wallet, que pende de tb_user: a exclusão de um usuário (LGPD) remove
+-- tudo em cascata.
+CREATE TABLE IF NOT EXISTS db_nexa.tb_wallet (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id     UUID NOT NULL REFERENCES db_nexa.tb_user (id) ON DELETE CASCADE,
+    name        VARCHAR(100) NOT NULL,
+    total       NUMERIC(14, 2) NOT NULL DEFAULT 0,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS ix_tb_wallet_user_id ON db_nexa.tb_wallet (user_id);
+
+CREATE TABLE IF NOT EXISTS db_nexa.tb_category (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    wallet_id   UUID NOT NULL REFERENCES db_nexa.tb_wallet (id) ON DELETE CASCADE,
+    name        VARCHAR(100) NOT NULL,
+    icon        VARCHAR(50) NOT NULL DEFAULT '',
+    color       VARCHAR(20) NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS ix_tb_category_wallet_id ON db_nexa.tb_category (wallet_id);
+
+CREATE TABLE IF NOT EXISTS db_nexa.tb_transaction (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    wallet_id       UUID NOT NULL REFERENCES db_nexa.tb_wallet (id) ON DELETE CASCADE,
+    category_id     UUID REFERENCES db_nexa.tb_category (id) ON DELETE SET NULL,
+    amount          NUMERIC(14, 2) NOT NULL CHECK (amount >= 0),
+    type            VARCHAR(10) NOT NULL CHECK (type IN ('income', 'expense')),
+    payment_method  VARCHAR(30) NOT NULL DEFAULT '',
+    date            DATE NOT NULL,
+    description     TEXT NOT NULL DEFAULT '',
+    photo_url       TEXT NOT NULL DEFAULT '',
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS ix_tb_transaction_wallet_date ON db_nexa.tb_transaction (wallet_id, date);
+
+CREATE TABLE IF NOT EXISTS db_nexa.tb_budget (
+    id               UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    wallet_id        UUID NOT NULL REFERENCES db_nexa.tb_wallet (id) ON DELETE CASCADE,
+    category_id      UUID REFERENCES db_nexa.tb_category (id) ON DELETE CASCADE,
+    reference_month  DATE NOT NULL,
+    total_limit      NUMERIC(14, 2) NOT NULL DEFAULT 0,
+    current_spent    NUMERIC(14, 2) NOT NULL DEFAULT 0,
+    saving_goal      NUMERIC(14, 2) NOT NULL DEFAULT 0,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS ix_tb_budget_wallet_id ON db_nexa.tb_budget (wallet_id);
+
+CREATE TABLE IF NOT EXISTS db_nexa.tb_credit_card (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    wallet_id     UUID NOT NULL REFERENCES db_nexa.tb_wallet (id) ON DELETE CASCADE,
+    name          VARCHAR(100) NOT NULL,
+    credit_limit  NUMERIC(14, 2) NOT NULL DEFAULT 0,
+    closing_day   SMALLINT NOT NULL CHECK (closing_day BETWEEN 1 AND 31),
+    due_day       SMALLINT NOT NULL CHECK (due_day BETWEEN 1 AND 31)
+);
+CREATE INDEX IF NOT EXISTS ix_tb_credit_card_wallet_id ON db_nexa.tb_credit_card (wallet_id);
+
+CREATE TABLE IF NOT EXISTS db_nexa.tb_purchase (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    card_id       UUID NOT NULL REFERENCES db_nexa.tb_credit_card (id) ON DELETE CASCADE,
+    category_id   UUID REFERENCES db_nexa.tb_category (id) ON DELETE SET NULL,
+    description   TEXT NOT NULL DEFAULT '',
+    total         NUMERIC(14, 2) NOT NULL,
+    date          DATE NOT NULL,
+    installments  SMALLINT NOT NULL DEFAULT 1 CHECK (installments >= 1),
+    interest      NUMERIC(6, 4) NOT NULL DEFAULT 0,
+    status        VARCHAR(20) NOT NULL DEFAULT 'open'
+);
+CREATE INDEX IF NOT EXISTS ix_tb_purchase_card_id ON db_nexa.tb_purchase (card_id);
+
+CREATE TABLE IF NOT EXISTS db_nexa.tb_installment (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    purchase_id  UUID NOT NULL REFERENCES db_nexa.tb_purchase (id) ON DELETE CASCADE,
+    number       SMALLINT NOT NULL,
+    value        NUMERIC(14, 2) NOT NULL,
+    date         DATE NOT NULL,
+    status       VARCHAR(20) NOT NULL DEFAULT 'pending'
+);
+CREATE INDEX IF NOT EXISTS ix_tb_installment_purchase_id ON db_nexa.tb_installment (purchase_id);
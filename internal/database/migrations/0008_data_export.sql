@@ -0,0 +1,20 @@
+-- Pedidos de exportação de dados (LGPD, portabilidade). A fila fica no banco
+-- para sobreviver a reinícios; o arquivo vive no storage até expires_at.
+CREATE TABLE IF NOT EXISTS db_nexa.tb_data_export (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id       UUID NOT NULL REFERENCES db_nexa.tb_user (id) ON DELETE CASCADE,
+    status        VARCHAR(20) NOT NULL DEFAULT 'pending'
+                  CHECK (status IN ('pending', 'processing', 'ready', 'failed', 'expired')),
+    storage_key   TEXT NOT NULL DEFAULT '',
+    download_url  TEXT NOT NULL DEFAULT '',
+    error         TEXT NOT NULL DEFAULT '',
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    started_at    TIMESTAMPTZ,
+    completed_at  TIMESTAMPTZ,
+    expires_at    TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS ix_tb_data_export_user_id ON db_nexa.tb_data_export (user_id);
+CREATE INDEX IF NOT EXISTS ix_tb_data_export_status ON db_nexa.tb_data_export (status);
+-- No máximo um pedido na fila por usuário; pedidos repetidos reaproveitam o atual.
+CREATE UNIQUE INDEX IF NOT EXISTS ux_tb_data_export_user_pending
+    ON db_nexa.tb_data_export (user_id) WHERE status IN ('pending', 'processing');
@@ -0,0 +1,33 @@
+-- Importação de extratos: o arquivo fica guardado entre o upload, o preview e
+-- a confirmação; o mapeamento de colunas é salvo por usuário e banco.
+CREATE TABLE IF NOT EXISTS db_nexa.tb_statement_import (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id         UUID NOT NULL REFERENCES db_nexa.tb_user (id) ON DELETE CASCADE,
+    wallet_id       UUID NOT NULL REFERENCES db_nexa.tb_wallet (id) ON DELETE CASCADE,
+    bank            VARCHAR(50) NOT NULL DEFAULT '',
+    format          VARCHAR(10) NOT NULL,
+    filename        VARCHAR(255) NOT NULL DEFAULT '',
+    content         BYTEA NOT NULL,
+    mapping         JSONB,
+    status          VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'imported')),
+    imported_count  INTEGER NOT NULL DEFAULT 0,
+    duplicate_count INTEGER NOT NULL DEFAULT 0,
+    invalid_count   INTEGER NOT NULL DEFAULT 0,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    imported_at     TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS ix_tb_statement_import_user_id ON db_nexa.tb_statement_import (user_id);
+
+CREATE TABLE IF NOT EXISTS db_nexa.tb_import_mapping (
+    user_id     UUID NOT NULL REFERENCES db_nexa.tb_user (id) ON DELETE CASCADE,
+    bank        VARCHAR(50) NOT NULL,
+    mapping     JSONB NOT NULL,
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, bank)
+);
+
+ALTER TABLE db_nexa.tb_transaction
+    ADD COLUMN IF NOT EXISTS import_id UUID REFERENCES db_nexa.tb_statement_import (id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS external_id VARCHAR(255) NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS ix_tb_transaction_wallet_external_id
+    ON db_nexa.tb_transaction (wallet_id, external_id) WHERE external_id <> '';
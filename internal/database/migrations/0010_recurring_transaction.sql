@@ -0,0 +1,45 @@
+-- Lançamentos recorrentes: o modelo guarda a regra e o próximo índice a
+-- lançar; exceções pulam ou alteram uma ocorrência (pela data nominal). O
+-- índice único em tb_transaction garante no máximo um lançamento por
+-- ocorrência, mesmo que o agendador rode duas vezes.
+CREATE TABLE IF NOT EXISTS db_nexa.tb_recurring_transaction (
+    id               UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    wallet_id        UUID NOT NULL REFERENCES db_nexa.tb_wallet (id) ON DELETE CASCADE,
+    category_id      UUID REFERENCES db_nexa.tb_category (id) ON DELETE SET NULL,
+    amount           NUMERIC(14, 2) NOT NULL CHECK (amount > 0),
+    type             VARCHAR(10) NOT NULL CHECK (type IN ('income', 'expense')),
+    payment_method   VARCHAR(30) NOT NULL DEFAULT '',
+    description      TEXT NOT NULL DEFAULT '',
+    frequency        VARCHAR(10) NOT NULL CHECK (frequency IN ('daily', 'weekly', 'monthly', 'yearly')),
+    interval_count   SMALLINT NOT NULL DEFAULT 1 CHECK (interval_count >= 1),
+    day_of_month     SMALLINT CHECK (day_of_month BETWEEN 1 AND 31),
+    business_day     VARCHAR(20) NOT NULL DEFAULT 'none'
+                     CHECK (business_day IN ('none', 'following', 'preceding', 'modified_following')),
+    start_date       DATE NOT NULL,
+    end_date         DATE,
+    max_occurrences  INTEGER CHECK (max_occurrences >= 1),
+    next_index       INTEGER NOT NULL DEFAULT 0,
+    next_date        DATE,
+    active           BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS ix_tb_recurring_transaction_wallet_id ON db_nexa.tb_recurring_transaction (wallet_id);
+CREATE INDEX IF NOT EXISTS ix_tb_recurring_transaction_due
+    ON db_nexa.tb_recurring_transaction (next_date) WHERE active AND next_date IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS db_nexa.tb_recurring_exception (
+    recurring_id     UUID NOT NULL REFERENCES db_nexa.tb_recurring_transaction (id) ON DELETE CASCADE,
+    occurrence_date  DATE NOT NULL,
+    skip             BOOLEAN NOT NULL DEFAULT FALSE,
+    amount           NUMERIC(14, 2) CHECK (amount > 0),
+    description      TEXT,
+    category_id      UUID REFERENCES db_nexa.tb_category (id) ON DELETE SET NULL,
+    payment_method   VARCHAR(30),
+    PRIMARY KEY (recurring_id, occurrence_date)
+);
+
+ALTER TABLE db_nexa.tb_transaction
+    ADD COLUMN IF NOT EXISTS recurring_id UUID REFERENCES db_nexa.tb_recurring_transaction (id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS occurrence_date DATE;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_tb_transaction_recurrence
+    ON db_nexa.tb_transaction (recurring_id, occurrence_date) WHERE recurring_id IS NOT NULL;
@@ -0,0 +1,17 @@
+-- Transferências entre carteiras do mesmo usuário: cada uma tem dois
+-- lançamentos ligados por transfer_id (saída como expense na origem, entrada
+-- como income no destino), que não contam como receita nem despesa.
+CREATE TABLE IF NOT EXISTS db_nexa.tb_transfer (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id      UUID NOT NULL REFERENCES db_nexa.tb_user (id) ON DELETE CASCADE,
+    amount       NUMERIC(14, 2) NOT NULL CHECK (amount > 0),
+    date         DATE NOT NULL,
+    description  TEXT NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS ix_tb_transfer_user_id ON db_nexa.tb_transfer (user_id, date);
+
+ALTER TABLE db_nexa.tb_transaction
+    ADD COLUMN IF NOT EXISTS transfer_id UUID REFERENCES db_nexa.tb_transfer (id) ON DELETE CASCADE;
+CREATE INDEX IF NOT EXISTS ix_tb_transaction_transfer_id
+    ON db_nexa.tb_transaction (transfer_id) WHERE transfer_id IS NOT NULL;
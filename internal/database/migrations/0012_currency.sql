@@ -0,0 +1,29 @@
+-- Multimoeda: cada carteira tem uma moeda ISO 4217 e o usuário escolhe a
+-- moeda dos relatórios consolidados. Lançamentos em moeda estrangeira guardam
+-- o valor original e a cotação usada; amount continua na moeda da carteira.
+ALTER TABLE db_nexa.tb_wallet ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'BRL';
+ALTER TABLE db_nexa.tb_settings ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'BRL';
+
+ALTER TABLE db_nexa.tb_transaction
+    ADD COLUMN IF NOT EXISTS original_amount NUMERIC(14, 2),
+    ADD COLUMN IF NOT EXISTS original_currency CHAR(3),
+    ADD COLUMN IF NOT EXISTS exchange_rate NUMERIC(20, 10);
+
+-- Transferência entre carteiras de moedas diferentes: amount sai da origem e
+-- to_amount entra no destino.
+ALTER TABLE db_nexa.tb_transfer ADD COLUMN IF NOT EXISTS to_amount NUMERIC(14, 2);
+UPDATE db_nexa.tb_transfer SET to_amount = amount WHERE to_amount IS NULL;
+ALTER TABLE db_nexa.tb_transfer ALTER COLUMN to_amount SET NOT NULL;
+
+-- Cotações: 1 base = rate quote, válida de date até a próxima cotação do
+-- par. Alimentada pelo endpoint administrativo ou por importação de arquivo.
+CREATE TABLE IF NOT EXISTS db_nexa.tb_exchange_rate (
+    base        CHAR(3) NOT NULL,
+    quote       CHAR(3) NOT NULL,
+    date        DATE NOT NULL,
+    rate        NUMERIC(20, 10) NOT NULL CHECK (rate > 0),
+    source      VARCHAR(20) NOT NULL DEFAULT 'manual',
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (base, quote, date),
+    CHECK (base <> quote)
+);
@@ -0,0 +1,3 @@
+-- O worker renova heartbeat_at enquanto monta o arquivo; só um pedido sem
+-- heartbeat recente é considerado abandonado e volta para a fila.
+ALTER TABLE db_nexa.tb_data_export ADD COLUMN IF NOT EXISTS heartbeat_at TIMESTAMPTZ;
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"nexa/internal/currency"
+	"nexa/internal/repository"
+	"nexa/internal/utils"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	maxRatesFileBytes = 2 << 20
+	maxExchangeRates  = 10000
+)
+
+type ExchangeRateHandler struct {
+	Rates *repository.ExchangeRateRepository
+}
+
+func NewExchangeRateHandler(db *pgxpool.Pool) *ExchangeRateHandler {
+	return &ExchangeRateHandler{
+		Rates: repository.NewExchangeRateRepository(db),
+	}
+}
+
+type exchangeRateRequest struct {
+	Base  string        `json:"base"`
+	Quote string        `json:"quote"`
+	Date  string        `json:"date"`
+	Rate  currency.Rate `json:"rate"`
+}
+
+// UpsertRates cadastra cotações (rota administrativa). O corpo é uma lista de
+// {base, quote, date, rate}: 1 base vale rate quote a partir de date.
+func (h *ExchangeRateHandler) UpsertRates(c *fiber.Ctx) error {
+	var request []exchangeRateRequest
+	if err := c.BodyParser(&request); err != nil {
+		return utils.NewRequestError("INVALID_BODY_FORMAT", err)
+	}
+	if len(request) == 0 {
+		return utils.NewRequestError("INVALID_EXCHANGE_RATE")
+	}
+	if len(request) > maxExchangeRates {
+		return utils.NewRequestError("TOO_MANY_EXCHANGE_RATES")
+	}
+
+	rates := make([]currency.ExchangeRate, 0, len(request))
+	for _, item := range request {
+		base, quote := currency.Normalize(item.Base), currency.Normalize(item.Quote)
+		if base == "" || quote == "" || base == quote {
+			return utils.NewRequestError("INVALID_CURRENCY")
+		}
+		date, err := time.Parse(dateLayout, item.Date)
+		if err != nil {
+			return utils.NewRequestError("INVALID_DATE_FORMAT", err)
+		}
+		if item.Rate.IsZero() {
+			return utils.NewRequestError("INVALID_EXCHANGE_RATE")
+		}
+		rates = append(rates, currency.ExchangeRate{Base: base, Quote: quote, Date: date, Rate: item.Rate})
+	}
+
+	if err := h.Rates.Upsert(c.UserContext(), rates, repository.ExchangeRateSourceManual); err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+
+	return c.JSON(fiber.Map{"saved": len(rates)})
+}
+
+// ImportRates cadastra cotações a partir de um CSV (multipart: file) com as
+// colunas date, base, quote e rate (rota administrativa). O arquivo é gravado
+// inteiro ou não é gravado.
+func (h *ExchangeRateHandler) ImportRates(c *fiber.Ctx) error {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return utils.NewRequestError("REQUIRED_RATES_FILE", err)
+	}
+	if fileHeader.Size > maxRatesFileBytes {
+		return utils.NewRequestError("RATES_FILE_TOO_LARGE")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return utils.NewRequestError("INVALID_RATES_FILE", err)
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(io.LimitReader(file, maxRatesFileBytes+1))
+	if err != nil {
+		return utils.NewRequestError("INVALID_RATES_FILE", err)
+	}
+	if len(content) > maxRatesFileBytes {
+		return utils.NewRequestError("RATES_FILE_TOO_LARGE")
+	}
+
+	rates, err := currency.ParseRatesCSV(content)
+	if err != nil {
+		return utils.NewRequestError("INVALID_RATES_FILE", err)
+	}
+	if len(rates) > maxExchangeRates {
+		return utils.NewRequestError("TOO_MANY_EXCHANGE_RATES")
+	}
+
+	if err := h.Rates.Upsert(c.UserContext(), rates, repository.ExchangeRateSourceFile); err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+
+	return c.JSON(fiber.Map{"saved": len(rates)})
+}
+
+// GetRate devolve a cotação from -> to válida em date (AAAA-MM-DD; padrão:
+// hoje), direta, inversa ou cruzada.
+func (h *ExchangeRateHandler) GetRate(c *fiber.Ctx) error {
+	from, to := currency.Normalize(c.Query("from")), currency.Normalize(c.Query("to"))
+	if from == "" || to == "" {
+		return utils.NewRequestError("INVALID_CURRENCY")
+	}
+
+	date, err := dateOrToday(c.Query("date"))
+	if err != nil {
+		return err
+	}
+
+	rate, err := h.Rates.Find(c.UserContext(), from, to, date)
+	if err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+	if rate == nil {
+		return utils.NewRequestError("EXCHANGE_RATE_NOT_FOUND")
+	}
+
+	return c.JSON(rate)
+}
+
+// dateOrToday lê uma data AAAA-MM-DD; vazia, vale hoje.
+func dateOrToday(value string) (time.Time, error) {
+	if value == "" {
+		now := time.Now()
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC), nil
+	}
+
+	date, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return date, utils.NewRequestError("INVALID_DATE_FORMAT", err)
+	}
+
+	return date, nil
+}
+
+// resolveRate busca a cotação from -> to na data, com o código de erro
+// quando não há cotação cadastrada.
+func resolveRate(c *fiber.Ctx, rates *repository.ExchangeRateRepository, from, to string, date time.Time) (currency.Rate, error) {
+	if from == to {
+		return currency.One(), nil
+	}
+
+	rate, err := rates.Find(c.UserContext(), from, to, date)
+	if err != nil {
+		return currency.Rate{}, utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+	if rate == nil {
+		return currency.Rate{}, utils.NewRequestError("EXCHANGE_RATE_NOT_FOUND",
+			fmt.Errorf("no exchange rate for %s/%s on %s", from, to, date.Format(dateLayout)))
+	}
+
+	return rate.Rate, nil
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"nexa/internal/buildinfo"
+	"nexa/internal/database"
+	"nexa/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type HealthHandler struct {
+	db         *pgxpool.Pool
+	mailServer *utils.MailServer
+}
+
+func NewHealthHandler(db *pgxpool.Pool, mailServer *utils.MailServer) *HealthHandler {
+	return &HealthHandler{
+		db:         db,
+		mailServer: mailServer,
+	}
+}
+
+type dependencyStatus struct {
+	Status    string `json:"status"`
+	Required  bool   `json:"required"`
+	LatencyMs int64  `json:"latencyMs"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Health indica apenas que o processo está de pé.
+func (h *HealthHandler) Health(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{"status": "ok"})
+}
+
+// Ready verifica as dependências necessárias para atender tráfego. O SMTP é
+// reportado quando configurado, mas não torna a instância indisponível.
+func (h *HealthHandler) Ready(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.Context(), 3*time.Second)
+	defer cancel()
+
+	checks := map[string]dependencyStatus{
+		"database":   runCheck(ctx, true, h.db.Ping),
+		"migrations": runCheck(ctx, true, h.checkMigrations),
+	}
+
+	if h.mailServer != nil {
+		checks["mail"] = runCheck(ctx, false, h.mailServer.Ping)
+	}
+
+	status, code := "ready", fiber.StatusOK
+	for _, check := range checks {
+		if check.Required && check.Status != "up" {
+			status, code = "not_ready", fiber.StatusServiceUnavailable
+		}
+	}
+
+	return c.Status(code).JSON(fiber.Map{
+		"status": status,
+		"checks": checks,
+	})
+}
+
+func (h *HealthHandler) Version(c *fiber.Ctx) error {
+	return c.JSON(buildinfo.Get())
+}
+
+func (h *HealthHandler) checkMigrations(ctx context.Context) error {
+	pending, err := database.PendingMigrations(ctx, h.db)
+	if err != nil {
+		return err
+	}
+
+	if len(pending) > 0 {
+		return fmt.Errorf("pending migrations: %s", strings.Join(pending, ", "))
+	}
+
+	return nil
+}
+
+func runCheck(ctx context.Context, required bool, check func(context.Context) error) dependencyStatus {
+	start := time.Now()
+	err := check(ctx)
+
+	status := dependencyStatus{
+		Status:    "up",
+		Required:  required,
+		LatencyMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		status.Status = "down"
+		status.Error = err.Error()
+	}
+
+	return status
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"nexa/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const HeaderAdminKey = "X-Admin-Key"
+
+// NewAdminKeyMiddleware libera a rota só para quem envia a chave
+// administrativa em X-Admin-Key. A comparação é feita sobre o hash, em tempo
+// constante, para não vazar nem o conteúdo nem o tamanho da chave.
+func NewAdminKeyMiddleware(key string) fiber.Handler {
+	expected := sha256.Sum256([]byte(key))
+
+	return func(c *fiber.Ctx) error {
+		hash := sha256.Sum256([]byte(c.Get(HeaderAdminKey)))
+		if subtle.ConstantTimeCompare(hash[:], expected[:]) != 1 {
+			return utils.NewRequestError("FORBIDDEN_ADMIN")
+		}
+
+		return c.Next()
+	}
+}
@@ -2,70 +2,111 @@ package middleware
 
 import (
 	"fmt"
-	"time"
+	"nexa/internal/i18n"
+	"nexa/internal/logger"
+	"nexa/internal/repository"
+	"nexa/internal/utils"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func JWTMiddleware(c *fiber.Ctx) error {
+// NewJWTMiddleware valida o token e, com users, rejeita tokens emitidos antes
+// da última revogação de sessões do usuário (ex.: troca de e-mail). Com
+// settings, o idioma salvo pelo usuário passa a valer para a requisição.
+func NewJWTMiddleware(secret string, users *repository.UserRepository, settings *repository.SettingsRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return jwtMiddleware(c, secret, users, settings)
+	}
+}
+
+func jwtMiddleware(c *fiber.Ctx, secret string, users *repository.UserRepository, settings *repository.SettingsRepository) error {
 	tokenString := c.Get("Authorization")
 	idUser := c.Params("idUser")
-	secret := "secret-key"
 
-	token, response, err := parseToken(tokenString, secret)
+	token, errorType, err := parseToken(tokenString, secret)
 	if err != nil {
-		if err = c.Status(fiber.StatusUnauthorized).JSON(response); err != nil {
-			return fmt.Errorf("filed to encode response: %s", err)
-		}
-
-		return nil
+		return utils.NewRequestError(errorType, err)
 	}
 
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
-		if err := c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"status":  401,
-			"message": "Token inválido ou ausente.",
-		}); err != nil {
-			return fmt.Errorf("failed to send unauthorized response: %w", err)
-		}
-
-		return nil
+		return utils.NewRequestError("INVALID_TOKEN")
 	}
 
 	tokenUserID, ok := claims["sub"].(string)
-	if ok && tokenUserID != idUser {
-		if err := c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"status":         403,
-			"error":          "forbidden",
-			"message":        "Acesso negado. idUser inconsistente.",
-			"expectedIdUser": claims["sub"],
-			"timestamp":      time.Now(),
-		}); err != nil {
-			return fmt.Errorf("ocorreu um erro interno: %w", err)
+	if !ok || tokenUserID == "" {
+		return utils.NewRequestError("INVALID_TOKEN")
+	}
+
+	// Rotas com :idUser só aceitam o dono do token; rotas como /me usam o
+	// próprio sub.
+	if idUser != "" && tokenUserID != idUser {
+		return utils.NewRequestError("FORBIDDEN_USER")
+	}
+
+	if users != nil {
+		if errorType, err := checkSessionRevocation(c, users, tokenUserID, claims); err != nil {
+			return utils.NewRequestError(errorType, err)
 		}
+	}
+	c.Locals(UserIDKey, tokenUserID)
+
+	if settings != nil {
+		applyUserLocale(c, settings, tokenUserID)
+	}
+
+	return c.Next()
+}
+
+// UserID devolve o usuário autenticado pelo JWT middleware.
+func UserID(c *fiber.Ctx) string {
+	userID, _ := c.Locals(UserIDKey).(string)
+	return userID
+}
 
-		return nil
+func checkSessionRevocation(c *fiber.Ctx, users *repository.UserRepository, userID string, claims jwt.MapClaims) (string, error) {
+	revokedAt, found, err := users.SessionsRevokedAt(c.UserContext(), userID)
+	if err != nil {
+		return "INTERNAL_SERVER_ERROR", err
+	}
+	if !found {
+		return "INVALID_TOKEN", fmt.Errorf("user %s not found", userID)
+	}
+	if revokedAt.IsZero() {
+		return "", nil
 	}
 
-	if err := c.Next(); err != nil {
-		return fmt.Errorf("falha ao continar a requisição: %w", err)
+	// O iat do JWT tem resolução de segundos: um token emitido no mesmo segundo
+	// da revogação (ex.: por quem roubou a sessão) precisa cair junto, então a
+	// comparação inclui o próprio segundo. Os tokens legítimos emitidos depois
+	// da revogação saem com iat no segundo seguinte (CreateTokenAfter).
+	issuedAt, err := claims.GetIssuedAt()
+	if err != nil || issuedAt == nil || issuedAt.Unix() <= revokedAt.Unix() {
+		return "INVALID_TOKEN", fmt.Errorf("token issued before sessions were revoked")
 	}
 
-	return nil
+	return "", nil
 }
 
-func parseToken(tokenString string, secret string) (*jwt.Token, fiber.Map, error) {
-	if tokenString == "" {
-		response := fiber.Map{
-			"status":    401,
-			"error":     "unauthorized",
-			"message":   "Token não fornecido ou inválido.",
-			"timestamp": time.Now(),
-		}
+// applyUserLocale usa o idioma das configurações do usuário; sem
+// configurações (ou com erro na busca) fica o locale do Accept-Language.
+func applyUserLocale(c *fiber.Ctx, settings *repository.SettingsRepository, userID string) {
+	userSettings, err := settings.FindByUserID(c.UserContext(), userID)
+	if err != nil {
+		logger.FromCtx(c).Warn().Err(err).Str("userID", userID).Msg("failed to load user locale")
+		return
+	}
+	if userSettings != nil {
+		i18n.SetLocale(c, userSettings.Language)
+	}
+}
 
-		return nil, response, fmt.Errorf("empty token")
+func parseToken(tokenString string, secret string) (*jwt.Token, string, error) {
+	tokenString = strings.TrimPrefix(tokenString, "Bearer ")
+	if tokenString == "" {
+		return nil, "UNAUTHORIZED", fmt.Errorf("empty token")
 	}
 
 	token, err := jwt.Parse(tokenString, func(_ *jwt.Token) (interface{}, error) {
@@ -73,15 +114,8 @@ func parseToken(tokenString string, secret string) (*jwt.Token, fiber.Map, error
 	})
 
 	if err != nil || !token.Valid {
-		response := fiber.Map{
-			"status":    401,
-			"error":     "unauthorized",
-			"message":   "Acesso negado. Token inválido.",
-			"timestamp": time.Now(),
-		}
-
-		return nil, response, fmt.Errorf("invalid token: %w", err)
+		return nil, "INVALID_TOKEN", fmt.Errorf("invalid token: %w", err)
 	}
 
-	return token, nil, nil
+	return token, "", nil
 }
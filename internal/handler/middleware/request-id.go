@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"regexp"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const (
+	HeaderRequestID = "X-Request-ID"
+	RequestIDKey    = "requestID"
+)
+
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._\-]{1,128}$`)
+
+// RequestID reaproveita o X-Request-ID recebido (quando seguro para log) ou
+// gera um novo, e o devolve no cabeçalho da resposta.
+func RequestID(c *fiber.Ctx) error {
+	requestID := c.Get(HeaderRequestID)
+	if !validRequestID.MatchString(requestID) {
+		requestID = uuid.NewString()
+	}
+
+	c.Locals(RequestIDKey, requestID)
+	c.Set(HeaderRequestID, requestID)
+
+	return c.Next()
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"nexa/internal/logger"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const UserIDKey = "idUser"
+
+// RequestLogger cria um logger por requisição com o request ID e, ao final,
+// registra rota, status, latência e usuário autenticado. Em nível debug o
+// corpo é registrado com campos sensíveis ocultados.
+func RequestLogger(c *fiber.Ctx) error {
+	start := time.Now()
+
+	requestID, _ := c.Locals(RequestIDKey).(string)
+	loggerContext := log.Logger.With().
+		Str("requestID", requestID).
+		Str("method", c.Method()).
+		Str("path", c.Path())
+
+	if spanContext := trace.SpanContextFromContext(c.UserContext()); spanContext.IsValid() {
+		loggerContext = loggerContext.Str("traceID", spanContext.TraceID().String())
+	}
+
+	requestLogger := loggerContext.Logger()
+	logger.Attach(c, &requestLogger)
+
+	if zerolog.GlobalLevel() <= zerolog.DebugLevel && c.Is("json") && len(c.Body()) > 0 {
+		requestLogger.Debug().RawJSON("body", logger.RedactJSON(c.Body())).Msg("request body")
+	}
+
+	err := c.Next()
+	if err != nil {
+		if handlerErr := c.App().ErrorHandler(c, err); handlerErr != nil {
+			_ = c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+
+	status := c.Response().StatusCode()
+	event := requestLogger.Info()
+	if status >= fiber.StatusInternalServerError {
+		event = requestLogger.Error()
+	} else if status >= fiber.StatusBadRequest {
+		event = requestLogger.Warn()
+	}
+
+	if userID, ok := c.Locals(UserIDKey).(string); ok {
+		event = event.Str("userID", userID)
+	}
+
+	event.
+		Str("route", c.Route().Path).
+		Int("status", status).
+		Dur("latency", time.Since(start)).
+		Msg("request completed")
+
+	return nil
+}
@@ -0,0 +1,275 @@
+package handler
+
+import (
+	"errors"
+	"nexa/internal/handler/middleware"
+	"nexa/internal/model"
+	"nexa/internal/money"
+	"nexa/internal/recurrence"
+	"nexa/internal/repository"
+	"nexa/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	maxPaymentMethodLength = 30
+	maxListedOccurrences   = 366
+	defaultOccurrencesDays = 90
+)
+
+type RecurringTransactionHandler struct {
+	Recurring *repository.RecurringTransactionRepository
+	Wallets   *repository.WalletRepository
+	// Notify acorda o agendador (jobs.RecurringTransactions.Notify).
+	Notify func()
+}
+
+func NewRecurringTransactionHandler(db *pgxpool.Pool, notify func()) *RecurringTransactionHandler {
+	return &RecurringTransactionHandler{
+		Recurring: repository.NewRecurringTransactionRepository(db),
+		Wallets:   repository.NewWalletRepository(db),
+		Notify:    notify,
+	}
+}
+
+type recurringTransactionRequest struct {
+	WalletID      string       `json:"walletId"`
+	CategoryID    *string      `json:"categoryId"`
+	Amount        money.Amount `json:"amount"`
+	Type          string       `json:"type"`
+	PaymentMethod string       `json:"paymentMethod"`
+	Description   string       `json:"description"`
+	Frequency     string       `json:"frequency"`
+	Interval      int          `json:"interval"`
+	DayOfMonth    int          `json:"dayOfMonth"`
+	BusinessDay   string       `json:"businessDay"`
+	StartDate     string       `json:"startDate"`
+	EndDate       string       `json:"endDate"`
+	Count         int          `json:"count"`
+}
+
+type occurrenceRequest struct {
+	Skip          bool          `json:"skip"`
+	Amount        *money.Amount `json:"amount"`
+	Description   *string       `json:"description"`
+	CategoryID    *string       `json:"categoryId"`
+	PaymentMethod *string       `json:"paymentMethod"`
+}
+
+// CreateRecurring cria um lançamento recorrente. A regra aceita frequency
+// (daily, weekly, monthly, yearly), interval, dayOfMonth (monthly),
+// businessDay (none, following, preceding, modified_following), startDate e,
+// opcionalmente, endDate ou count.
+func (h *RecurringTransactionHandler) CreateRecurring(c *fiber.Ctx) error {
+	userID := middleware.UserID(c)
+
+	var request recurringTransactionRequest
+	if err := c.BodyParser(&request); err != nil {
+		return utils.NewRequestError("INVALID_BODY_FORMAT", err)
+	}
+
+	if request.WalletID == "" {
+		return utils.NewRequestError("REQUIRED_WALLET")
+	}
+	wallet, err := h.Wallets.FindByID(c.UserContext(), request.WalletID, userID)
+	if err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+	if wallet == nil {
+		return utils.NewRequestError("WALLET_NOT_FOUND").WithUserID(userID)
+	}
+
+	recurring := &model.RecurringTransaction{
+		WalletID:      wallet.ID,
+		CategoryID:    request.CategoryID,
+		Amount:        request.Amount,
+		Type:          strings.ToLower(request.Type),
+		PaymentMethod: strings.TrimSpace(request.PaymentMethod),
+		Description:   strings.TrimSpace(request.Description),
+		Rule: recurrence.Rule{
+			Frequency:   strings.ToLower(request.Frequency),
+			Interval:    request.Interval,
+			DayOfMonth:  request.DayOfMonth,
+			BusinessDay: strings.ToLower(request.BusinessDay),
+			Count:       request.Count,
+		},
+	}
+
+	if code := validateTransactionFields(recurring.Amount, recurring.Type, recurring.PaymentMethod); code != "" {
+		return utils.NewRequestError(code)
+	}
+
+	if recurring.StartDate, err = time.Parse(dateLayout, request.StartDate); err != nil {
+		return utils.NewRequestError("INVALID_DATE_FORMAT", err)
+	}
+	if request.EndDate != "" {
+		endDate, err := time.Parse(dateLayout, request.EndDate)
+		if err != nil {
+			return utils.NewRequestError("INVALID_DATE_FORMAT", err)
+		}
+		recurring.EndDate = &endDate
+	}
+	if err := recurring.Normalize(); err != nil {
+		return utils.NewRequestError("INVALID_RECURRENCE", err)
+	}
+
+	err = h.Recurring.Create(c.UserContext(), recurring)
+	if errors.Is(err, repository.ErrCategoryNotFound) {
+		return utils.NewRequestError("INVALID_CATEGORY", err)
+	}
+	if err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+
+	h.Notify()
+
+	return c.Status(fiber.StatusCreated).JSON(recurring)
+}
+
+// ListRecurring lista os lançamentos recorrentes do usuário (query walletId
+// opcional).
+func (h *RecurringTransactionHandler) ListRecurring(c *fiber.Ctx) error {
+	recurring, err := h.Recurring.FindByUserID(c.UserContext(), middleware.UserID(c), c.Query("walletId"))
+	if err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+
+	return c.JSON(recurring)
+}
+
+// StopRecurring encerra a série; os lançamentos já feitos continuam na
+// carteira.
+func (h *RecurringTransactionHandler) StopRecurring(c *fiber.Ctx) error {
+	recurring, err := h.loadRecurring(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.Recurring.Stop(c.UserContext(), recurring.ID); err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ListOccurrences mostra as ocorrências entre from e to (AAAA-MM-DD; padrão:
+// os próximos 90 dias) com status posted, skipped ou scheduled.
+func (h *RecurringTransactionHandler) ListOccurrences(c *fiber.Ctx) error {
+	recurring, err := h.loadRecurring(c)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if value := c.Query("from"); value != "" {
+		if from, err = time.Parse(dateLayout, value); err != nil {
+			return utils.NewRequestError("INVALID_DATE_FORMAT", err)
+		}
+	}
+	to := from.AddDate(0, 0, defaultOccurrencesDays)
+	if value := c.Query("to"); value != "" {
+		if to, err = time.Parse(dateLayout, value); err != nil {
+			return utils.NewRequestError("INVALID_DATE_FORMAT", err)
+		}
+	}
+	if to.Before(from) {
+		return utils.NewRequestError("INVALID_DATE_RANGE")
+	}
+
+	occurrences, err := h.Recurring.Occurrences(c.UserContext(), recurring, from, to, maxListedOccurrences)
+	if err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+
+	return c.JSON(occurrences)
+}
+
+// UpdateOccurrence pula ({"skip": true}) ou altera valor, descrição,
+// categoria ou forma de pagamento de uma ocorrência, identificada pela data
+// nominal em :date. Um corpo sem alterações desfaz a exceção. Ocorrências já
+// lançadas são corrigidas na carteira na hora.
+func (h *RecurringTransactionHandler) UpdateOccurrence(c *fiber.Ctx) error {
+	recurring, err := h.loadRecurring(c)
+	if err != nil {
+		return err
+	}
+
+	date, err := time.Parse(dateLayout, c.Params("date"))
+	if err != nil {
+		return utils.NewRequestError("INVALID_DATE_FORMAT", err)
+	}
+	occurrence, ok := recurring.Find(date)
+	if !ok {
+		return utils.NewRequestError("OCCURRENCE_NOT_FOUND")
+	}
+
+	var request occurrenceRequest
+	if err := c.BodyParser(&request); err != nil {
+		return utils.NewRequestError("INVALID_BODY_FORMAT", err)
+	}
+
+	exception := &model.RecurrenceException{
+		Skip:          request.Skip,
+		Amount:        request.Amount,
+		Description:   request.Description,
+		CategoryID:    request.CategoryID,
+		PaymentMethod: request.PaymentMethod,
+	}
+	if exception.Amount != nil && *exception.Amount <= 0 {
+		return utils.NewRequestError("INVALID_AMOUNT")
+	}
+	if exception.PaymentMethod != nil && len(*exception.PaymentMethod) > maxPaymentMethodLength {
+		return utils.NewRequestError("INVALID_PAYMENT_METHOD")
+	}
+
+	err = h.Recurring.SetException(c.UserContext(), recurring, occurrence, exception)
+	if errors.Is(err, repository.ErrCategoryNotFound) {
+		return utils.NewRequestError("INVALID_CATEGORY", err)
+	}
+	if err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+
+	occurrences, err := h.Recurring.Occurrences(c.UserContext(), recurring, occurrence.Date, occurrence.Date, 1)
+	if err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+	if len(occurrences) == 0 {
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+
+	return c.JSON(occurrences[0])
+}
+
+func (h *RecurringTransactionHandler) loadRecurring(c *fiber.Ctx) (*model.RecurringTransaction, error) {
+	userID := middleware.UserID(c)
+
+	recurring, err := h.Recurring.FindByID(c.UserContext(), c.Params("id"), userID)
+	if err != nil {
+		return nil, utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+	if recurring == nil {
+		return nil, utils.NewRequestError("RECURRING_TRANSACTION_NOT_FOUND").WithUserID(userID)
+	}
+
+	return recurring, nil
+}
+
+// validateTransactionFields confere os campos comuns a todo lançamento.
+func validateTransactionFields(amount money.Amount, kind, paymentMethod string) string {
+	if amount <= 0 {
+		return "INVALID_AMOUNT"
+	}
+	if kind != model.TransactionIncome && kind != model.TransactionExpense {
+		return "INVALID_TRANSACTION_TYPE"
+	}
+	if len(paymentMethod) > maxPaymentMethodLength {
+		return "INVALID_PAYMENT_METHOD"
+	}
+	return ""
+}
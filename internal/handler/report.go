@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"nexa/internal/currency"
+	"nexa/internal/handler/middleware"
+	"nexa/internal/model"
+	"nexa/internal/repository"
+	"nexa/internal/utils"
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ReportHandler struct {
+	Wallets  *repository.WalletRepository
+	Rates    *repository.ExchangeRateRepository
+	Settings *repository.SettingsRepository
+}
+
+func NewReportHandler(db *pgxpool.Pool) *ReportHandler {
+	return &ReportHandler{
+		Wallets:  repository.NewWalletRepository(db),
+		Rates:    repository.NewExchangeRateRepository(db),
+		Settings: repository.NewSettingsRepository(db),
+	}
+}
+
+// GetNetWorth consolida o saldo de todas as carteiras em date (AAAA-MM-DD;
+// padrão: hoje), convertido para currency (padrão: a moeda do usuário) pela
+// última cotação cadastrada até essa data.
+func (h *ReportHandler) GetNetWorth(c *fiber.Ctx) error {
+	userID := middleware.UserID(c)
+
+	date, err := dateOrToday(c.Query("date"))
+	if err != nil {
+		return err
+	}
+
+	target := currency.Normalize(c.Query("currency"))
+	if c.Query("currency") != "" && target == "" {
+		return utils.NewRequestError("INVALID_CURRENCY")
+	}
+	if target == "" {
+		if target, err = userCurrency(c, h.Settings, userID); err != nil {
+			return err
+		}
+	}
+
+	balances, err := h.Wallets.BalancesAt(c.UserContext(), userID, date)
+	if err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+
+	currencies := []string{target}
+	for _, balance := range balances {
+		if !slices.Contains(currencies, balance.Currency) {
+			currencies = append(currencies, balance.Currency)
+		}
+	}
+	rates, err := h.Rates.Latest(c.UserContext(), date, currencies...)
+	if err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+
+	report := model.NetWorth{Date: date, Currency: target, Wallets: balances, MissingCurrencies: []string{}}
+	for i := range report.Wallets {
+		balance := &report.Wallets[i]
+
+		rate, ok := currency.Resolve(rates, balance.Currency, target)
+		if !ok {
+			if !slices.Contains(report.MissingCurrencies, balance.Currency) {
+				report.MissingCurrencies = append(report.MissingCurrencies, balance.Currency)
+			}
+			continue
+		}
+
+		converted := rate.Rate.Convert(balance.Balance)
+		balance.Converted = &converted
+		report.Total += converted
+		if balance.Currency != target {
+			balance.ExchangeRate = &rate.Rate
+			balance.RateDate = &rate.Date
+		}
+	}
+
+	return c.JSON(report)
+}
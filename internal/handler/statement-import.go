@@ -0,0 +1,375 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"nexa/internal/handler/middleware"
+	"nexa/internal/model"
+	"nexa/internal/money"
+	"nexa/internal/repository"
+	"nexa/internal/statement"
+	"nexa/internal/utils"
+	"path/filepath"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	maxStatementBytes = 5 << 20
+	maxBankNameLength = 50
+)
+
+type StatementImportHandler struct {
+	Imports *repository.StatementImportRepository
+	Wallets *repository.WalletRepository
+}
+
+func NewStatementImportHandler(db *pgxpool.Pool) *StatementImportHandler {
+	return &StatementImportHandler{
+		Imports: repository.NewStatementImportRepository(db),
+		Wallets: repository.NewWalletRepository(db),
+	}
+}
+
+// UploadStatement recebe o extrato (multipart: file, walletId e bank
+// opcional) em CSV, OFX ou QIF. Para CSV devolve o mapeamento sugerido ou,
+// se o usuário já importou desse banco, o mapeamento salvo.
+func (h *StatementImportHandler) UploadStatement(c *fiber.Ctx) error {
+	userID := middleware.UserID(c)
+
+	walletID := c.FormValue("walletId")
+	if walletID == "" {
+		return utils.NewRequestError("REQUIRED_WALLET")
+	}
+	wallet, err := h.Wallets.FindByID(c.UserContext(), walletID, userID)
+	if err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+	if wallet == nil {
+		return utils.NewRequestError("WALLET_NOT_FOUND").WithUserID(userID)
+	}
+
+	bank := strings.ToLower(strings.TrimSpace(c.FormValue("bank")))
+	if len(bank) > maxBankNameLength {
+		return utils.NewRequestError("INVALID_BANK")
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return utils.NewRequestError("REQUIRED_STATEMENT_FILE", err)
+	}
+	content, code, err := readStatementFile(fileHeader)
+	if code != "" {
+		return utils.NewRequestError(code, err)
+	}
+
+	imp := &model.StatementImport{
+		UserID:   userID,
+		WalletID: wallet.ID,
+		Bank:     bank,
+		Format:   statement.DetectFormat(fileHeader.Filename, content),
+		Filename: filepath.Base(fileHeader.Filename),
+		Content:  content,
+	}
+
+	var detection *statement.CSVDetection
+	savedMapping := false
+	switch imp.Format {
+	case statement.FormatCSV:
+		detection, err = statement.DetectCSV(content)
+		if err != nil {
+			return utils.NewRequestError("INVALID_STATEMENT_FILE", err)
+		}
+
+		if bank != "" {
+			saved, err := h.Imports.FindBankMapping(c.UserContext(), userID, bank)
+			if err != nil {
+				return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+			}
+			var mapping statement.CSVMapping
+			if saved != nil && json.Unmarshal(saved, &mapping) == nil && mapping.Validate() == nil {
+				detection.Mapping = mapping
+				savedMapping = true
+			}
+		}
+
+		if imp.Mapping, err = json.Marshal(detection.Mapping); err != nil {
+			return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+		}
+	case statement.FormatOFX, statement.FormatQIF:
+		// Formatos estruturados não têm mapeamento; o arquivo só precisa ser legível.
+		if _, code, err := parseStatement(imp); code != "" {
+			return utils.NewRequestError(code, err)
+		}
+	default:
+		return utils.NewRequestError("UNSUPPORTED_STATEMENT_FORMAT")
+	}
+
+	if err := h.Imports.Create(c.UserContext(), imp); err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"import":       imp,
+		"detection":    detection,
+		"savedMapping": savedMapping,
+	})
+}
+
+type importMappingRequest struct {
+	Mapping     *statement.CSVMapping `json:"mapping"`
+	SaveMapping *bool                 `json:"saveMapping"`
+	// Reconcile lança um ajuste para o saldo da carteira bater com o saldo
+	// do extrato (só OFX com LEDGERBAL).
+	Reconcile bool `json:"reconcile"`
+}
+
+// reconciliation compara o saldo do extrato com o saldo que a carteira terá
+// na mesma data depois da importação.
+type reconciliation struct {
+	LedgerBalance    statement.Balance `json:"ledgerBalance"`
+	ProjectedBalance money.Amount      `json:"projectedBalance"`
+	Difference       money.Amount      `json:"difference"`
+}
+
+type previewSummary struct {
+	Total      int `json:"total"`
+	New        int `json:"new"`
+	Duplicates int `json:"duplicates"`
+	Invalid    int `json:"invalid"`
+}
+
+type previewRow struct {
+	statement.Row
+	Duplicate bool `json:"duplicate,omitempty"`
+}
+
+// PreviewImport simula a importação (dry-run) com o mapeamento do corpo ou o
+// último usado, mostrando por linha o lançamento lido, o erro ou se ele já
+// existe na carteira. Nada é gravado além do mapeamento.
+func (h *StatementImportHandler) PreviewImport(c *fiber.Ctx) error {
+	userID := middleware.UserID(c)
+
+	imp, request, err := h.loadImport(c, userID)
+	if err != nil {
+		return err
+	}
+
+	if request.Mapping != nil {
+		if err := h.Imports.UpdateMapping(c.UserContext(), imp.ID, imp.Mapping); err != nil {
+			return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+		}
+	}
+
+	parsed, code, err := parseStatement(imp)
+	if code != "" {
+		return utils.NewRequestError(code, err)
+	}
+	rows := parsed.Rows
+
+	entries := statement.Entries(rows)
+	var duplicates []bool
+	if len(entries) > 0 {
+		from, to := statement.DateRange(entries)
+		existing, err := h.Imports.ExistingEntries(c.UserContext(), imp.WalletID, from, to)
+		if err != nil {
+			return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+		}
+		duplicates = statement.MarkDuplicates(entries, existing)
+	}
+
+	preview := make([]previewRow, len(rows))
+	summary := previewSummary{Total: len(rows)}
+	entryIndex := 0
+	for i, row := range rows {
+		preview[i] = previewRow{Row: row}
+		switch {
+		case row.Entry == nil:
+			summary.Invalid++
+		case duplicates[entryIndex]:
+			preview[i].Duplicate = true
+			summary.Duplicates++
+			entryIndex++
+		default:
+			summary.New++
+			entryIndex++
+		}
+	}
+
+	response := fiber.Map{
+		"import":  imp,
+		"rows":    preview,
+		"summary": summary,
+	}
+
+	if ledger := parsed.LedgerBalance; ledger != nil {
+		balance, err := h.Wallets.BalanceAt(c.UserContext(), imp.WalletID, ledger.Date)
+		if err != nil {
+			return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+		}
+		for i, entry := range entries {
+			if !duplicates[i] && !entry.Date.After(ledger.Date) {
+				balance += entry.Amount
+			}
+		}
+		response["reconciliation"] = reconciliation{
+			LedgerBalance:    *ledger,
+			ProjectedBalance: balance,
+			Difference:       ledger.Amount - balance,
+		}
+	}
+
+	return c.JSON(response)
+}
+
+// CommitImport grava na carteira os lançamentos novos do extrato. O
+// mapeamento usado é salvo para o banco, a menos que saveMapping seja false.
+func (h *StatementImportHandler) CommitImport(c *fiber.Ctx) error {
+	userID := middleware.UserID(c)
+
+	imp, request, err := h.loadImport(c, userID)
+	if err != nil {
+		return err
+	}
+
+	parsed, code, err := parseStatement(imp)
+	if code != "" {
+		return utils.NewRequestError(code, err)
+	}
+
+	var reconcile *statement.Balance
+	if request.Reconcile {
+		if parsed.LedgerBalance == nil {
+			return utils.NewRequestError("NO_LEDGER_BALANCE")
+		}
+		reconcile = parsed.LedgerBalance
+	}
+
+	entries := statement.Entries(parsed.Rows)
+	result, err := h.Imports.Commit(c.UserContext(), imp, entries, len(parsed.Rows)-len(entries), reconcile)
+	if errors.Is(err, repository.ErrImportAlreadyCommitted) {
+		return utils.NewRequestError("IMPORT_ALREADY_COMMITTED").WithUserID(userID)
+	}
+	if err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+
+	if imp.Bank != "" && imp.Mapping != nil && (request.SaveMapping == nil || *request.SaveMapping) {
+		if err := h.Imports.SaveBankMapping(c.UserContext(), userID, imp.Bank, imp.Mapping); err != nil {
+			return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+		}
+	}
+
+	result.Skipped = skippedRows(parsed.Rows)
+
+	return c.JSON(result)
+}
+
+func skippedRows(rows []statement.Row) []statement.Row {
+	skipped := []statement.Row{}
+	for _, row := range rows {
+		if row.Entry == nil {
+			skipped = append(skipped, row)
+		}
+	}
+
+	return skipped
+}
+
+// loadImport busca a importação pendente de :id e aplica o mapeamento do
+// corpo, se houver.
+func (h *StatementImportHandler) loadImport(c *fiber.Ctx, userID string) (*model.StatementImport, *importMappingRequest, error) {
+	var request importMappingRequest
+	if len(c.Body()) > 0 {
+		if err := json.Unmarshal(c.Body(), &request); err != nil {
+			return nil, nil, utils.NewRequestError("INVALID_BODY_FORMAT", err)
+		}
+	}
+
+	imp, err := h.Imports.FindByID(c.UserContext(), c.Params("id"), userID)
+	if err != nil {
+		return nil, nil, utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+	if imp == nil {
+		return nil, nil, utils.NewRequestError("STATEMENT_IMPORT_NOT_FOUND").WithUserID(userID)
+	}
+	if imp.Status != model.StatementImportPending {
+		return nil, nil, utils.NewRequestError("IMPORT_ALREADY_COMMITTED").WithUserID(userID)
+	}
+
+	if request.Mapping != nil {
+		if imp.Format != statement.FormatCSV {
+			return nil, nil, utils.NewRequestError("INVALID_IMPORT_MAPPING")
+		}
+		if err := request.Mapping.Validate(); err != nil {
+			return nil, nil, utils.NewRequestError("INVALID_IMPORT_MAPPING", err)
+		}
+		if imp.Mapping, err = json.Marshal(request.Mapping); err != nil {
+			return nil, nil, utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+		}
+	}
+
+	return imp, &request, nil
+}
+
+func parseStatement(imp *model.StatementImport) (*statement.Statement, string, error) {
+	var (
+		parsed *statement.Statement
+		err    error
+	)
+
+	switch imp.Format {
+	case statement.FormatCSV:
+		var mapping statement.CSVMapping
+		if err := json.Unmarshal(imp.Mapping, &mapping); err != nil {
+			return nil, "INVALID_IMPORT_MAPPING", err
+		}
+		var rows []statement.Row
+		rows, err = statement.ParseCSV(imp.Content, mapping)
+		parsed = &statement.Statement{Rows: rows}
+	case statement.FormatOFX:
+		parsed, err = statement.ParseOFX(imp.Content)
+	case statement.FormatQIF:
+		parsed, err = statement.ParseQIF(imp.Content)
+	default:
+		return nil, "UNSUPPORTED_STATEMENT_FORMAT", nil
+	}
+
+	if errors.Is(err, statement.ErrInvalidMapping) {
+		return nil, "INVALID_IMPORT_MAPPING", err
+	}
+	if err != nil {
+		return nil, "INVALID_STATEMENT_FILE", err
+	}
+
+	return parsed, "", nil
+}
+
+func readStatementFile(fileHeader *multipart.FileHeader) ([]byte, string, error) {
+	if fileHeader.Size > maxStatementBytes {
+		return nil, "STATEMENT_FILE_TOO_LARGE", nil
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, "INVALID_STATEMENT_FILE", err
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(io.LimitReader(file, maxStatementBytes+1))
+	if err != nil {
+		return nil, "INVALID_STATEMENT_FILE", err
+	}
+	if len(content) > maxStatementBytes {
+		return nil, "STATEMENT_FILE_TOO_LARGE", nil
+	}
+	if len(content) == 0 {
+		return nil, "INVALID_STATEMENT_FILE", nil
+	}
+
+	return content, "", nil
+}
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"nexa/internal/handler/middleware"
+	"nexa/internal/i18n"
+	"nexa/internal/logger"
+	"nexa/internal/model"
+	"nexa/internal/repository"
+	"nexa/internal/statement"
+	"nexa/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const dateLayout = "2006-01-02"
+
+type TransactionExportHandler struct {
+	Transactions *repository.TransactionRepository
+	Wallets      *repository.WalletRepository
+}
+
+func NewTransactionExportHandler(db *pgxpool.Pool) *TransactionExportHandler {
+	return &TransactionExportHandler{
+		Transactions: repository.NewTransactionRepository(db),
+		Wallets:      repository.NewWalletRepository(db),
+	}
+}
+
+// ExportTransactions devolve os lançamentos da carteira em CSV, OFX ou XLSX.
+// Query: format, from e to (AAAA-MM-DD, padrão: mês corrente), categoryId,
+// type e locale (padrão: o da requisição; "pt-BR" gera CSV com ";" e
+// vírgula decimal). O arquivo é escrito à medida que as linhas chegam do
+// banco, sem carregar o histórico em memória.
+func (h *TransactionExportHandler) ExportTransactions(c *fiber.Ctx) error {
+	userID := middleware.UserID(c)
+
+	format := strings.ToLower(c.Query("format", statement.FormatCSV))
+	if format != statement.FormatCSV && format != statement.FormatOFX && format != statement.FormatXLSX {
+		return utils.NewRequestError("INVALID_EXPORT_FORMAT")
+	}
+
+	filter, code, err := exportFilter(c)
+	if code != "" {
+		return utils.NewRequestError(code, err)
+	}
+
+	wallet, err := h.Wallets.FindByID(c.UserContext(), c.Params("walletId"), userID)
+	if err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+	if wallet == nil {
+		return utils.NewRequestError("WALLET_NOT_FOUND").WithUserID(userID)
+	}
+	filter.WalletID = wallet.ID
+
+	locale := i18n.Normalize(c.Query("locale"))
+	if locale == "" {
+		locale = i18n.FromCtx(c)
+	}
+
+	options := statement.ExportOptions{
+		Locale:      locale,
+		AccountID:   wallet.ID,
+		AccountName: wallet.Name,
+		Currency:    wallet.Currency,
+		From:        filter.From,
+		To:          filter.To,
+	}
+	if format == statement.FormatOFX {
+		if options.LedgerBalance, err = h.Wallets.BalanceAt(c.UserContext(), wallet.ID, filter.To); err != nil {
+			return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+		}
+	}
+
+	c.Set(fiber.HeaderContentType, statement.ContentType(format))
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, exportFilename(wallet, filter, format)))
+
+	// O corpo é escrito depois que o handler retorna; a requisição já pode ter
+	// sido liberada, então o contexto não pode depender dela.
+	ctx := context.WithoutCancel(c.UserContext())
+	log := logger.FromCtx(c)
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		writer, err := statement.NewWriter(format, w, options)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to start transaction export")
+			return
+		}
+
+		err = h.Transactions.Export(ctx, filter, writer.Write)
+		if err == nil {
+			err = writer.Close()
+		}
+		if err == nil {
+			err = w.Flush()
+		}
+		if err != nil {
+			// Os cabeçalhos já foram enviados; resta registrar e cortar o arquivo.
+			log.Error().Err(err).Str("walletId", filter.WalletID).Msg("transaction export interrupted")
+		}
+	})
+
+	return nil
+}
+
+// exportFilter lê o período e os filtros opcionais da query.
+func exportFilter(c *fiber.Ctx) (repository.TransactionFilter, string, error) {
+	now := time.Now()
+	filter := repository.TransactionFilter{
+		From: time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC),
+	}
+	filter.To = filter.From.AddDate(0, 1, -1)
+
+	var err error
+	if from := c.Query("from"); from != "" {
+		if filter.From, err = time.Parse(dateLayout, from); err != nil {
+			return filter, "INVALID_DATE_FORMAT", err
+		}
+	}
+	if to := c.Query("to"); to != "" {
+		if filter.To, err = time.Parse(dateLayout, to); err != nil {
+			return filter, "INVALID_DATE_FORMAT", err
+		}
+	}
+	if filter.To.Before(filter.From) {
+		return filter, "INVALID_DATE_RANGE", nil
+	}
+
+	if categoryID := c.Query("categoryId"); categoryID != "" {
+		if _, err := uuid.Parse(categoryID); err != nil {
+			return filter, "INVALID_CATEGORY", err
+		}
+		filter.CategoryID = categoryID
+	}
+
+	filter.Type = strings.ToLower(c.Query("type"))
+	switch filter.Type {
+	case "", model.TransactionIncome, model.TransactionExpense, repository.TransferFilter:
+	default:
+		return filter, "INVALID_TRANSACTION_TYPE", nil
+	}
+
+	return filter, "", nil
+}
+
+func exportFilename(wallet *model.Wallet, filter repository.TransactionFilter, format string) string {
+	name := strings.Map(func(r rune) rune {
+		if r < 0x20 || r == '"' || r == '\\' || r == '/' || r >= 0x7f {
+			return '_'
+		}
+		return r
+	}, wallet.Name)
+
+	return fmt.Sprintf("%s_%s_%s.%s", name, filter.From.Format(dateLayout), filter.To.Format(dateLayout), format)
+}
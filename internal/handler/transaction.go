@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"errors"
+	"nexa/internal/currency"
+	"nexa/internal/handler/middleware"
+	"nexa/internal/model"
+	"nexa/internal/money"
+	"nexa/internal/repository"
+	"nexa/internal/utils"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TransactionHandler struct {
+	Transactions *repository.TransactionRepository
+	Wallets      *repository.WalletRepository
+	Rates        *repository.ExchangeRateRepository
+}
+
+func NewTransactionHandler(db *pgxpool.Pool) *TransactionHandler {
+	return &TransactionHandler{
+		Transactions: repository.NewTransactionRepository(db),
+		Wallets:      repository.NewWalletRepository(db),
+		Rates:        repository.NewExchangeRateRepository(db),
+	}
+}
+
+type transactionRequest struct {
+	CategoryID    *string        `json:"categoryId"`
+	Amount        money.Amount   `json:"amount"`
+	Currency      string         `json:"currency"`
+	ExchangeRate  *currency.Rate `json:"exchangeRate"`
+	Type          string         `json:"type"`
+	PaymentMethod string         `json:"paymentMethod"`
+	Date          string         `json:"date"`
+	Description   string         `json:"description"`
+}
+
+// CreateTransaction lança uma receita ou despesa avulsa na carteira. Com
+// currency diferente da moeda da carteira, amount é o valor nessa moeda e é
+// convertido pela cotação da data (ou por exchangeRate, se informada); o
+// lançamento guarda os dois valores. date (AAAA-MM-DD) vale hoje por padrão.
+func (h *TransactionHandler) CreateTransaction(c *fiber.Ctx) error {
+	userID := middleware.UserID(c)
+
+	wallet, err := h.Wallets.FindByID(c.UserContext(), c.Params("walletId"), userID)
+	if err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+	if wallet == nil {
+		return utils.NewRequestError("WALLET_NOT_FOUND").WithUserID(userID)
+	}
+
+	var request transactionRequest
+	if err := c.BodyParser(&request); err != nil {
+		return utils.NewRequestError("INVALID_BODY_FORMAT", err)
+	}
+
+	transaction := &model.Transaction{
+		WalletID:      wallet.ID,
+		CategoryID:    request.CategoryID,
+		Amount:        request.Amount,
+		Type:          strings.ToLower(request.Type),
+		PaymentMethod: strings.TrimSpace(request.PaymentMethod),
+		Description:   strings.TrimSpace(request.Description),
+	}
+	if code := validateTransactionFields(transaction.Amount, transaction.Type, transaction.PaymentMethod); code != "" {
+		return utils.NewRequestError(code)
+	}
+
+	if transaction.Date, err = dateOrToday(strings.TrimSpace(request.Date)); err != nil {
+		return err
+	}
+
+	original := wallet.Currency
+	if request.Currency != "" {
+		if original = currency.Normalize(request.Currency); original == "" {
+			return utils.NewRequestError("INVALID_CURRENCY")
+		}
+	}
+	if original != wallet.Currency {
+		rate := request.ExchangeRate
+		if rate == nil || rate.IsZero() {
+			resolved, err := resolveRate(c, h.Rates, original, wallet.Currency, transaction.Date)
+			if err != nil {
+				return err
+			}
+			rate = &resolved
+		}
+
+		amount := transaction.Amount
+		transaction.OriginalAmount = &amount
+		transaction.OriginalCurrency = &original
+		transaction.ExchangeRate = rate
+		if transaction.Amount = rate.Convert(amount); transaction.Amount <= 0 {
+			return utils.NewRequestError("INVALID_AMOUNT")
+		}
+	}
+
+	err = h.Transactions.Create(c.UserContext(), transaction)
+	if errors.Is(err, repository.ErrCategoryNotFound) {
+		return utils.NewRequestError("INVALID_CATEGORY", err)
+	}
+	if err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(transaction)
+}
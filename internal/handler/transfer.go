@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"nexa/internal/handler/middleware"
+	"nexa/internal/model"
+	"nexa/internal/money"
+	"nexa/internal/repository"
+	"nexa/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TransferHandler struct {
+	Transfers *repository.TransferRepository
+	Wallets   *repository.WalletRepository
+	Rates     *repository.ExchangeRateRepository
+}
+
+func NewTransferHandler(db *pgxpool.Pool) *TransferHandler {
+	return &TransferHandler{
+		Transfers: repository.NewTransferRepository(db),
+		Wallets:   repository.NewWalletRepository(db),
+		Rates:     repository.NewExchangeRateRepository(db),
+	}
+}
+
+// transferRequest serve para criar e editar; na edição, campos ausentes
+// mantêm o valor atual. ToAmount só vale entre moedas diferentes.
+type transferRequest struct {
+	FromWalletID *string       `json:"fromWalletId"`
+	ToWalletID   *string       `json:"toWalletId"`
+	Amount       *money.Amount `json:"amount"`
+	ToAmount     *money.Amount `json:"toAmount"`
+	Date         *string       `json:"date"`
+	Description  *string       `json:"description"`
+}
+
+// CreateTransfer move dinheiro entre duas carteiras do usuário. date
+// (AAAA-MM-DD) é opcional e vale hoje por padrão. Entre moedas diferentes, o
+// valor que entra no destino é toAmount ou, sem ele, amount convertido pela
+// cotação da data.
+func (h *TransferHandler) CreateTransfer(c *fiber.Ctx) error {
+	userID := middleware.UserID(c)
+
+	var request transferRequest
+	if err := c.BodyParser(&request); err != nil {
+		return utils.NewRequestError("INVALID_BODY_FORMAT", err)
+	}
+
+	now := time.Now()
+	transfer := &model.Transfer{
+		UserID: userID,
+		Date:   time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC),
+	}
+	if err := h.apply(c, transfer, &request); err != nil {
+		return err
+	}
+
+	if err := h.Transfers.Create(c.UserContext(), transfer); err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(transfer)
+}
+
+// ListTransfers lista as transferências do usuário (query walletId opcional).
+func (h *TransferHandler) ListTransfers(c *fiber.Ctx) error {
+	transfers, err := h.Transfers.FindByUserID(c.UserContext(), middleware.UserID(c), c.Query("walletId"))
+	if err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+
+	return c.JSON(transfers)
+}
+
+func (h *TransferHandler) GetTransfer(c *fiber.Ctx) error {
+	transfer, err := h.loadTransfer(c)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(transfer)
+}
+
+// UpdateTransfer altera a transferência; os dois lançamentos e os saldos das
+// carteiras acompanham.
+func (h *TransferHandler) UpdateTransfer(c *fiber.Ctx) error {
+	previous, err := h.loadTransfer(c)
+	if err != nil {
+		return err
+	}
+
+	var request transferRequest
+	if err := c.BodyParser(&request); err != nil {
+		return utils.NewRequestError("INVALID_BODY_FORMAT", err)
+	}
+
+	transfer := *previous
+	if err := h.apply(c, &transfer, &request); err != nil {
+		return err
+	}
+
+	if err := h.Transfers.Update(c.UserContext(), &transfer); err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+
+	return c.JSON(transfer)
+}
+
+// DeleteTransfer apaga os dois lados da transferência.
+func (h *TransferHandler) DeleteTransfer(c *fiber.Ctx) error {
+	transfer, err := h.loadTransfer(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.Transfers.Delete(c.UserContext(), transfer); err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// apply valida o pedido e o aplica sobre transfer. Na edição, o valor de
+// destino só é recalculado se valor, data ou carteiras mudarem.
+func (h *TransferHandler) apply(c *fiber.Ctx, transfer *model.Transfer, request *transferRequest) error {
+	userID := transfer.UserID
+	previous := *transfer
+
+	if request.FromWalletID != nil {
+		transfer.FromWalletID = *request.FromWalletID
+	}
+	if request.ToWalletID != nil {
+		transfer.ToWalletID = *request.ToWalletID
+	}
+	if request.Amount != nil {
+		transfer.Amount = *request.Amount
+	}
+	if request.Description != nil {
+		transfer.Description = strings.TrimSpace(*request.Description)
+	}
+	if request.Date != nil {
+		date, err := time.Parse(dateLayout, *request.Date)
+		if err != nil {
+			return utils.NewRequestError("INVALID_DATE_FORMAT", err)
+		}
+		transfer.Date = date
+	}
+
+	if transfer.FromWalletID == "" || transfer.ToWalletID == "" {
+		return utils.NewRequestError("REQUIRED_WALLET")
+	}
+	if transfer.FromWalletID == transfer.ToWalletID {
+		return utils.NewRequestError("SAME_WALLET_TRANSFER")
+	}
+	if transfer.Amount <= 0 {
+		return utils.NewRequestError("INVALID_AMOUNT")
+	}
+
+	wallets := make([]*model.Wallet, 0, 2)
+	for _, walletID := range []string{transfer.FromWalletID, transfer.ToWalletID} {
+		wallet, err := h.Wallets.FindByID(c.UserContext(), walletID, userID)
+		if err != nil {
+			return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+		}
+		if wallet == nil {
+			return utils.NewRequestError("WALLET_NOT_FOUND").WithUserID(userID)
+		}
+		wallets = append(wallets, wallet)
+	}
+	from, to := wallets[0].Currency, wallets[1].Currency
+
+	switch {
+	case from == to:
+		transfer.ToAmount = transfer.Amount
+	case request.ToAmount != nil:
+		if *request.ToAmount <= 0 {
+			return utils.NewRequestError("INVALID_AMOUNT")
+		}
+		transfer.ToAmount = *request.ToAmount
+	case transfer.ToAmount == 0 || transfer.Amount != previous.Amount || !transfer.Date.Equal(previous.Date) ||
+		transfer.FromWalletID != previous.FromWalletID || transfer.ToWalletID != previous.ToWalletID:
+		rate, err := resolveRate(c, h.Rates, from, to, transfer.Date)
+		if err != nil {
+			return err
+		}
+		if transfer.ToAmount = rate.Convert(transfer.Amount); transfer.ToAmount <= 0 {
+			return utils.NewRequestError("INVALID_AMOUNT")
+		}
+	}
+
+	return nil
+}
+
+func (h *TransferHandler) loadTransfer(c *fiber.Ctx) (*model.Transfer, error) {
+	userID := middleware.UserID(c)
+
+	transfer, err := h.Transfers.FindByID(c.UserContext(), c.Params("id"), userID)
+	if err != nil {
+		return nil, utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+	if transfer == nil {
+		return nil, utils.NewRequestError("TRANSFER_NOT_FOUND").WithUserID(userID)
+	}
+
+	return transfer, nil
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"nexa/internal/handler/middleware"
+	"nexa/internal/utils"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DeactivateMe desativa a conta do usuário autenticado e encerra todas as
+// sessões. Um novo login dentro do período de carência reativa a conta;
+// depois dele, o job de exclusão apaga os dados definitivamente (LGPD).
+func (u *UserHandler) DeactivateMe(c *fiber.Ctx) error {
+	userID := middleware.UserID(c)
+
+	var body struct {
+		Password string `json:"password"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return utils.NewRequestError("INVALID_BODY_FORMAT", err)
+	}
+	if body.Password == "" {
+		return utils.NewRequestError("REQUIRED_PASSWORD")
+	}
+
+	user, err := u.UserRepository.FindByFilter(c.UserContext(), "id", userID)
+	if err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+	if user == nil {
+		return utils.NewRequestError("USER_NOT_FOUND").WithUserID(userID)
+	}
+
+	if _, err := u.validateLoginCredentials(user, body.Password); err != nil {
+		return utils.NewRequestError("INVALID_LOGIN_CREDENTIALS")
+	}
+
+	// Contas não verificadas também são inativas; só contas ativas podem ser
+	// desativadas, para que a reativação não pule a verificação de e-mail.
+	if !user.IsActive {
+		return utils.NewRequestError("USER_NOT_ACTIVE").WithUserID(userID)
+	}
+
+	now := time.Now()
+	err = u.UserRepository.UpdateByID(c.UserContext(), userID, map[string]interface{}{
+		"is_active":           false,
+		"deactivated_at":      now,
+		"sessions_revoked_at": now.Truncate(time.Second),
+	})
+	if err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message":             "Conta desativada. Faça login novamente antes da data de exclusão para reativá-la.",
+		"deletionScheduledAt": now.Add(u.Config.Account.DeletionGracePeriod()),
+	})
+}
+
+// reactivateIfWithinGracePeriod reativa, no login, uma conta desativada que
+// ainda não passou do período de carência.
+func (u *UserHandler) reactivateIfWithinGracePeriod(c *fiber.Ctx, userID string, deactivatedAt time.Time) (bool, error) {
+	if time.Since(deactivatedAt) > u.Config.Account.DeletionGracePeriod() {
+		return false, nil
+	}
+
+	err := u.UserRepository.UpdateByID(c.UserContext(), userID, map[string]interface{}{
+		"is_active":      true,
+		"deactivated_at": nil,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -1,107 +1,115 @@
 package handler
 
 import (
-	"bytes"
+	"context"
 	"fmt"
+	"nexa/internal/config"
 	"nexa/internal/factory"
+	"nexa/internal/i18n"
 	"nexa/internal/model"
 	"nexa/internal/repository"
 	"nexa/internal/security"
 	"nexa/internal/utils"
-	"os"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt"
-	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type UserAuthenticationHandler struct {
 	UserRepository                 *repository.UserRepository
 	UserAuthenticationTokenRepo    *repository.UserAuthenticationTokenRepository
+	EmailChangeTokenRepo           *repository.UserAuthenticationTokenRepository
 	UserAuthenticationTokenBuilder *factory.UserAuthenticationTokenFactory
+	SettingsRepository             *repository.SettingsRepository
 	MailServer                     *utils.MailServer
+	Config                         *config.Config
 }
 
-func NewUserAuthenticationHandler(db *pgx.Conn, mailServer *utils.MailServer) *UserAuthenticationHandler {
+func NewUserAuthenticationHandler(db *pgxpool.Pool, mailServer *utils.MailServer, cfg *config.Config) *UserAuthenticationHandler {
 	return &UserAuthenticationHandler{
+		Config:                         cfg,
 		UserRepository:                 repository.NewUserRepository(db),
 		UserAuthenticationTokenRepo:    repository.NewUserAuthenticationTokenRepository(db, "db_nexa", "tb_user_authentication_token"),
+		EmailChangeTokenRepo:           repository.NewUserAuthenticationTokenRepository(db, "db_nexa", "tb_email_change_token"),
 		UserAuthenticationTokenBuilder: factory.NewUserAuthenticationTokenFactory(),
+		SettingsRepository:             repository.NewSettingsRepository(db),
 		MailServer:                     mailServer,
 	}
 }
 
-func (ua *UserAuthenticationHandler) HandleInitialAuthentication(userID string) error {
-	token, err := ua.createUserAuthenticationToken(userID)
+func (ua *UserAuthenticationHandler) HandleInitialAuthentication(ctx context.Context, userID string) error {
+	token, err := ua.createUserAuthenticationToken(ctx, userID)
 	if err != nil {
 		return err
 	}
-	_, err = ua.sendAuthenticationEmail(token.Code, userID)
+	_, err = ua.sendAuthenticationEmail(ctx, token.Code, userID)
 	return err
 }
 
 func (ua *UserAuthenticationHandler) VerifyUser(c *fiber.Ctx) error {
-	userID, code, err := ua.getUserIDAndCode(c)
+	userID, code, errorType, err := ua.getUserIDAndCode(c)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		return utils.NewRequestError(errorType, err)
 	}
 
-	token, err := ua.UserAuthenticationTokenRepo.FindTokenByUserID(userID)
+	token, err := ua.UserAuthenticationTokenRepo.FindTokenByUserID(c.UserContext(), userID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
 	}
 
-	codeStatus, err := ua.validateTokenAndCreateNewIfNeeded(token, userID, code)
+	codeStatus, err := ua.validateTokenAndCreateNewIfNeeded(c.UserContext(), token, userID, code)
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": codeStatus})
+		return utils.NewRequestError(codeStatus, err)
 	}
 
-	if err := ua.activateUserAccount(userID); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	if err := ua.activateUserAccount(c.UserContext(), userID); err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
 	}
 
 	// Gere o JWT com o ID do usuário como sub
-	tokenStr, err := utils.GenerateJWT(userID)
+	tokenStr, err := utils.GenerateJWT(ua.Config.JWT.Secret, userID)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Erro ao gerar token"})
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", fmt.Errorf("failed to generate JWT: %w", err))
 	}
 
 	return c.JSON(fiber.Map{"token": tokenStr})
 }
 
-func (ua *UserAuthenticationHandler) activateUserAccount(userID string) error {
-	return ua.UserRepository.UpdateByID(userID, map[string]interface{}{"is_active": true})
+func (ua *UserAuthenticationHandler) activateUserAccount(ctx context.Context, userID string) error {
+	return ua.UserRepository.UpdateByID(ctx, userID, map[string]interface{}{"is_active": true})
 }
 
-func (ua *UserAuthenticationHandler) getUserIDAndCode(c *fiber.Ctx) (string, string, error) {
+func (ua *UserAuthenticationHandler) getUserIDAndCode(c *fiber.Ctx) (string, string, string, error) {
 	var request struct {
 		UserID string `json:"idUser"`
 		Code   string `json:"code"`
 	}
 	if err := c.BodyParser(&request); err != nil {
-		return "", "", err
+		return "", "", "INVALID_BODY_FORMAT", err
 	}
 	if request.UserID == "" || request.Code == "" {
-		return "", "", fmt.Errorf("userID or code missing")
+		return "", "", "REQUIRED_AUTHENTICATION_CODE", fmt.Errorf("userID or code missing")
 	}
-	return request.UserID, request.Code, nil
+	return request.UserID, request.Code, "", nil
 }
 
-func (ua *UserAuthenticationHandler) validateTokenAndCreateNewIfNeeded(token *model.UserAuthenticationToken, userID, code string) (string, error) {
+func (ua *UserAuthenticationHandler) validateTokenAndCreateNewIfNeeded(ctx context.Context, token *model.UserAuthenticationToken, userID, code string) (string, error) {
 	if token == nil || token.HasExpired() || token.Fails > 2 {
 		var tokenID string
 		if token != nil {
 			tokenID = token.ID
 		}
-		HTTPError, err := ua.deleteAndCreateNewUserAuthenticationToken(tokenID, userID)
+		HTTPError, err := ua.deleteAndCreateNewUserAuthenticationToken(ctx, tokenID, userID)
 		if err != nil {
 			return HTTPError, err
 		}
 		return "EXPIRED_AUTHENTICATION_TOKEN", fmt.Errorf("token expirado")
 	}
 	if token.Code != code {
-		if err := ua.UserAuthenticationTokenRepo.IncrementFails(token.ID); err != nil {
+		if err := ua.UserAuthenticationTokenRepo.IncrementFails(ctx, token.ID); err != nil {
 			return "INTERNAL_SERVER_ERROR", err
 		}
 		return "INVALID_USER_AUTHENTICATION_TOKEN", fmt.Errorf("código inválido")
@@ -109,29 +117,29 @@ func (ua *UserAuthenticationHandler) validateTokenAndCreateNewIfNeeded(token *mo
 	return "", nil
 }
 
-func (ua *UserAuthenticationHandler) deleteAndCreateNewUserAuthenticationToken(tokenID, userID string) (string, error) {
+func (ua *UserAuthenticationHandler) deleteAndCreateNewUserAuthenticationToken(ctx context.Context, tokenID, userID string) (string, error) {
 	if tokenID != "" {
-		_ = ua.UserAuthenticationTokenRepo.Delete(tokenID)
+		_ = ua.UserAuthenticationTokenRepo.Delete(ctx, tokenID)
 	}
-	token, err := ua.createUserAuthenticationToken(userID)
+	token, err := ua.createUserAuthenticationToken(ctx, userID)
 	if err != nil {
 		return "INTERNAL_SERVER_ERROR", err
 	}
-	HTTPError, err := ua.sendAuthenticationEmail(token.Code, userID)
+	HTTPError, err := ua.sendAuthenticationEmail(ctx, token.Code, userID)
 	if err != nil {
 		return HTTPError, err
 	}
 	return "", nil
 }
 
-func (ua *UserAuthenticationHandler) createUserAuthenticationToken(userID string) (*model.UserAuthenticationToken, error) {
+func (ua *UserAuthenticationHandler) createUserAuthenticationToken(ctx context.Context, userID string) (*model.UserAuthenticationToken, error) {
 	code, err := utils.GenerateCode(4)
 	if err != nil {
 		return nil, err
 	}
 	token := ua.UserAuthenticationTokenBuilder.CreateUserAuthenticationToken(userID, code, 1440)
 	// Insere e obtém ID gerado
-	id, err := ua.UserAuthenticationTokenRepo.Insert(token)
+	id, err := ua.UserAuthenticationTokenRepo.Insert(ctx, token)
 	if err != nil {
 		return nil, err
 	}
@@ -139,57 +147,70 @@ func (ua *UserAuthenticationHandler) createUserAuthenticationToken(userID string
 	return token, nil
 }
 
-func (ua *UserAuthenticationHandler) sendAuthenticationEmail(code string, userID string) (string, error) {
-	user, err := ua.UserRepository.FindByFilter("id", userID)
+func (ua *UserAuthenticationHandler) sendAuthenticationEmail(ctx context.Context, code string, userID string) (string, error) {
+	user, err := ua.UserRepository.FindByFilter(ctx, "id", userID)
 	if err != nil {
 		return "INTERNAL_SERVER_ERROR", err
 	}
 	if user == nil {
-		return "NOT_FOUND", fmt.Errorf("user not found")
+		return "USER_NOT_FOUND", fmt.Errorf("user not found")
 	}
 
-	var body bytes.Buffer
-	template, err := utils.ParseFile("assets/index.html")
-	if err != nil {
+	locale := ua.userLocale(ctx, userID)
+
+	if err := ua.MailServer.SendTemplateEmail(ctx, locale, "authEmail.html", "EMAIL_VERIFICATION_SUBJECT", user.Email, struct {
+		Name   string
+		Code   string
+		Digits []string
+	}{Name: user.Name, Code: code, Digits: strings.Split(code, "")}); err != nil {
 		return "INTERNAL_SERVER_ERROR", err
 	}
 
-	if err = template.Execute(&body, struct {
-		Name string
-		Code string
-	}{Name: user.Name, Code: code}); err != nil {
-		return "INTERNAL_SERVER_ERROR", err
+	return "", nil
+}
+
+// userLocale usa o idioma salvo nas configurações do usuário, que é
+// preenchido no cadastro a partir do Accept-Language.
+func (ua *UserAuthenticationHandler) userLocale(ctx context.Context, userID string) string {
+	settings, err := ua.SettingsRepository.FindByUserID(ctx, userID)
+	if err != nil || settings == nil {
+		return i18n.DefaultLocale
 	}
 
-	if err = ua.MailServer.SendEmailHTML(
-		"Validação de E-mail",
-		body.String(),
-		[]string{user.Email},
-	); err != nil {
-		return "INTERNAL_SERVER_ERROR", err
+	if locale := i18n.Normalize(settings.Language); locale != "" {
+		return locale
 	}
 
-	return "", nil
+	return i18n.DefaultLocale
 }
 
 func (ua *UserAuthenticationHandler) GetPublicKey(c *fiber.Ctx) error {
-	pubPEM, err := security.LoadPublicKeyPEMFlatString()
+	pubPEM, err := security.LoadPublicKeyPEMFlatString(ua.Config.Keys.PublicKey)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Erro ao carregar chave pública"})
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", fmt.Errorf("failed to load public key: %w", err))
 	}
 	return c.JSON(fiber.Map{"publicKey": pubPEM})
 }
 
-var secretKey = []byte("secret-key")
+// CreateTokenAfter emite um token com iat posterior ao segundo de revokedAt,
+// para que ele não seja tratado como revogado pela revogação que o precedeu.
+func (ua *UserAuthenticationHandler) CreateTokenAfter(id string, issuer string, revokedAt time.Time) (string, error) {
+	issuedAt := time.Now()
+	if next := revokedAt.Truncate(time.Second).Add(time.Second); issuedAt.Before(next) {
+		issuedAt = next
+	}
+
+	return ua.createToken(id, issuer, issuedAt)
+}
 
-func (ua *UserAuthenticationHandler) CreateToken(id string, issuer string) (string, error) {
-	secretKey := []byte(os.Getenv("JWT_SECRET"))
+func (ua *UserAuthenticationHandler) createToken(id string, issuer string, issuedAt time.Time) (string, error) {
+	secretKey := []byte(ua.Config.JWT.Secret)
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"sub": id,
 		"iss": issuer,
-		"iat": time.Now().Unix(),
-		"exp": time.Now().Add(2 * time.Hour).Unix(),
+		"iat": issuedAt.Unix(),
+		"exp": issuedAt.Add(2 * time.Hour).Unix(),
 	})
 
 	tokenString, err := token.SignedString(secretKey)
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"errors"
+	"nexa/internal/handler/middleware"
+	"nexa/internal/repository"
+	"nexa/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type DataExportHandler struct {
+	Exports *repository.DataExportRepository
+	// Notify acorda o worker de exportação (jobs.DataExport.Notify).
+	Notify func()
+}
+
+func NewDataExportHandler(db *pgxpool.Pool, notify func()) *DataExportHandler {
+	return &DataExportHandler{
+		Exports: repository.NewDataExportRepository(db),
+		Notify:  notify,
+	}
+}
+
+// RequestExport enfileira a exportação dos dados do usuário autenticado
+// (LGPD). O arquivo é montado em segundo plano e o link chega por e-mail;
+// enquanto houver um pedido na fila ou um link válido, ele é devolvido em vez
+// de criar outro.
+func (h *DataExportHandler) RequestExport(c *fiber.Ctx) error {
+	userID := middleware.UserID(c)
+
+	export, err := h.Exports.FindActiveByUserID(c.UserContext(), userID)
+	if err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+	if export != nil {
+		return c.Status(fiber.StatusOK).JSON(export)
+	}
+
+	export, err = h.Exports.Create(c.UserContext(), userID)
+	if errors.Is(err, repository.ErrDataExportInProgress) {
+		// Outro pedido simultâneo ganhou a corrida.
+		export, err = h.Exports.FindActiveByUserID(c.UserContext(), userID)
+	}
+	if err != nil || export == nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+
+	h.Notify()
+
+	return c.Status(fiber.StatusAccepted).JSON(export)
+}
+
+// GetExport devolve o status de um pedido do próprio usuário; downloadUrl só
+// aparece enquanto o arquivo está disponível.
+func (h *DataExportHandler) GetExport(c *fiber.Ctx) error {
+	userID := middleware.UserID(c)
+
+	export, err := h.Exports.FindByID(c.UserContext(), c.Params("id"), userID)
+	if err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+	if export == nil {
+		return utils.NewRequestError("DATA_EXPORT_NOT_FOUND").WithUserID(userID)
+	}
+
+	return c.JSON(export)
+}
@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"nexa/internal/handler/middleware"
+	"nexa/internal/logger"
+	"nexa/internal/repository"
+	"nexa/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	emailChangeCodeLength = 6
+	emailChangeTTLMinutes = 60
+	emailChangeMaxFails   = 3
+)
+
+// RequestEmailChange guarda o novo e-mail como pendente, envia um código para
+// ele e avisa o endereço atual. A troca só acontece em ConfirmEmailChange.
+func (u *UserHandler) RequestEmailChange(c *fiber.Ctx) error {
+	userID := middleware.UserID(c)
+
+	var body struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return utils.NewRequestError("INVALID_BODY_FORMAT", err)
+	}
+
+	email := strings.ToLower(strings.TrimSpace(body.Email))
+	if email == "" || body.Password == "" {
+		return utils.NewRequestError("REQUIRED_LOGIN_CREDENTIALS")
+	}
+
+	if match, _ := emailRegex.MatchString(email); !match {
+		return utils.NewRequestError("INVALID_EMAIL")
+	}
+
+	user, err := u.UserRepository.FindByFilter(c.UserContext(), "id", userID)
+	if err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+	if user == nil {
+		return utils.NewRequestError("USER_NOT_FOUND").WithUserID(userID)
+	}
+
+	if _, err := u.validateLoginCredentials(user, body.Password); err != nil {
+		return utils.NewRequestError("INVALID_LOGIN_CREDENTIALS")
+	}
+
+	if email == user.Email {
+		return utils.NewRequestError("SAME_EMAIL")
+	}
+
+	existing, err := u.UserRepository.FindByFilter(c.UserContext(), "email", email)
+	if err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+	if existing != nil {
+		return utils.NewRequestError("USER_ALREADY_REGISTERED")
+	}
+
+	auth := u.UserAuthenticationHandler
+	if err := u.deleteEmailChangeToken(c, userID); err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+
+	code, err := utils.GenerateCode(emailChangeCodeLength)
+	if err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+
+	token := auth.UserAuthenticationTokenBuilder.CreateUserAuthenticationToken(userID, code, emailChangeTTLMinutes)
+	if token.ID, err = auth.EmailChangeTokenRepo.Insert(c.UserContext(), token); err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+
+	if err := u.UserRepository.UpdateByID(c.UserContext(), userID, map[string]interface{}{"pending_email": email}); err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+
+	locale := auth.userLocale(c.UserContext(), userID)
+
+	if err := auth.MailServer.SendTemplateEmail(c.UserContext(), locale, "emailChange.html", "EMAIL_CHANGE_SUBJECT", email, struct {
+		Name             string
+		Digits           []string
+		ExpiresInMinutes int
+	}{Name: user.Name, Digits: strings.Split(code, ""), ExpiresInMinutes: emailChangeTTLMinutes}); err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", fmt.Errorf("failed to send email change code: %w", err))
+	}
+
+	// O aviso ao endereço atual é best effort: a troca continua protegida
+	// pelo código enviado ao novo endereço.
+	if err := auth.MailServer.SendTemplateEmail(c.UserContext(), locale, "emailChangeNotice.html", "EMAIL_CHANGE_NOTICE_SUBJECT", user.Email, struct {
+		Name     string
+		NewEmail string
+	}{Name: user.Name, NewEmail: email}); err != nil {
+		logger.FromCtx(c).Warn().Err(err).Str("userID", userID).Msg("failed to send email change notice")
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message":      "Código enviado para o novo e-mail",
+		"pendingEmail": email,
+		"expiresAt":    token.ExpiresAt,
+	})
+}
+
+// ConfirmEmailChange troca o e-mail pelo pendente quando o código confere,
+// encerra as demais sessões e devolve um token novo para a sessão atual.
+func (u *UserHandler) ConfirmEmailChange(c *fiber.Ctx) error {
+	userID := middleware.UserID(c)
+
+	var body struct {
+		Code string `json:"code"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return utils.NewRequestError("INVALID_BODY_FORMAT", err)
+	}
+
+	code := strings.ToUpper(strings.TrimSpace(body.Code))
+	if code == "" {
+		return utils.NewRequestError("REQUIRED_CODE")
+	}
+
+	user, err := u.UserRepository.FindByFilter(c.UserContext(), "id", userID)
+	if err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+	if user == nil {
+		return utils.NewRequestError("USER_NOT_FOUND").WithUserID(userID)
+	}
+	if user.PendingEmail == "" {
+		return utils.NewRequestError("NO_PENDING_EMAIL_CHANGE")
+	}
+
+	auth := u.UserAuthenticationHandler
+	token, err := auth.EmailChangeTokenRepo.FindTokenByUserID(c.UserContext(), userID)
+	if err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+
+	if token == nil || token.HasExpired() || token.Fails >= emailChangeMaxFails {
+		if err := u.cancelEmailChange(c, userID); err != nil {
+			return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+		}
+		return utils.NewRequestError("EXPIRED_EMAIL_CHANGE_CODE")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(token.Code), []byte(code)) != 1 {
+		if err := auth.EmailChangeTokenRepo.IncrementFails(c.UserContext(), token.ID); err != nil {
+			return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+		}
+		return utils.NewRequestError("INVALID_USER_AUTHENTICATION_TOKEN")
+	}
+
+	// A revogação é guardada em segundos inteiros, a resolução do iat.
+	revokedAt := time.Now().Truncate(time.Second)
+	err = u.UserRepository.UpdateByID(c.UserContext(), userID, map[string]interface{}{
+		"email":               user.PendingEmail,
+		"pending_email":       "",
+		"sessions_revoked_at": revokedAt,
+	})
+	if errors.Is(err, repository.ErrEmailTaken) {
+		if err := u.cancelEmailChange(c, userID); err != nil {
+			return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+		}
+		return utils.NewRequestError("USER_ALREADY_REGISTERED")
+	}
+	if err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+
+	if err := auth.EmailChangeTokenRepo.Delete(c.UserContext(), token.ID); err != nil {
+		logger.FromCtx(c).Warn().Err(err).Str("userID", userID).Msg("failed to delete email change token")
+	}
+
+	jwtToken, err := auth.CreateTokenAfter(userID, "/me/email/confirm", revokedAt)
+	if err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", fmt.Errorf("failed to create JWT token: %w", err))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "E-mail alterado com sucesso",
+		"email":   user.PendingEmail,
+		"token":   jwtToken,
+	})
+}
+
+func (u *UserHandler) cancelEmailChange(c *fiber.Ctx, userID string) error {
+	if err := u.deleteEmailChangeToken(c, userID); err != nil {
+		return err
+	}
+
+	return u.UserRepository.UpdateByID(c.UserContext(), userID, map[string]interface{}{"pending_email": ""})
+}
+
+func (u *UserHandler) deleteEmailChangeToken(c *fiber.Ctx, userID string) error {
+	repo := u.UserAuthenticationHandler.EmailChangeTokenRepo
+
+	token, err := repo.FindTokenByUserID(c.UserContext(), userID)
+	if err != nil || token == nil {
+		return err
+	}
+
+	return repo.Delete(c.UserContext(), token.ID)
+}
@@ -2,87 +2,204 @@ package handler
 
 import (
 	"bytes"
-	"crypto/sha1"
-	"encoding/hex"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
-	"net/http"
-	"net/url"
+	"nexa/internal/config"
+	"nexa/internal/currency"
 	"nexa/internal/factory"
+	"nexa/internal/handler/middleware"
+	"nexa/internal/i18n"
+	"nexa/internal/imaging"
+	"nexa/internal/logger"
+	"nexa/internal/metrics"
 	"nexa/internal/model"
 	"nexa/internal/repository"
 	"nexa/internal/security"
+	"nexa/internal/storage"
 	"nexa/internal/utils"
-	"os"
 	"regexp"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/dlclark/regexp2"
 	"github.com/gofiber/fiber/v2"
-	"github.com/jackc/pgx/v5"
-	"github.com/rs/zerolog/log"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type UserHandler struct {
 	UserFactory               *factory.UserFactory
 	UserRepository            *repository.UserRepository
 	UserAuthenticationHandler *UserAuthenticationHandler
+	PasswordManager           *security.PasswordManager
+	PasswordPolicy            *security.PasswordPolicy
+	Storage                   storage.ObjectStorage
+	Config                    *config.Config
 }
 
-func NewUserHandler(db *pgx.Conn) *UserHandler {
+func NewUserHandler(db *pgxpool.Pool, cfg *config.Config, objectStorage storage.ObjectStorage, authHandler *UserAuthenticationHandler) *UserHandler {
 	return &UserHandler{
+		Config:                    cfg,
+		Storage:                   objectStorage,
 		UserRepository:            repository.NewUserRepository(db),
 		UserFactory:               factory.NewUserFactory(),
-		UserAuthenticationHandler: NewUserAuthenticationHandler(db, nil),
+		UserAuthenticationHandler: authHandler,
+		PasswordManager:           security.InitPasswordManager(cfg.Password),
+		PasswordPolicy:            security.NewPasswordPolicy(),
 	}
 }
 
 func (u *UserHandler) RegisterUser(c *fiber.Ctx) error {
-	var modelUser model.User
+	var request model.RegisterUserRequest
+	if err := c.BodyParser(&request); err != nil {
+		return utils.NewRequestError("INVALID_BODY_FORMAT", err)
+	}
 
-	if err := c.BodyParser(&modelUser); err != nil {
-		return c.Status(400).JSON(utils.EncodeRequestError(c, "INVALID_BODY_FORMAT"))
+	modelUser := model.User{
+		Name:      request.Name,
+		FirstName: request.FirstName,
+		LastName:  request.LastName,
+		Username:  request.Username,
+		Email:     request.Email,
+		Password:  request.Password,
 	}
 
-	if len(modelUser.Name) > 20 {
-		return c.Status(400).JSON(fiber.Map{"error": "Invalid name", "message": "O nome não deve conter mais de 20 caracteres"})
+	// Clientes antigos enviam só "name"; ele é separado em primeiro nome e sobrenome.
+	if modelUser.FirstName == "" && modelUser.LastName == "" {
+		modelUser.FirstName, modelUser.LastName = utils.SplitName(modelUser.Name)
 	}
 
-	passwordRegex := regexp2.MustCompile(`^(?=.*[A-Z])(?=.*\d)(?=.*[!@#\$%\^&\*\(\)_\+\-=\[\]{};':"\\|,.<>\/?]).{6,}$`, 0)
-	match, _ := passwordRegex.MatchString(modelUser.Password)
+	firstName, lastName, code, err := u.validateNames(modelUser.FirstName, modelUser.LastName)
+	if err != nil {
+		return utils.NewRequestError(code, err)
+	}
+	modelUser.FirstName = firstName
+	modelUser.LastName = lastName
+	modelUser.Name = utils.JoinName(firstName, lastName)
 
-	if !match {
-		return c.Status(400).JSON(fiber.Map{"error": "Invalid password", "message": "A senha deve possuir no mínimo 6 caracteres, contendo uma letra maiúscula, um número e um caractere especial"})
+	modelUser.Email = strings.ToLower(strings.TrimSpace(modelUser.Email))
+	modelUser.Username = strings.ToLower(strings.TrimSpace(modelUser.Username))
+
+	if code, err := u.ValidateUsername(c.UserContext(), modelUser.Username, ""); err != nil {
+		return utils.NewRequestError(code, err)
 	}
 
-	emailRegex := regexp2.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`, 0)
-	match, _ = emailRegex.MatchString(modelUser.Email)
+	violations := u.PasswordPolicy.Check(modelUser.Password, security.PersonalInfo{
+		Name:     modelUser.Name,
+		Username: modelUser.Username,
+		Email:    modelUser.Email,
+	})
+	if len(violations) > 0 {
+		return utils.NewRequestErrors(violations)
+	}
 
+	match, _ := emailRegex.MatchString(modelUser.Email)
 	if !match {
-		return c.Status(400).JSON(fiber.Map{"error": "Invalid email", "message": "O email inserido não é válido"})
+		return utils.NewRequestError("INVALID_EMAIL")
+	}
+
+	existing, err := u.UserRepository.FindByFilter(c.UserContext(), "email", modelUser.Email)
+	if err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+	if existing != nil {
+		return utils.NewRequestError("USER_ALREADY_REGISTERED")
 	}
 
 	modelUser.LastLogin = time.Now()
 
-	hash, err := security.EncryptPassword(modelUser.Password)
+	hash, err := u.PasswordManager.Hash(modelUser.Password)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"message": "Internal Server Error", "error": err.Error()})
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
 	}
 
-	modelUser.Password = string(hash)
+	modelUser.Password = hash
 
-	err = u.UserRepository.InsertUser(&modelUser)
+	err = u.UserRepository.InsertUser(c.UserContext(), &modelUser)
+	if errors.Is(err, repository.ErrEmailTaken) {
+		return utils.NewRequestError("USER_ALREADY_REGISTERED")
+	}
+	if errors.Is(err, repository.ErrUsernameTaken) {
+		return utils.NewRequestError("USERNAME_ALREADY_TAKEN")
+	}
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"message": "Internal Server Error", "error": err.Error()})
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+
+	if err := u.UserAuthenticationHandler.SettingsRepository.Upsert(c.UserContext(), &model.Settings{
+		UserID:   modelUser.ID,
+		Language: i18n.FromCtx(c),
+	}); err != nil {
+		logger.FromCtx(c).Warn().Err(err).Str("userID", modelUser.ID).Msg("failed to create user settings")
 	}
 
 	return c.Status(201).JSON(fiber.Map{"message": "User creation successful"})
 }
 
+var emailRegex = regexp2.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`, 0)
+
+// CheckAvailability responde se username e/ou e-mail (query string) podem ser
+// usados num cadastro, com o código do motivo quando não podem.
+func (u *UserHandler) CheckAvailability(c *fiber.Ctx) error {
+	response := fiber.Map{}
+
+	if username := strings.ToLower(strings.TrimSpace(c.Query("username"))); username != "" {
+		code, err := u.ValidateUsername(c.UserContext(), username, "")
+		if code == "INTERNAL_SERVER_ERROR" {
+			return utils.NewRequestError(code, err)
+		}
+		response["username"] = model.Availability{Available: err == nil, Code: code}
+	}
+
+	if email := strings.ToLower(strings.TrimSpace(c.Query("email"))); email != "" {
+		availability := model.Availability{Available: true}
+		if match, _ := emailRegex.MatchString(email); !match {
+			availability = model.Availability{Code: "INVALID_EMAIL"}
+		} else {
+			existing, err := u.UserRepository.FindByFilter(c.UserContext(), "email", email)
+			if err != nil {
+				return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+			}
+			if existing != nil {
+				availability = model.Availability{Code: "USER_ALREADY_REGISTERED"}
+			}
+		}
+		response["email"] = availability
+	}
+
+	if len(response) == 0 {
+		return utils.NewRequestError("REQUIRED_USERNAME_OR_EMAIL")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// validateNames normaliza e valida primeiro nome (obrigatório) e sobrenome
+// (opcional), cada um com no máximo 20 caracteres.
+func (u *UserHandler) validateNames(firstName, lastName string) (string, string, string, error) {
+	firstName = utils.FormatName(firstName)
+	lastName = utils.FormatName(lastName)
+
+	if code, err := u.ValidateName(firstName); err != nil {
+		return "", "", code, err
+	}
+	if lastName != "" {
+		if code, err := u.ValidateName(lastName); err != nil {
+			return "", "", code, err
+		}
+	}
+
+	if utf8.RuneCountInString(firstName) > 20 || utf8.RuneCountInString(lastName) > 20 {
+		return "", "", "NAME_TOO_LONG", fmt.Errorf("nome muito longo")
+	}
+
+	return firstName, lastName, "", nil
+}
+
 func (u *UserHandler) validateUser(user *model.User) (bool, error) {
 	return false, nil
 }
@@ -110,94 +227,89 @@ func (u *UserHandler) ValidateName(name string) (string, error) {
 	return "", nil
 }
 
-func (u *UserHandler) LoginUser(c *fiber.Ctx) error {
-	var user model.User
+func (u *UserHandler) LoginUser(c *fiber.Ctx) (err error) {
+	// Só conta como tentativa de login o que chega à conferência das
+	// credenciais; corpo inválido ou erro de banco não entram na métrica.
+	credentialsChecked := false
+	defer func() {
+		if credentialsChecked {
+			metrics.RecordLogin(err == nil)
+		}
+	}()
 
-	_, err := utils.ParseBody(c, &user)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   "INVALID_BODY_FORMAT",
-			"message": fmt.Sprintf("failed to parse body: %s", err),
-		})
+	var request model.LoginRequest
+	if err := c.BodyParser(&request); err != nil {
+		return utils.NewRequestError("INVALID_BODY_FORMAT", err)
 	}
 
-	email := strings.ToLower(strings.TrimSpace(user.Email))
-	if email == "" || user.Password == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   "INVALID_CREDENTIALS",
-			"message": "Email e senha são obrigatórios",
-		})
+	email := strings.ToLower(strings.TrimSpace(request.Email))
+	if email == "" || request.Password == "" {
+		return utils.NewRequestError("REQUIRED_LOGIN_CREDENTIALS")
 	}
 
-	dbUser, err := u.UserRepository.FindByFilter("email", email)
+	dbUser, err := u.UserRepository.FindByFilter(c.UserContext(), "email", email)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to query user")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "INTERNAL_SERVER_ERROR",
-			"message": "Erro ao buscar usuário no banco de dados",
-		})
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", fmt.Errorf("failed to query user: %w", err))
 	}
 
+	credentialsChecked = true
 	if dbUser == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error":   "INVALID_CREDENTIALS",
-			"message": "Email ou senha incorretos",
-		})
+		return utils.NewRequestError("INVALID_LOGIN_CREDENTIALS")
+	}
+
+	needsRehash, err := u.validateLoginCredentials(dbUser, request.Password)
+	if err != nil {
+		return utils.NewRequestError("INVALID_LOGIN_CREDENTIALS")
+	}
+
+	if needsRehash {
+		u.rehashPassword(c, dbUser, request.Password)
 	}
 
-	if err := u.validateLoginCredentials(dbUser, user.Password); err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error":   "INVALID_CREDENTIALS",
-			"message": "Email ou senha incorretos",
-		})
+	reactivated := false
+	if dbUser.DeactivatedAt != nil {
+		reactivated, err = u.reactivateIfWithinGracePeriod(c, dbUser.ID, *dbUser.DeactivatedAt)
+		if err != nil {
+			return utils.NewRequestError("INTERNAL_SERVER_ERROR", fmt.Errorf("failed to reactivate user: %w", err))
+		}
+		dbUser.IsActive = reactivated
 	}
 
 	if !dbUser.IsActive {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"status":  fiber.StatusForbidden,
-			"message": "Este usuário está inativo.",
-			"idUser":  dbUser.ID,
-		})
+		return utils.NewRequestError("USER_NOT_ACTIVE").WithUserID(dbUser.ID)
 	}
 
-	existing, err := u.UserAuthenticationHandler.UserAuthenticationTokenRepo.FindTokenByUserID(dbUser.ID)
+	existing, err := u.UserAuthenticationHandler.UserAuthenticationTokenRepo.FindTokenByUserID(c.UserContext(), dbUser.ID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "INTERNAL_SERVER_ERROR",
-			"message": "Falha ao buscar token existente",
-		})
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", fmt.Errorf("failed to find existing token: %w", err))
 	}
 
 	if existing != nil {
-		_ = u.UserAuthenticationHandler.UserAuthenticationTokenRepo.Delete(existing.ID)
+		_ = u.UserAuthenticationHandler.UserAuthenticationTokenRepo.Delete(c.UserContext(), existing.ID)
 	}
 
 	code, err := utils.GenerateCode(6)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "INTERNAL_SERVER_ERROR",
-			"message": "Falha ao gerar código de autenticação",
-		})
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
 	}
 
 	tokenData := u.UserAuthenticationHandler.UserAuthenticationTokenBuilder.CreateUserAuthenticationToken(dbUser.ID, code, 1440)
 
-	tokenID, err := u.UserAuthenticationHandler.UserAuthenticationTokenRepo.Insert(tokenData)
+	tokenID, err := u.UserAuthenticationHandler.UserAuthenticationTokenRepo.Insert(c.UserContext(), tokenData)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "INTERNAL_SERVER_ERROR",
-			"message": "Falha ao armazenar token no banco",
-		})
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
 	}
 	tokenData.ID = tokenID
 
-	jwtToken, err := u.UserAuthenticationHandler.CreateToken(dbUser.ID, "/auth/login")
+	// Um login logo após a desativação não pode cair na revogação dela.
+	var revokedAt time.Time
+	if dbUser.SessionsRevokedAt != nil {
+		revokedAt = *dbUser.SessionsRevokedAt
+	}
+
+	jwtToken, err := u.UserAuthenticationHandler.CreateTokenAfter(dbUser.ID, "/auth/login", revokedAt)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to create JWT token")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "INTERNAL_SERVER_ERROR",
-			"message": "Falha ao gerar token de autenticação",
-		})
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", fmt.Errorf("failed to create JWT token: %w", err))
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -208,291 +320,332 @@ func (u *UserHandler) LoginUser(c *fiber.Ctx) error {
 		"authCode":    tokenData.Code,
 		"idUser":      dbUser.ID,
 		"name":        dbUser.Name,
+		"photoUrl":    dbUser.PhotoUrl,
+		"banner":      dbUser.Banner,
+		"reactivated": reactivated,
 	})
 }
 
-func (u *UserHandler) validateLoginCredentials(user *model.User, password string) error {
-	if err := security.VerifyPasswordMatch(password, user.Password); err != nil {
-		return errors.New("invalid login credentials")
+func (u *UserHandler) validateLoginCredentials(user *model.User, password string) (bool, error) {
+	needsRehash, err := u.PasswordManager.Verify(password, user.Password)
+	if err != nil {
+		return false, errors.New("invalid login credentials")
 	}
-	return nil
+	return needsRehash, nil
 }
 
-func (u UserHandler) verifyUserActive(user *model.User, c *fiber.Ctx) bool {
-	return true
-}
+// rehashPassword regrava o hash com o algoritmo e os parâmetros atuais.
+// Falhas não impedem o login; o hash antigo continua válido.
+func (u *UserHandler) rehashPassword(c *fiber.Ctx, user *model.User, password string) {
+	hash, err := u.PasswordManager.Hash(password)
+	if err != nil {
+		logger.FromCtx(c).Warn().Err(err).Str("userID", user.ID).Msg("failed to rehash password")
+		return
+	}
 
-func (u UserHandler) handleLoginError(c *fiber.Ctx, errMsg string) error {
-	if err := utils.EncodeRequestError(c, errMsg, "/login"); err != nil {
-		return fmt.Errorf("failed to encode request error: %s", err)
+	if err := u.UserRepository.UpdateByID(c.UserContext(), user.ID, map[string]interface{}{"password": hash}); err != nil {
+		logger.FromCtx(c).Warn().Err(err).Str("userID", user.ID).Msg("failed to store rehashed password")
 	}
-	return nil
 }
 
-func (u *UserHandler) EditUser(c *fiber.Ctx) error {
-	var body map[string]interface{}
+func (u UserHandler) verifyUserActive(user *model.User, c *fiber.Ctx) bool {
+	return true
+}
+
+// GetMe devolve o perfil privado do usuário autenticado.
+func (u *UserHandler) GetMe(c *fiber.Ctx) error {
+	userID := middleware.UserID(c)
 
-	if err := c.BodyParser(&body); err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, "failed to parse body")
+	user, err := u.UserRepository.FindByFilter(c.UserContext(), "id", userID)
+	if err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
 	}
-
-	idRaw, ok := body["idUser"]
-	if !ok {
-		return fiber.NewError(fiber.StatusBadRequest, "idUser é obrigatório")
+	if user == nil {
+		return utils.NewRequestError("USER_NOT_FOUND").WithUserID(userID)
 	}
 
-	userID, ok := idRaw.(string)
-	if !ok || userID == "" {
-		return fiber.NewError(fiber.StatusBadRequest, "idUser deve ser uma string válida")
+	settings, err := u.UserAuthenticationHandler.SettingsRepository.FindByUserID(c.UserContext(), userID)
+	if err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
 	}
 
-	delete(body, "idUser")
+	return c.Status(fiber.StatusOK).JSON(model.NewUserPrivateProfile(user, settings))
+}
 
-	if err := u.UserRepository.UpdateByID(userID, body); err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "falha ao atualizar usuário")
+// GetPublicProfile devolve a projeção pública de um usuário ativo.
+func (u *UserHandler) GetPublicProfile(c *fiber.Ctx) error {
+	username := c.Params("username")
+	if username == "" {
+		return utils.NewRequestError("USER_NOT_FOUND")
 	}
 
-	return c.SendStatus(fiber.StatusOK)
-}
+	user, err := u.UserRepository.FindByFilter(c.UserContext(), "username", username)
+	if err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+	if user == nil || !user.IsActive {
+		return utils.NewRequestError("USER_NOT_FOUND")
+	}
 
-type CloudinaryResponse struct {
-	SecureURL string `json:"secure_url"`
-	PublicID  string `json:"public_id"`
-	Error     struct {
-		Message string `json:"message"`
-	} `json:"error"`
+	return c.Status(fiber.StatusOK).JSON(model.NewUserPublicProfile(user))
 }
 
-func (h *UserHandler) UploadUserImage(c *fiber.Ctx) error {
-	userIDStr := c.FormValue("idUser")
-	if userIDStr == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "idUser is required",
-		})
+// UpdateMe aplica uma atualização parcial no perfil do usuário autenticado.
+// Só os campos de model.UpdateProfileRequest são aceitos; qualquer outro
+// campo no corpo é rejeitado.
+func (u *UserHandler) UpdateMe(c *fiber.Ctx) error {
+	userID := middleware.UserID(c)
+
+	var body model.UpdateProfileRequest
+	decoder := json.NewDecoder(bytes.NewReader(c.Body()))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&body); err != nil {
+		return utils.NewRequestError("INVALID_BODY_FORMAT", err)
 	}
 
-	user, err := h.UserRepository.FindByFilter("id", userIDStr)
+	user, err := u.UserRepository.FindByFilter(c.UserContext(), "id", userID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to fetch user",
-		})
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+	if user == nil {
+		return utils.NewRequestError("USER_NOT_FOUND").WithUserID(userID)
 	}
 
-	if user != nil && user.PhotoUrl != "" {
-		publicID := utils.ExtractPublicID(user.PhotoUrl)
-		if publicID != "" {
-			if err := deleteCloudinaryImage(publicID); err != nil {
-				log.Printf("Aviso: não foi possível deletar imagem anterior - %v", err)
-			}
+	updateData := map[string]interface{}{}
+
+	if body.Name != nil || body.FirstName != nil || body.LastName != nil {
+		firstName, lastName := user.FirstName, user.LastName
+		if body.Name != nil {
+			firstName, lastName = utils.SplitName(*body.Name)
+		}
+		if body.FirstName != nil {
+			firstName = *body.FirstName
+		}
+		if body.LastName != nil {
+			lastName = *body.LastName
+		}
+
+		firstName, lastName, code, err := u.validateNames(firstName, lastName)
+		if err != nil {
+			return utils.NewRequestError(code, err)
 		}
+		updateData["first_name"] = firstName
+		updateData["last_name"] = lastName
+		updateData["name"] = utils.JoinName(firstName, lastName)
 	}
 
-	fileHeader, err := c.FormFile("image")
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "no image provided",
-		})
+	if body.Username != nil {
+		username := strings.ToLower(strings.TrimSpace(*body.Username))
+		if code, err := u.ValidateUsername(c.UserContext(), username, userID); err != nil {
+			return utils.NewRequestError(code, err)
+		}
+		updateData["username"] = username
 	}
 
-	file, err := fileHeader.Open()
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to open image",
-		})
+	if body.PhotoUrl != nil {
+		if *body.PhotoUrl != "" && !utils.IsAvatarPreset(*body.PhotoUrl) {
+			return utils.NewRequestError("INVALID_AVATAR_PRESET")
+		}
+		updateData["photo_url"] = *body.PhotoUrl
 	}
-	defer file.Close()
 
-	imageBytes, err := io.ReadAll(file)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to read image data",
-		})
+	if body.Banner != nil {
+		if *body.Banner != "" && !utils.IsBannerPreset(*body.Banner) {
+			return utils.NewRequestError("INVALID_BANNER_PRESET")
+		}
+		updateData["banner"] = *body.Banner
 	}
 
-	if !utils.IsValidImageType(imageBytes) {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid image format. Only JPEG/PNG allowed",
-		})
+	var settings *model.Settings
+	if body.Currency != nil {
+		code := currency.Normalize(*body.Currency)
+		if code == "" {
+			return utils.NewRequestError("INVALID_CURRENCY")
+		}
+		settings = &model.Settings{UserID: userID, Currency: code}
 	}
 
-	photoURL, err := utils.UploadPhotoToCloudinary(imageBytes)
-	if err != nil {
-		log.Printf("Erro no upload para Cloudinary: %v", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to upload image to cloud storage",
-		})
+	if len(updateData) == 0 && settings == nil {
+		return utils.NewRequestError("EMPTY_USER")
 	}
 
-	err = h.UserRepository.UpdateByID(userIDStr, map[string]interface{}{
-		"photo_url": photoURL,
-	})
+	err = u.UserRepository.UpdateProfile(c.UserContext(), userID, updateData, settings)
+	if errors.Is(err, repository.ErrUsernameTaken) {
+		return utils.NewRequestError("USERNAME_ALREADY_TAKEN")
+	}
 	if err != nil {
-		log.Printf("Erro ao atualizar usuário: %v", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to update user profile",
-		})
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "Profile photo updated successfully",
-	})
+	if body.PhotoUrl != nil && *body.PhotoUrl != user.PhotoUrl {
+		u.deleteStoredImage(c, user.PhotoUrl, imaging.Avatar)
+	}
+	if body.Banner != nil && *body.Banner != user.Banner {
+		u.deleteStoredImage(c, user.Banner, imaging.Banner)
+	}
+
+	return u.GetMe(c)
 }
 
-func deleteCloudinaryImage(publicID string) error {
-	cloudName := os.Getenv("CLOUDINARY_CLOUD_NAME")
-	apiKey := os.Getenv("CLOUDINARY_API_KEY")
-	apiSecret := os.Getenv("CLOUDINARY_API_SECRET")
+// ValidateUsername aplica as regras de username: 3 a 30 caracteres entre
+// letras minúsculas, números, "." e "_", e não estar em uso por outro usuário.
+func (u *UserHandler) ValidateUsername(ctx context.Context, username, userID string) (string, error) {
+	if username == "" {
+		return "REQUIRED_USERNAME", fmt.Errorf("username é obrigatório")
+	}
+
+	if !usernameRegex.MatchString(username) {
+		return "INVALID_USERNAME", fmt.Errorf("username contém caracteres inválidos")
+	}
 
-	timestamp := fmt.Sprintf("%d", time.Now().Unix())
-	signatureBase := fmt.Sprintf("public_id=%s&timestamp=%s%s", publicID, timestamp, apiSecret)
+	existing, err := u.UserRepository.FindByFilter(ctx, "username", username)
+	if err != nil {
+		return "INTERNAL_SERVER_ERROR", err
+	}
+	if existing != nil && existing.ID != userID {
+		return "USERNAME_ALREADY_TAKEN", fmt.Errorf("username já está em uso")
+	}
 
-	h := sha1.New()
-	h.Write([]byte(signatureBase))
-	signature := hex.EncodeToString(h.Sum(nil))
+	return "", nil
+}
 
-	form := url.Values{}
-	form.Add("public_id", publicID)
-	form.Add("timestamp", timestamp)
-	form.Add("api_key", apiKey)
-	form.Add("signature", signature)
+var usernameRegex = regexp.MustCompile(`^[a-z0-9._]{3,30}$`)
 
-	deleteURL := fmt.Sprintf("https://api.cloudinary.com/v1_1/%s/image/destroy", cloudName)
-	req, err := http.NewRequest("POST", deleteURL, strings.NewReader(form.Encode()))
+// UploadUserImage troca a foto do usuário autenticado (multipart: image).
+func (h *UserHandler) UploadUserImage(c *fiber.Ctx) error {
+	userID := middleware.UserID(c)
+
+	user, err := h.UserRepository.FindByFilter(c.UserContext(), "id", userID)
 	if err != nil {
-		return err
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+	if user == nil {
+		return utils.NewRequestError("USER_NOT_FOUND").WithUserID(userID)
 	}
-	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	fileHeader, err := c.FormFile("image")
 	if err != nil {
-		return err
+		return utils.NewRequestError("INVALID_PHOTO", err)
 	}
-	defer resp.Body.Close()
 
-	var res map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+	variants, err := h.storeImage(c, fileHeader, imaging.Avatar, userID)
+	if err != nil {
 		return err
 	}
-	if res["result"] != "ok" {
-		return fmt.Errorf("erro ao deletar imagem do cloudinary: %v", res)
+	photoURL := variants[imaging.Avatar.Variants[0].Name]
+
+	err = h.UserRepository.UpdateByID(c.UserContext(), userID, map[string]interface{}{
+		"photo_url": photoURL,
+	})
+	if err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", fmt.Errorf("failed to update user profile: %w", err))
 	}
 
-	return nil
+	h.deleteStoredImage(c, user.PhotoUrl, imaging.Avatar)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":  "Profile photo updated successfully",
+		"photoUrl": photoURL,
+		"variants": variants,
+	})
 }
 
+// UploadUserBanner troca o banner do usuário autenticado: um preset em path
+// ou uma imagem enviada (multipart: banner).
 func (h *UserHandler) UploadUserBanner(c *fiber.Ctx) error {
-	cloudName := os.Getenv("CLOUDINARY_CLOUD_NAME")
-	apiKey := os.Getenv("CLOUDINARY_API_KEY")
-	apiSecret := os.Getenv("CLOUDINARY_API_SECRET")
-
-	userIDStr := c.FormValue("idUser")
-	if userIDStr == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "idUser is required",
-		})
-	}
-
-	path := c.FormValue("path")
-	if path != "" {
-		err := h.UserRepository.UpdateByID(userIDStr, map[string]interface{}{
-			"banner": path,
-		})
+	userID := middleware.UserID(c)
+
+	user, err := h.UserRepository.FindByFilter(c.UserContext(), "id", userID)
+	if err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+	if user == nil {
+		return utils.NewRequestError("USER_NOT_FOUND").WithUserID(userID)
+	}
+
+	bannerURL := c.FormValue("path")
+	var variants map[string]string
+
+	if bannerURL != "" {
+		if !utils.IsBannerPreset(bannerURL) {
+			return utils.NewRequestError("INVALID_BANNER_PRESET")
+		}
+	} else {
+		fileHeader, err := c.FormFile("banner")
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "failed to update banner path",
-			})
+			return utils.NewRequestError("INVALID_BANNER", err)
 		}
-		return c.Status(fiber.StatusOK).JSON(fiber.Map{
-			"message": "banner atualizado com path local",
-		})
-	}
 
-	fileHeader, err := c.FormFile("banner")
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "no banner image provided",
-		})
+		variants, err = h.storeImage(c, fileHeader, imaging.Banner, userID)
+		if err != nil {
+			return err
+		}
+		bannerURL = variants[imaging.Banner.Variants[0].Name]
 	}
 
-	file, err := fileHeader.Open()
+	err = h.UserRepository.UpdateByID(c.UserContext(), userID, map[string]interface{}{
+		"banner": bannerURL,
+	})
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to open banner",
-		})
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", fmt.Errorf("failed to update user banner: %w", err))
 	}
-	defer file.Close()
 
-	timestamp := fmt.Sprintf("%d", time.Now().Unix())
-	signatureBase := "timestamp=" + timestamp + apiSecret
-
-	hSha := sha1.New()
-	hSha.Write([]byte(signatureBase))
-	signature := hex.EncodeToString(hSha.Sum(nil))
-
-	var body bytes.Buffer
-	writer := multipart.NewWriter(&body)
+	if user.Banner != bannerURL {
+		h.deleteStoredImage(c, user.Banner, imaging.Banner)
+	}
 
-	_ = writer.WriteField("api_key", apiKey)
-	_ = writer.WriteField("timestamp", timestamp)
-	_ = writer.WriteField("signature", signature)
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":  "banner atualizado com sucesso",
+		"banner":   bannerURL,
+		"variants": variants,
+	})
+}
 
-	part, err := writer.CreateFormFile("file", fileHeader.Filename)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "error preparing upload",
-		})
+// storeImage processa o upload conforme o perfil e grava todos os variants
+// sob a mesma pasta ("<folder>/<idUser>-<timestamp>/<variant>"). A pasta é
+// nova a cada envio, assim CDNs e navegadores nunca servem a imagem antiga.
+// Retorna as URLs indexadas pelo nome do variant.
+func (h *UserHandler) storeImage(c *fiber.Ctx, fileHeader *multipart.FileHeader, profile imaging.Profile, userID string) (map[string]string, error) {
+	if fileHeader.Size > profile.MaxBytes {
+		return nil, utils.NewRequestError("IMAGE_TOO_LARGE")
 	}
 
-	_, err = io.Copy(part, file)
+	file, err := fileHeader.Open()
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "error copying banner file",
-		})
+		return nil, utils.NewRequestError("INTERNAL_SERVER_ERROR", fmt.Errorf("failed to open image: %w", err))
 	}
-	writer.Close()
+	defer file.Close()
 
-	uploadURL := fmt.Sprintf("https://api.cloudinary.com/v1_1/%s/image/upload", cloudName)
-	req, err := http.NewRequest("POST", uploadURL, &body)
+	data, err := io.ReadAll(io.LimitReader(file, profile.MaxBytes+1))
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "error creating request",
-		})
+		return nil, utils.NewRequestError("INTERNAL_SERVER_ERROR", fmt.Errorf("failed to read image data: %w", err))
 	}
-	req.Header.Set("Content-Type", writer.FormDataContentType())
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	images, err := imaging.Process(data, profile)
+	if errors.Is(err, imaging.ErrTooLarge) {
+		return nil, utils.NewRequestError("IMAGE_TOO_LARGE")
+	}
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "error sending banner",
-		})
+		return nil, utils.NewRequestError("INVALID_IMAGE_FORMAT", err)
 	}
-	defer resp.Body.Close()
 
-	var cloudResp CloudinaryResponse
-	if err := json.NewDecoder(resp.Body).Decode(&cloudResp); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "error decoding cloudinary response",
-		})
+	folder := fmt.Sprintf("%s/%s-%d", profile.Folder, userID, time.Now().UnixNano())
+	variants := make(map[string]string, len(images))
+	for _, img := range images {
+		url, err := h.Storage.Put(c.UserContext(), folder+"/"+img.Variant.Name, bytes.NewReader(img.Data), img.ContentType)
+		if err != nil {
+			return nil, utils.NewRequestError("IMAGE_UPLOAD_FAILED", err)
+		}
+		variants[img.Variant.Name] = url
 	}
 
-	if cloudResp.SecureURL == "" {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "cloudinary error: " + cloudResp.Error.Message,
-		})
-	}
+	return variants, nil
+}
 
-	err = h.UserRepository.UpdateByID(userIDStr, map[string]interface{}{
-		"banner": cloudResp.SecureURL,
-	})
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to update user banner",
-		})
+// deleteStoredImage remove a imagem substituída e seus variants. Falhas só
+// são registradas: a imagem nova já foi salva.
+func (h *UserHandler) deleteStoredImage(c *fiber.Ctx, url string, profile imaging.Profile) {
+	if err := storage.DeleteImage(c.UserContext(), h.Storage, url, profile.VariantNames()); err != nil {
+		logger.FromCtx(c).Warn().Err(err).Str("url", url).Msg("não foi possível deletar imagem anterior")
 	}
-
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "banner enviado com sucesso",
-	})
 }
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"nexa/internal/currency"
+	"nexa/internal/handler/middleware"
+	"nexa/internal/model"
+	"nexa/internal/repository"
+	"nexa/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	monthLayout         = "2006-01"
+	maxWalletNameLength = 100
+)
+
+type WalletHandler struct {
+	Wallets      *repository.WalletRepository
+	Transactions *repository.TransactionRepository
+	Budgets      *repository.BudgetRepository
+	Settings     *repository.SettingsRepository
+}
+
+func NewWalletHandler(db *pgxpool.Pool) *WalletHandler {
+	return &WalletHandler{
+		Wallets:      repository.NewWalletRepository(db),
+		Transactions: repository.NewTransactionRepository(db),
+		Budgets:      repository.NewBudgetRepository(db),
+		Settings:     repository.NewSettingsRepository(db),
+	}
+}
+
+type walletRequest struct {
+	Name     string `json:"name"`
+	Currency string `json:"currency"`
+}
+
+// CreateWallet cria uma carteira. currency (ISO 4217) é fixa depois de
+// criada; ausente, vale a moeda padrão do usuário.
+func (h *WalletHandler) CreateWallet(c *fiber.Ctx) error {
+	userID := middleware.UserID(c)
+
+	var request walletRequest
+	if err := c.BodyParser(&request); err != nil {
+		return utils.NewRequestError("INVALID_BODY_FORMAT", err)
+	}
+
+	wallet := &model.Wallet{UserID: userID, Name: strings.TrimSpace(request.Name)}
+	if wallet.Name == "" || len(wallet.Name) > maxWalletNameLength {
+		return utils.NewRequestError("INVALID_WALLET_NAME")
+	}
+
+	if request.Currency != "" {
+		if wallet.Currency = currency.Normalize(request.Currency); wallet.Currency == "" {
+			return utils.NewRequestError("INVALID_CURRENCY")
+		}
+	} else {
+		code, err := userCurrency(c, h.Settings, userID)
+		if err != nil {
+			return err
+		}
+		wallet.Currency = code
+	}
+
+	if err := h.Wallets.Create(c.UserContext(), wallet); err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(wallet)
+}
+
+// ListWallets lista as carteiras do usuário com o saldo na moeda de cada uma.
+func (h *WalletHandler) ListWallets(c *fiber.Ctx) error {
+	wallets, err := h.Wallets.FindByUserID(c.UserContext(), middleware.UserID(c))
+	if err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+
+	return c.JSON(wallets)
+}
+
+// GetMonthFlow devolve receitas e despesas da carteira no mês (query month,
+// AAAA-MM; padrão: mês corrente). Transferências vêm separadas.
+func (h *WalletHandler) GetMonthFlow(c *fiber.Ctx) error {
+	wallet, month, err := h.walletMonth(c)
+	if err != nil {
+		return err
+	}
+
+	flow, err := h.Transactions.MonthFlow(c.UserContext(), wallet.ID, month)
+	if err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+
+	return c.JSON(flow)
+}
+
+// ListBudgets devolve os orçamentos do mês com o gasto calculado.
+func (h *WalletHandler) ListBudgets(c *fiber.Ctx) error {
+	wallet, month, err := h.walletMonth(c)
+	if err != nil {
+		return err
+	}
+
+	budgets, err := h.Budgets.FindByMonth(c.UserContext(), wallet.ID, month)
+	if err != nil {
+		return utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+
+	return c.JSON(budgets)
+}
+
+// userCurrency é a moeda padrão do usuário (tb_settings), ou BRL.
+func userCurrency(c *fiber.Ctx, settings *repository.SettingsRepository, userID string) (string, error) {
+	found, err := settings.FindByUserID(c.UserContext(), userID)
+	if err != nil {
+		return "", utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+	if found == nil || found.Currency == "" {
+		return currency.Default, nil
+	}
+
+	return found.Currency, nil
+}
+
+// walletMonth carrega :walletId do usuário e lê o mês da query.
+func (h *WalletHandler) walletMonth(c *fiber.Ctx) (*model.Wallet, time.Time, error) {
+	userID := middleware.UserID(c)
+
+	month := time.Now()
+	if value := c.Query("month"); value != "" {
+		parsed, err := time.Parse(monthLayout, value)
+		if err != nil {
+			return nil, month, utils.NewRequestError("INVALID_MONTH", err)
+		}
+		month = parsed
+	}
+
+	wallet, err := h.Wallets.FindByID(c.UserContext(), c.Params("walletId"), userID)
+	if err != nil {
+		return nil, month, utils.NewRequestError("INTERNAL_SERVER_ERROR", err)
+	}
+	if wallet == nil {
+		return nil, month, utils.NewRequestError("WALLET_NOT_FOUND").WithUserID(userID)
+	}
+
+	return wallet, month, nil
+}
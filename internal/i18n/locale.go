@@ -0,0 +1,103 @@
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	PtBR = "pt-BR"
+	EnUS = "en-US"
+
+	DefaultLocale = PtBR
+
+	localsKey = "locale"
+)
+
+var supportedLocales = map[string]string{
+	"pt": PtBR,
+	"en": EnUS,
+}
+
+// Normalize converte qualquer tag de idioma para um locale suportado
+// ("en-GB" -> "en-US", "pt" -> "pt-BR"). Retorna "" se não houver suporte.
+func Normalize(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	base, _, _ := strings.Cut(tag, "-")
+	base, _, _ = strings.Cut(base, "_")
+
+	return supportedLocales[base]
+}
+
+// Negotiate escolhe o melhor locale suportado de um cabeçalho Accept-Language,
+// respeitando os pesos q. Sem correspondência, retorna DefaultLocale.
+func Negotiate(acceptLanguage string) string {
+	type candidate struct {
+		locale string
+		q      float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+
+		if locale := Normalize(tag); locale != "" && q > 0 {
+			candidates = append(candidates, candidate{locale: locale, q: q})
+		}
+	}
+
+	if len(candidates) == 0 {
+		return DefaultLocale
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+
+	return candidates[0].locale
+}
+
+// Middleware resolve o locale da requisição a partir do Accept-Language.
+// Em rotas autenticadas, o idioma das configurações do usuário o substitui
+// (ver SetLocale).
+func Middleware(c *fiber.Ctx) error {
+	c.Locals(localsKey, Negotiate(c.Get(fiber.HeaderAcceptLanguage)))
+	return c.Next()
+}
+
+// SetLocale troca o locale da requisição; tags sem suporte são ignoradas e o
+// locale negociado pelo Accept-Language continua valendo.
+func SetLocale(c *fiber.Ctx, tag string) {
+	if locale := Normalize(tag); locale != "" {
+		c.Locals(localsKey, locale)
+	}
+}
+
+func FromCtx(c *fiber.Ctx) string {
+	if locale, ok := c.Locals(localsKey).(string); ok && locale != "" {
+		return locale
+	}
+
+	return Negotiate(c.Get(fiber.HeaderAcceptLanguage))
+}
+
+// EmailTemplatePath retorna o caminho do template de e-mail no locale pedido.
+func EmailTemplatePath(locale, name string) string {
+	locale = Normalize(locale)
+	if locale == "" {
+		locale = DefaultLocale
+	}
+
+	return "assets/email/" + locale + "/" + name
+}
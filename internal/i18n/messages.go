@@ -0,0 +1,452 @@
+package i18n
+
+// messages é o catálogo de textos por código e locale. Os códigos de erro
+// são as mesmas chaves de utils.HTTPErrors.
+var messages = map[string]map[string]string{
+	"INVALID_ID_USER": {
+		PtBR: "Formato de idUser inválido.",
+		EnUS: "Invalid idUser format.",
+	},
+	"REQUIRED_EMAIL": {
+		PtBR: "O e-mail é obrigatório.",
+		EnUS: "Email is required.",
+	},
+	"USER_ALREADY_IN_WAIT_LIST": {
+		PtBR: "E-mail já cadastrado na lista de espera.",
+		EnUS: "Email already registered on the wait list.",
+	},
+	"USER_NOT_ALLOWED": {
+		PtBR: "Usuário ainda está na lista de espera.",
+		EnUS: "User is still on the wait list.",
+	},
+	"USER_ALREADY_REGISTERED": {
+		PtBR: "E-mail já está em uso.",
+		EnUS: "Email is already in use.",
+	},
+	"USER_NOT_ACTIVE": {
+		PtBR: "Este usuário está inativo.",
+		EnUS: "This user is not active.",
+	},
+	"USER_ALREADY_ACTIVE": {
+		PtBR: "Usuário já está ativo.",
+		EnUS: "User is already active.",
+	},
+	"INVALID_PHOTO": {
+		PtBR: "Nenhuma imagem enviada.",
+		EnUS: "No image provided.",
+	},
+	"INVALID_LOGIN_CREDENTIALS": {
+		PtBR: "E-mail ou senha incorretos.",
+		EnUS: "Incorrect email or password.",
+	},
+	"REQUIRED_LOGIN_CREDENTIALS": {
+		PtBR: "E-mail e senha são obrigatórios.",
+		EnUS: "Email and password are required.",
+	},
+	"INVALID_USER_ID": {
+		PtBR: "Dados de entrada inválidos. Verifique o formato e tente novamente.",
+		EnUS: "Invalid input data. Check the format and try again.",
+	},
+	"INTERNAL_SERVER_ERROR": {
+		PtBR: "Ocorreu um erro no servidor.",
+		EnUS: "An internal server error occurred.",
+	},
+	"USER_NOT_FOUND": {
+		PtBR: "Usuário não encontrado.",
+		EnUS: "User not found.",
+	},
+	"INVALID_USER_AUTHENTICATION_TOKEN": {
+		PtBR: "Código inválido.",
+		EnUS: "Invalid code.",
+	},
+	"EXPIRED_AUTHENTICATION_TOKEN": {
+		PtBR: "Código de autenticação expirado. Um novo código foi enviado.",
+		EnUS: "Authentication code expired. A new code has been sent.",
+	},
+	"EMPTY_USER": {
+		PtBR: "Os dados do usuário não podem estar vazios.",
+		EnUS: "Empty user object is not allowed.",
+	},
+	"FUTURE_DATE": {
+		PtBR: "Datas futuras não são permitidas.",
+		EnUS: "A future date is not allowed.",
+	},
+	"MORE_THAN_500_CHARS": {
+		PtBR: "O nome deve conter no máximo 500 caracteres.",
+		EnUS: "Name must have at most 500 characters.",
+	},
+	"INVALID_AGE": {
+		PtBR: "Idade mínima de 18 anos.",
+		EnUS: "Minimum age is 18 years.",
+	},
+	"INVALID_DATE_FORMAT": {
+		PtBR: "Formato de data inválido.",
+		EnUS: "Invalid date format.",
+	},
+	"INVALID_NAME": {
+		PtBR: "Nome não aceita números ou caracteres especiais.",
+		EnUS: "Name cannot contain numbers or special characters.",
+	},
+	"NAME_TOO_LONG": {
+		PtBR: "O nome não deve conter mais de 20 caracteres.",
+		EnUS: "Name must not exceed 20 characters.",
+	},
+	"REQUIRED_NAME": {
+		PtBR: "O nome é obrigatório.",
+		EnUS: "Name is required.",
+	},
+	"INVALID_PASSWORD": {
+		PtBR: "A senha deve possuir no mínimo 6 caracteres, contendo uma letra maiúscula, um número e um caractere especial.",
+		EnUS: "Password must have at least 6 characters, including an uppercase letter, a number and a special character.",
+	},
+	"BREACHED_PASSWORD": {
+		PtBR: "Esta senha aparece em vazamentos de dados conhecidos. Escolha outra senha.",
+		EnUS: "This password appears in known data breaches. Choose a different password.",
+	},
+	"PASSWORD_CONTAINS_PERSONAL_INFO": {
+		PtBR: "A senha não pode conter seu nome, nome de usuário ou e-mail.",
+		EnUS: "Password must not contain your name, username or email.",
+	},
+	"LOW_ENTROPY_PASSWORD": {
+		PtBR: "A senha é previsível demais: evite repetições, sequências e padrões de teclado.",
+		EnUS: "Password is too predictable: avoid repeated characters, sequences and keyboard patterns.",
+	},
+	"EMAIL_ALEADY_REGISTERED": {
+		PtBR: "E-mail já está em uso.",
+		EnUS: "Email is already in use.",
+	},
+	"INVALID_EMAIL": {
+		PtBR: "E-mail inválido.",
+		EnUS: "Invalid email.",
+	},
+	"INVALID_BODY_FORMAT": {
+		PtBR: "Formato de JSON inválido.",
+		EnUS: "Invalid JSON format.",
+	},
+	"REQUIRED_AUTHENTICATION_CODE": {
+		PtBR: "idUser e código são obrigatórios.",
+		EnUS: "idUser and code are required.",
+	},
+	"INVALID_IMAGE_FORMAT": {
+		PtBR: "Formato de imagem inválido. Apenas JPEG, PNG e WebP são permitidos.",
+		EnUS: "Invalid image format. Only JPEG, PNG and WebP are allowed.",
+	},
+	"REQUIRED_USERNAME": {
+		PtBR: "O nome de usuário é obrigatório.",
+		EnUS: "Username is required.",
+	},
+	"INVALID_USERNAME": {
+		PtBR: "O nome de usuário deve ter de 3 a 30 caracteres entre letras minúsculas, números, \".\" e \"_\".",
+		EnUS: "Username must have 3 to 30 characters among lowercase letters, numbers, \".\" and \"_\".",
+	},
+	"USERNAME_ALREADY_TAKEN": {
+		PtBR: "Nome de usuário já está em uso.",
+		EnUS: "Username is already taken.",
+	},
+	"REQUIRED_PASSWORD": {
+		PtBR: "A senha é obrigatória.",
+		EnUS: "Password is required.",
+	},
+	"SAME_EMAIL": {
+		PtBR: "O novo e-mail é igual ao atual.",
+		EnUS: "The new email is the same as the current one.",
+	},
+	"REQUIRED_CODE": {
+		PtBR: "O código é obrigatório.",
+		EnUS: "Code is required.",
+	},
+	"NO_PENDING_EMAIL_CHANGE": {
+		PtBR: "Não há alteração de e-mail pendente.",
+		EnUS: "There is no pending email change.",
+	},
+	"EXPIRED_EMAIL_CHANGE_CODE": {
+		PtBR: "Código expirado ou bloqueado. Solicite a alteração de e-mail novamente.",
+		EnUS: "Code expired or locked. Request the email change again.",
+	},
+	"REQUIRED_USERNAME_OR_EMAIL": {
+		PtBR: "Informe username ou email para verificar a disponibilidade.",
+		EnUS: "Provide username or email to check availability.",
+	},
+	"INVALID_AVATAR_PRESET": {
+		PtBR: "Foto inválida. Escolha uma das fotos disponíveis ou envie uma imagem.",
+		EnUS: "Invalid photo. Choose one of the available photos or upload an image.",
+	},
+	"INVALID_BANNER_PRESET": {
+		PtBR: "Banner inválido. Escolha um dos banners disponíveis.",
+		EnUS: "Invalid banner. Choose one of the available banners.",
+	},
+	"IMAGE_TOO_LARGE": {
+		PtBR: "A imagem excede o tamanho máximo permitido (5 MB para foto, 8 MB para banner, até 25 megapixels).",
+		EnUS: "The image exceeds the maximum size (5 MB for photos, 8 MB for banners, up to 25 megapixels).",
+	},
+	"INVALID_BANNER": {
+		PtBR: "Nenhuma imagem de banner enviada.",
+		EnUS: "No banner image provided.",
+	},
+	"IMAGE_UPLOAD_FAILED": {
+		PtBR: "Falha ao enviar a imagem para o armazenamento.",
+		EnUS: "Failed to upload the image to storage.",
+	},
+	"DATA_EXPORT_NOT_FOUND": {
+		PtBR: "Exportação de dados não encontrada.",
+		EnUS: "Data export not found.",
+	},
+	"REQUIRED_WALLET": {
+		PtBR: "Informe a carteira de destino.",
+		EnUS: "Provide the target wallet.",
+	},
+	"WALLET_NOT_FOUND": {
+		PtBR: "Carteira não encontrada.",
+		EnUS: "Wallet not found.",
+	},
+	"INVALID_BANK": {
+		PtBR: "Nome do banco inválido (máximo de 50 caracteres).",
+		EnUS: "Invalid bank name (up to 50 characters).",
+	},
+	"REQUIRED_STATEMENT_FILE": {
+		PtBR: "Envie o arquivo do extrato.",
+		EnUS: "Upload the statement file.",
+	},
+	"STATEMENT_FILE_TOO_LARGE": {
+		PtBR: "O extrato excede o tamanho máximo de 5 MB.",
+		EnUS: "The statement exceeds the 5 MB limit.",
+	},
+	"UNSUPPORTED_STATEMENT_FORMAT": {
+		PtBR: "Formato de extrato não suportado. Envie um arquivo CSV, OFX ou QIF.",
+		EnUS: "Unsupported statement format. Upload a CSV, OFX or QIF file.",
+	},
+	"INVALID_STATEMENT_FILE": {
+		PtBR: "Não foi possível ler o extrato.",
+		EnUS: "The statement could not be read.",
+	},
+	"INVALID_IMPORT_MAPPING": {
+		PtBR: "Mapeamento de colunas inválido.",
+		EnUS: "Invalid column mapping.",
+	},
+	"STATEMENT_IMPORT_NOT_FOUND": {
+		PtBR: "Importação não encontrada.",
+		EnUS: "Import not found.",
+	},
+	"NO_LEDGER_BALANCE": {
+		PtBR: "O extrato não informa o saldo, então não é possível conciliar a carteira.",
+		EnUS: "The statement has no balance, so the wallet cannot be reconciled.",
+	},
+	"IMPORT_ALREADY_COMMITTED": {
+		PtBR: "Esta importação já foi concluída.",
+		EnUS: "This import has already been completed.",
+	},
+	"INVALID_EXPORT_FORMAT": {
+		PtBR: "Formato de exportação inválido. Use csv, ofx ou xlsx.",
+		EnUS: "Invalid export format. Use csv, ofx or xlsx.",
+	},
+	"INVALID_DATE_RANGE": {
+		PtBR: "A data final deve ser igual ou posterior à inicial.",
+		EnUS: "The end date must be on or after the start date.",
+	},
+	"INVALID_CATEGORY": {
+		PtBR: "Categoria inválida.",
+		EnUS: "Invalid category.",
+	},
+	"INVALID_TRANSACTION_TYPE": {
+		PtBR: "Tipo de lançamento inválido.",
+		EnUS: "Invalid transaction type.",
+	},
+	"INVALID_AMOUNT": {
+		PtBR: "O valor deve ser maior que zero.",
+		EnUS: "The amount must be greater than zero.",
+	},
+	"INVALID_PAYMENT_METHOD": {
+		PtBR: "A forma de pagamento deve ter no máximo 30 caracteres.",
+		EnUS: "The payment method must have at most 30 characters.",
+	},
+	"INVALID_RECURRENCE": {
+		PtBR: "Regra de recorrência inválida. Confira frequência, intervalo, dia do mês, ajuste de dia útil e datas.",
+		EnUS: "Invalid recurrence rule. Check frequency, interval, day of month, business-day adjustment and dates.",
+	},
+	"RECURRING_TRANSACTION_NOT_FOUND": {
+		PtBR: "Lançamento recorrente não encontrado.",
+		EnUS: "Recurring transaction not found.",
+	},
+	"OCCURRENCE_NOT_FOUND": {
+		PtBR: "A data não é uma ocorrência deste lançamento recorrente.",
+		EnUS: "The date is not an occurrence of this recurring transaction.",
+	},
+	"SAME_WALLET_TRANSFER": {
+		PtBR: "A carteira de destino deve ser diferente da de origem.",
+		EnUS: "The target wallet must differ from the source wallet.",
+	},
+	"TRANSFER_NOT_FOUND": {
+		PtBR: "Transferência não encontrada.",
+		EnUS: "Transfer not found.",
+	},
+	"INVALID_MONTH": {
+		PtBR: "Mês inválido. Use o formato AAAA-MM.",
+		EnUS: "Invalid month. Use the YYYY-MM format.",
+	},
+	"INVALID_WALLET_NAME": {
+		PtBR: "Nome da carteira inválido. Use de 1 a 100 caracteres.",
+		EnUS: "Invalid wallet name. Use 1 to 100 characters.",
+	},
+	"INVALID_CURRENCY": {
+		PtBR: "Moeda inválida ou não suportada. Use um código ISO 4217 com centavos, como BRL ou USD.",
+		EnUS: "Invalid or unsupported currency. Use an ISO 4217 code with two decimal places, such as BRL or USD.",
+	},
+	"INVALID_EXCHANGE_RATE": {
+		PtBR: "Cotação inválida. Informe um valor positivo.",
+		EnUS: "Invalid exchange rate. Provide a positive value.",
+	},
+	"EXCHANGE_RATE_NOT_FOUND": {
+		PtBR: "Não há cotação cadastrada para essa moeda na data.",
+		EnUS: "No exchange rate is available for this currency on that date.",
+	},
+	"TOO_MANY_EXCHANGE_RATES": {
+		PtBR: "Envie no máximo 10000 cotações por vez.",
+		EnUS: "Send at most 10000 exchange rates at a time.",
+	},
+	"REQUIRED_RATES_FILE": {
+		PtBR: "Envie o arquivo de cotações.",
+		EnUS: "Send the exchange rates file.",
+	},
+	"RATES_FILE_TOO_LARGE": {
+		PtBR: "O arquivo de cotações deve ter no máximo 2 MB.",
+		EnUS: "The exchange rates file must be at most 2 MB.",
+	},
+	"INVALID_RATES_FILE": {
+		PtBR: "Arquivo de cotações inválido. Use as colunas date, base, quote e rate.",
+		EnUS: "Invalid exchange rates file. Use the date, base, quote and rate columns.",
+	},
+	"UNAUTHORIZED": {
+		PtBR: "Token não fornecido ou inválido.",
+		EnUS: "Missing or invalid token.",
+	},
+	"INVALID_TOKEN": {
+		PtBR: "Acesso negado. Token inválido.",
+		EnUS: "Access denied. Invalid token.",
+	},
+	"FORBIDDEN_USER": {
+		PtBR: "Acesso negado. idUser inconsistente.",
+		EnUS: "Access denied. Inconsistent idUser.",
+	},
+	"FORBIDDEN_ADMIN": {
+		PtBR: "Acesso negado. Chave administrativa ausente ou inválida.",
+		EnUS: "Access denied. Missing or invalid admin key.",
+	},
+
+	"EMAIL_VERIFICATION_SUBJECT": {
+		PtBR: "Validação de E-mail",
+		EnUS: "Email Verification",
+	},
+	"EMAIL_CHANGE_SUBJECT": {
+		PtBR: "Confirme seu novo e-mail",
+		EnUS: "Confirm your new email",
+	},
+	"EMAIL_CHANGE_NOTICE_SUBJECT": {
+		PtBR: "Alteração de e-mail solicitada",
+		EnUS: "Email change requested",
+	},
+	"DATA_EXPORT_READY_SUBJECT": {
+		PtBR: "Seus dados estão prontos para download",
+		EnUS: "Your data is ready to download",
+	},
+
+	// Rótulos dos arquivos de exportação de lançamentos.
+	"EXPORT_DATE": {
+		PtBR: "Data",
+		EnUS: "Date",
+	},
+	"EXPORT_TYPE": {
+		PtBR: "Tipo",
+		EnUS: "Type",
+	},
+	"EXPORT_CATEGORY": {
+		PtBR: "Categoria",
+		EnUS: "Category",
+	},
+	"EXPORT_DESCRIPTION": {
+		PtBR: "Descrição",
+		EnUS: "Description",
+	},
+	"EXPORT_PAYMENT_METHOD": {
+		PtBR: "Forma de pagamento",
+		EnUS: "Payment method",
+	},
+	"EXPORT_AMOUNT": {
+		PtBR: "Valor",
+		EnUS: "Amount",
+	},
+	"EXPORT_TYPE_income": {
+		PtBR: "Receita",
+		EnUS: "Income",
+	},
+	"EXPORT_TYPE_expense": {
+		PtBR: "Despesa",
+		EnUS: "Expense",
+	},
+	"EXPORT_TYPE_transfer_in": {
+		PtBR: "Transferência recebida",
+		EnUS: "Transfer in",
+	},
+	"EXPORT_TYPE_transfer_out": {
+		PtBR: "Transferência enviada",
+		EnUS: "Transfer out",
+	},
+	"EXPORT_SUMMARY_SHEET": {
+		PtBR: "Resumo",
+		EnUS: "Summary",
+	},
+	"EXPORT_TRANSACTIONS_SHEET": {
+		PtBR: "Lançamentos",
+		EnUS: "Transactions",
+	},
+	"EXPORT_PERIOD": {
+		PtBR: "Período",
+		EnUS: "Period",
+	},
+	"EXPORT_INCOME": {
+		PtBR: "Receitas",
+		EnUS: "Income",
+	},
+	"EXPORT_EXPENSE": {
+		PtBR: "Despesas",
+		EnUS: "Expenses",
+	},
+	"EXPORT_NET": {
+		PtBR: "Saldo do período",
+		EnUS: "Net",
+	},
+	"EXPORT_TRANSFERS_IN": {
+		PtBR: "Transferências recebidas",
+		EnUS: "Transfers in",
+	},
+	"EXPORT_TRANSFERS_OUT": {
+		PtBR: "Transferências enviadas",
+		EnUS: "Transfers out",
+	},
+	"EXPORT_COUNT": {
+		PtBR: "Lançamentos",
+		EnUS: "Transactions",
+	},
+	"EXPORT_UNCATEGORIZED": {
+		PtBR: "Sem categoria",
+		EnUS: "Uncategorized",
+	},
+}
+
+// Translate retorna o texto do código no locale pedido, caindo para o
+// DefaultLocale e, por fim, para o próprio código.
+func Translate(locale, code string) string {
+	texts, ok := messages[code]
+	if !ok {
+		return code
+	}
+
+	if text, ok := texts[locale]; ok {
+		return text
+	}
+
+	if text, ok := texts[DefaultLocale]; ok {
+		return text
+	}
+
+	return code
+}
@@ -0,0 +1,147 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+var (
+	ErrTooLarge     = errors.New("image exceeds the size limit")
+	ErrInvalidImage = errors.New("invalid image")
+)
+
+// MaxPixels protege contra "decompression bombs": arquivos pequenos que
+// declaram dimensões enormes e estourariam a memória ao decodificar.
+const MaxPixels = 25_000_000
+
+const jpegQuality = 85
+
+type Variant struct {
+	Name   string
+	Width  int
+	Height int
+}
+
+// Profile descreve um tipo de imagem. O primeiro variant é o principal, cuja
+// URL fica salva no usuário.
+type Profile struct {
+	Folder   string
+	MaxBytes int64
+	Variants []Variant
+}
+
+var Avatar = Profile{
+	Folder:   "avatars",
+	MaxBytes: 5 << 20,
+	Variants: []Variant{
+		{Name: "256x256", Width: 256, Height: 256},
+		{Name: "128x128", Width: 128, Height: 128},
+		{Name: "64x64", Width: 64, Height: 64},
+	},
+}
+
+var Banner = Profile{
+	Folder:   "banners",
+	MaxBytes: 8 << 20,
+	Variants: []Variant{
+		{Name: "1500x500", Width: 1500, Height: 500},
+		{Name: "750x250", Width: 750, Height: 250},
+	},
+}
+
+func (p Profile) VariantNames() []string {
+	names := make([]string, len(p.Variants))
+	for i, v := range p.Variants {
+		names[i] = v.Name
+	}
+	return names
+}
+
+type Image struct {
+	Variant     Variant
+	Data        []byte
+	ContentType string
+}
+
+// Process valida e normaliza um upload: decodifica (JPEG, PNG ou WebP),
+// aplica a orientação EXIF, recorta no centro e redimensiona para cada
+// variant. A saída é sempre JPEG recodificado, o que descarta EXIF/GPS e
+// qualquer outro metadado. Go não tem encoder WebP nativo, por isso o WebP é
+// aceito apenas na entrada.
+func Process(data []byte, profile Profile) ([]Image, error) {
+	if int64(len(data)) > profile.MaxBytes {
+		return nil, ErrTooLarge
+	}
+
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	if cfg.Width*cfg.Height > MaxPixels {
+		return nil, ErrTooLarge
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	if format == "jpeg" {
+		src = applyOrientation(src, exifOrientation(data))
+	}
+
+	images := make([]Image, 0, len(profile.Variants))
+	for _, variant := range profile.Variants {
+		encoded, err := encodeJPEG(resize(src, variant.Width, variant.Height))
+		if err != nil {
+			return nil, err
+		}
+
+		images = append(images, Image{
+			Variant:     variant,
+			Data:        encoded,
+			ContentType: "image/jpeg",
+		})
+	}
+
+	return images, nil
+}
+
+// resize recorta o maior retângulo central com a proporção do destino e o
+// escala. O fundo branco substitui a transparência de PNG/WebP.
+func resize(src image.Image, width, height int) *image.RGBA {
+	b := src.Bounds()
+	crop := b
+	if b.Dx()*height > b.Dy()*width {
+		w := b.Dy() * width / height
+		crop.Min.X = b.Min.X + (b.Dx()-w)/2
+		crop.Max.X = crop.Min.X + w
+	} else {
+		h := b.Dx() * height / width
+		crop.Min.Y = b.Min.Y + (b.Dy()-h)/2
+		crop.Max.Y = crop.Min.Y + h
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, crop, draw.Over, nil)
+
+	return dst
+}
+
+func encodeJPEG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return nil, fmt.Errorf("failed to encode jpeg: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,117 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"nexa/internal/config"
+	"nexa/internal/imaging"
+	"nexa/internal/logger"
+	"nexa/internal/model"
+	"nexa/internal/repository"
+	"nexa/internal/storage"
+	"nexa/internal/tracing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const accountDeletionBatchSize = 100
+
+// AccountDeletion apaga definitivamente (LGPD) as contas desativadas há mais
+// que o período de carência: imagens no storage, a linha de tb_user com tudo
+// que depende dela em cascata, deixando apenas o registro anônimo.
+type AccountDeletion struct {
+	Users       *repository.UserRepository
+	Exports     *repository.DataExportRepository
+	Storage     storage.ObjectStorage
+	GracePeriod time.Duration
+	Interval    time.Duration
+}
+
+func NewAccountDeletion(db *pgxpool.Pool, objectStorage storage.ObjectStorage, cfg config.AccountConfig) *AccountDeletion {
+	return &AccountDeletion{
+		Users:       repository.NewUserRepository(db),
+		Exports:     repository.NewDataExportRepository(db),
+		Storage:     objectStorage,
+		GracePeriod: cfg.DeletionGracePeriod(),
+		Interval:    cfg.DeletionJobInterval,
+	}
+}
+
+// Run executa RunOnce a cada Interval até ctx ser cancelado.
+func (j *AccountDeletion) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.Interval)
+	defer ticker.Stop()
+
+	for {
+		deleted, err := j.RunOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.FromContext(ctx).Error().Err(err).Msg("account deletion failed")
+		}
+		if deleted > 0 {
+			logger.FromContext(ctx).Info().Int("deleted", deleted).Msg("deactivated accounts deleted")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce processa um lote e retorna quantas contas foram apagadas. Uma conta
+// cujos arquivos não puderam ser removidos fica para a próxima execução.
+func (j *AccountDeletion) RunOnce(ctx context.Context) (int, error) {
+	ctx, span := tracing.Start(ctx, "AccountDeletion.RunOnce")
+	defer span.End()
+
+	users, err := j.Users.FindDeactivatedBefore(ctx, time.Now().Add(-j.GracePeriod), accountDeletionBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	deleted := 0
+	for i := range users {
+		user := &users[i]
+
+		if err := j.deleteFiles(ctx, user); err != nil {
+			errs = append(errs, fmt.Errorf("user %s: %w", user.ID, err))
+			continue
+		}
+
+		err := j.Users.HardDelete(ctx, user)
+		if errors.Is(err, repository.ErrUserNotFound) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("user %s: %w", user.ID, err))
+			continue
+		}
+
+		deleted++
+	}
+
+	return deleted, errors.Join(errs...)
+}
+
+// deleteFiles apaga do storage as imagens e as exportações de dados, que a
+// cascata do banco não alcança.
+func (j *AccountDeletion) deleteFiles(ctx context.Context, user *model.User) error {
+	errs := []error{
+		storage.DeleteImage(ctx, j.Storage, user.PhotoUrl, imaging.Avatar.VariantNames()),
+		storage.DeleteImage(ctx, j.Storage, user.Banner, imaging.Banner.VariantNames()),
+	}
+
+	keys, err := j.Exports.FindStorageKeysByUserID(ctx, user.ID)
+	if err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+	for _, key := range keys {
+		errs = append(errs, j.Storage.Delete(ctx, key))
+	}
+
+	return errors.Join(errs...)
+}
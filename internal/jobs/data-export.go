@@ -0,0 +1,461 @@
+package jobs
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"nexa/internal/config"
+	"nexa/internal/i18n"
+	"nexa/internal/logger"
+	"nexa/internal/model"
+	"nexa/internal/repository"
+	"nexa/internal/storage"
+	"nexa/internal/tracing"
+	"nexa/internal/utils"
+	"os"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const expiredExportsBatchSize = 100
+
+// errExportClaimLost interrompe a montagem de um pedido que outro worker
+// reivindicou.
+var errExportClaimLost = errors.New("data export claimed by another worker")
+
+// imageColumns são as colunas dos datasets que apontam para arquivos no
+// storage; os arquivos entram no ZIP em images/.
+var imageColumns = map[string]bool{
+	"photo_url": true,
+	"banner":    true,
+}
+
+// DataExport consome a fila de tb_data_export: monta um ZIP com os dados do
+// usuário em JSON e CSV e as imagens enviadas, guarda no storage sob uma chave
+// impossível de adivinhar e envia o link por e-mail. O arquivo é apagado do
+// storage quando o link expira.
+type DataExport struct {
+	Exports      *repository.DataExportRepository
+	Users        *repository.UserRepository
+	Settings     *repository.SettingsRepository
+	Storage      storage.ObjectStorage
+	MailServer   *utils.MailServer
+	LinkTTL      time.Duration
+	PollInterval time.Duration
+
+	wake chan struct{}
+}
+
+func NewDataExport(db *pgxpool.Pool, objectStorage storage.ObjectStorage, mailServer *utils.MailServer, cfg config.ExportConfig) *DataExport {
+	return &DataExport{
+		Exports:      repository.NewDataExportRepository(db),
+		Users:        repository.NewUserRepository(db),
+		Settings:     repository.NewSettingsRepository(db),
+		Storage:      objectStorage,
+		MailServer:   mailServer,
+		LinkTTL:      cfg.LinkTTL,
+		PollInterval: cfg.PollInterval,
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+// Notify acorda o worker após um novo pedido, sem esperar o PollInterval.
+// Nunca bloqueia.
+func (j *DataExport) Notify() {
+	select {
+	case j.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run esvazia a fila e remove os arquivos expirados a cada PollInterval ou
+// Notify, até ctx ser cancelado.
+func (j *DataExport) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		j.drain(ctx)
+
+		if err := j.RemoveExpired(ctx); err != nil && ctx.Err() == nil {
+			logger.FromContext(ctx).Error().Err(err).Msg("failed to remove expired data exports")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-j.wake:
+		}
+	}
+}
+
+func (j *DataExport) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		processed, err := j.RunOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.FromContext(ctx).Error().Err(err).Msg("data export failed")
+		}
+		if !processed {
+			return
+		}
+	}
+}
+
+// RunOnce processa o próximo pedido da fila. processed é false quando a fila
+// está vazia. Um pedido que falha é marcado como failed e não é refeito.
+func (j *DataExport) RunOnce(ctx context.Context) (processed bool, err error) {
+	ctx, span := tracing.Start(ctx, "DataExport.RunOnce")
+	defer span.End()
+
+	export, err := j.Exports.ClaimNext(ctx)
+	if err != nil || export == nil {
+		return false, err
+	}
+
+	buildCtx, cancel := context.WithCancelCause(ctx)
+	heartbeatDone := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+		j.heartbeat(buildCtx, export, cancel)
+	}()
+
+	err = j.build(buildCtx, export)
+	cancel(nil)
+	<-heartbeatDone
+
+	if err != nil {
+		if ctx.Err() != nil {
+			// Desligamento: o pedido volta para a fila ao ser considerado abandonado.
+			return true, err
+		}
+		if errors.Is(context.Cause(buildCtx), errExportClaimLost) {
+			// O pedido agora é do outro worker, que o marca como pronto ou falho.
+			return true, fmt.Errorf("export %s: %w", export.ID, errExportClaimLost)
+		}
+		tracing.RecordError(span, err)
+		return true, errors.Join(
+			fmt.Errorf("export %s: %w", export.ID, err),
+			j.Exports.MarkFailed(context.WithoutCancel(ctx), export.ID, err),
+		)
+	}
+
+	return true, nil
+}
+
+// heartbeat renova o pedido a cada DataExportHeartbeatInterval enquanto ctx
+// durar e cancela a montagem se outro worker o reivindicar.
+func (j *DataExport) heartbeat(ctx context.Context, export *model.DataExport, cancel context.CancelCauseFunc) {
+	ticker := time.NewTicker(repository.DataExportHeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		owned, err := j.Exports.Heartbeat(ctx, export)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.FromContext(ctx).Warn().Err(err).Str("export", export.ID).Msg("failed to renew data export")
+			}
+			continue
+		}
+		if !owned {
+			cancel(errExportClaimLost)
+			return
+		}
+	}
+}
+
+func (j *DataExport) build(ctx context.Context, export *model.DataExport) error {
+	datasets, err := j.Exports.DumpUserData(ctx, export.UserID)
+	if err != nil {
+		return err
+	}
+
+	archive, err := os.CreateTemp("", "nexa-export-*.zip")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+
+	if err := j.writeArchive(ctx, archive, datasets); err != nil {
+		return err
+	}
+	if _, err := archive.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to rewind archive: %w", err)
+	}
+
+	key, err := exportKey(export.UserID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := j.Storage.Put(ctx, key, archive, "application/zip"); err != nil {
+		return fmt.Errorf("failed to upload archive: %w", err)
+	}
+
+	url, err := storage.TemporaryURL(j.Storage, key, j.LinkTTL)
+	if err != nil {
+		return errors.Join(err, j.Storage.Delete(context.WithoutCancel(ctx), key))
+	}
+
+	expiresAt := time.Now().Add(j.LinkTTL)
+	export.StorageKey = key
+	export.DownloadURL = url
+	export.ExpiresAt = &expiresAt
+	if err := j.Exports.MarkReady(ctx, export); err != nil {
+		return errors.Join(err, j.Storage.Delete(context.WithoutCancel(ctx), key))
+	}
+
+	if err := j.sendReadyEmail(ctx, export); err != nil {
+		// O link continua disponível em GET /me/export/:id.
+		logger.FromContext(ctx).Warn().Err(err).Str("export", export.ID).Msg("failed to send data export email")
+	}
+
+	return nil
+}
+
+// exportKey gera "exports/<idUser>/<aleatório>.zip". Nem todo storage assina
+// URLs (ver storage.TemporaryURL), então a chave também precisa ser secreta.
+func exportKey(userID string) (string, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to generate export key: %w", err)
+	}
+
+	return "exports/" + userID + "/" + hex.EncodeToString(random) + ".zip", nil
+}
+
+func (j *DataExport) writeArchive(ctx context.Context, w io.Writer, datasets []model.ExportDataset) error {
+	archive := zip.NewWriter(w)
+
+	manifest := map[string]any{
+		"exportedAt": time.Now().UTC(),
+		"datasets":   map[string]int{},
+	}
+
+	var images []string
+	for _, dataset := range datasets {
+		manifest["datasets"].(map[string]int)[dataset.Name] = len(dataset.Rows)
+
+		if err := writeJSON(archive, dataset.Name+".json", datasetObjects(dataset)); err != nil {
+			return err
+		}
+		if err := writeCSV(archive, dataset.Name+".csv", dataset); err != nil {
+			return err
+		}
+
+		images = append(images, datasetImages(dataset)...)
+	}
+
+	var missing []string
+	seen := map[string]bool{}
+	for _, url := range images {
+		key := j.Storage.Key(url)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if err := j.copyImage(ctx, archive, key); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			// Uma imagem que sumiu do storage não impede a exportação dos dados.
+			logger.FromContext(ctx).Warn().Err(err).Str("key", key).Msg("failed to export image")
+			missing = append(missing, url)
+		}
+	}
+	if len(missing) > 0 {
+		manifest["missingImages"] = missing
+	}
+
+	if err := writeJSON(archive, "manifest.json", manifest); err != nil {
+		return err
+	}
+
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+
+	return nil
+}
+
+func (j *DataExport) copyImage(ctx context.Context, archive *zip.Writer, key string) error {
+	body, err := j.Storage.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	name := "images/" + key
+	if path.Ext(name) == "" {
+		// Os variants processados são sempre JPEG (internal/imaging).
+		name += ".jpg"
+	}
+
+	file, err := archive.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+
+	_, err = io.Copy(file, body)
+	return err
+}
+
+func datasetObjects(dataset model.ExportDataset) []map[string]any {
+	objects := make([]map[string]any, 0, len(dataset.Rows))
+	for _, row := range dataset.Rows {
+		object := make(map[string]any, len(row))
+		for i, value := range row {
+			object[dataset.Columns[i]] = value
+		}
+		objects = append(objects, object)
+	}
+
+	return objects
+}
+
+func datasetImages(dataset model.ExportDataset) []string {
+	var urls []string
+	for i, column := range dataset.Columns {
+		if !imageColumns[column] {
+			continue
+		}
+		for _, row := range dataset.Rows {
+			if url, ok := row[i].(string); ok && url != "" {
+				urls = append(urls, url)
+			}
+		}
+	}
+
+	return urls
+}
+
+func writeJSON(archive *zip.Writer, name string, value any) error {
+	file, err := archive.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+
+	return nil
+}
+
+func writeCSV(archive *zip.Writer, name string, dataset model.ExportDataset) error {
+	file, err := archive.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+
+	w := csv.NewWriter(file)
+	if err := w.Write(dataset.Columns); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+
+	record := make([]string, len(dataset.Columns))
+	for _, row := range dataset.Rows {
+		for i, value := range row {
+			record[i] = csvValue(value)
+		}
+		if err := w.Write(record); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+
+	return nil
+}
+
+func csvValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (j *DataExport) sendReadyEmail(ctx context.Context, export *model.DataExport) error {
+	user, err := j.Users.FindByFilter(ctx, "id", export.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return repository.ErrUserNotFound
+	}
+
+	locale := i18n.DefaultLocale
+	if settings, err := j.Settings.FindByUserID(ctx, export.UserID); err == nil && settings != nil {
+		if normalized := i18n.Normalize(settings.Language); normalized != "" {
+			locale = normalized
+		}
+	}
+
+	expiresAt := export.ExpiresAt.UTC().Format("02/01/2006 15:04 MST")
+	if locale == i18n.EnUS {
+		expiresAt = export.ExpiresAt.UTC().Format("Jan 2, 2006 15:04 MST")
+	}
+
+	return j.MailServer.SendTemplateEmail(ctx, locale, "dataExportReady.html", "DATA_EXPORT_READY_SUBJECT", user.Email, map[string]any{
+		"Name":        user.Name,
+		"DownloadURL": export.DownloadURL,
+		"ExpiresAt":   expiresAt,
+	})
+}
+
+// RemoveExpired apaga do storage os arquivos cujo link venceu. Um arquivo que
+// não pôde ser apagado fica para a próxima execução.
+func (j *DataExport) RemoveExpired(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "DataExport.RemoveExpired")
+	defer span.End()
+
+	exports, err := j.Exports.FindExpired(ctx, expiredExportsBatchSize)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, export := range exports {
+		if err := j.Storage.Delete(ctx, export.StorageKey); err != nil {
+			errs = append(errs, fmt.Errorf("export %s: %w", export.ID, err))
+			continue
+		}
+		if err := j.Exports.MarkExpired(ctx, export.ID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,99 @@
+package jobs
+
+import (
+	"context"
+	"nexa/internal/config"
+	"nexa/internal/logger"
+	"nexa/internal/repository"
+	"nexa/internal/tracing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// maxOccurrencesPerClaim limita quantas ocorrências atrasadas de um modelo
+// são lançadas numa transação; o restante sai nas próximas.
+const maxOccurrencesPerClaim = 200
+
+// RecurringTransactions lança as ocorrências vencidas dos lançamentos
+// recorrentes. Roda em todas as instâncias: cada modelo é travado com SKIP
+// LOCKED e cada ocorrência tem no máximo um lançamento.
+type RecurringTransactions struct {
+	Recurring    *repository.RecurringTransactionRepository
+	PollInterval time.Duration
+	Location     *time.Location
+
+	wake chan struct{}
+}
+
+func NewRecurringTransactions(db *pgxpool.Pool, cfg config.RecurringConfig) *RecurringTransactions {
+	// O fuso já foi validado na carga da configuração.
+	location, err := cfg.Location()
+	if err != nil {
+		location = time.UTC
+	}
+
+	return &RecurringTransactions{
+		Recurring:    repository.NewRecurringTransactionRepository(db),
+		PollInterval: cfg.PollInterval,
+		Location:     location,
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+// Notify acorda o agendador após a criação de um modelo, para que uma
+// ocorrência de hoje não espere o PollInterval. Nunca bloqueia.
+func (j *RecurringTransactions) Notify() {
+	select {
+	case j.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run executa RunOnce a cada PollInterval ou Notify até ctx ser cancelado.
+func (j *RecurringTransactions) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		posted, err := j.RunOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.FromContext(ctx).Error().Err(err).Msg("recurring transactions failed")
+		}
+		if posted > 0 {
+			logger.FromContext(ctx).Info().Int("posted", posted).Msg("recurring transactions posted")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-j.wake:
+		}
+	}
+}
+
+// RunOnce lança tudo o que venceu até hoje e retorna quantos lançamentos
+// foram criados.
+func (j *RecurringTransactions) RunOnce(ctx context.Context) (int, error) {
+	ctx, span := tracing.Start(ctx, "RecurringTransactions.RunOnce")
+	defer span.End()
+
+	now := time.Now().In(j.Location)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	total := 0
+	for ctx.Err() == nil {
+		posted, processed, err := j.Recurring.PostNextDue(ctx, today, maxOccurrencesPerClaim)
+		total += posted
+		if err != nil {
+			tracing.RecordError(span, err)
+			return total, err
+		}
+		if !processed {
+			break
+		}
+	}
+
+	return total, ctx.Err()
+}
@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+const localsKey = "logger"
+
+// Init configura o logger global do zerolog: JSON em produção e saída
+// legível no console nos demais ambientes.
+func Init(production bool, level string) {
+	parsedLevel, err := zerolog.ParseLevel(level)
+	if err != nil || level == "" {
+		parsedLevel = zerolog.InfoLevel
+	}
+	zerolog.SetGlobalLevel(parsedLevel)
+	zerolog.TimeFieldFormat = time.RFC3339Nano
+
+	if production {
+		log.Logger = zerolog.New(os.Stdout).With().Timestamp().Logger()
+		return
+	}
+
+	log.Logger = zerolog.New(zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: time.TimeOnly}).With().Timestamp().Logger()
+}
+
+// FromCtx retorna o logger da requisição (com request ID, rota e usuário)
+// ou o logger global fora de uma requisição.
+func FromCtx(c *fiber.Ctx) *zerolog.Logger {
+	if l, ok := c.Locals(localsKey).(*zerolog.Logger); ok {
+		return l
+	}
+
+	return &log.Logger
+}
+
+// FromContext é o equivalente para código que recebe apenas context.Context.
+func FromContext(ctx context.Context) *zerolog.Logger {
+	l := zerolog.Ctx(ctx)
+	if l.GetLevel() == zerolog.Disabled {
+		return &log.Logger
+	}
+
+	return l
+}
+
+// Attach guarda o logger nos locals do Fiber e no UserContext da requisição.
+func Attach(c *fiber.Ctx, l *zerolog.Logger) {
+	c.Locals(localsKey, l)
+	c.SetUserContext(l.WithContext(c.UserContext()))
+}
@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+var sensitiveKeys = []string{"password", "code", "token", "secret", "authorization"}
+
+func isSensitive(key string) bool {
+	lowered := strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(lowered, sensitive) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Redact devolve uma cópia do valor com campos sensíveis (senha, código,
+// token...) substituídos, percorrendo mapas e listas aninhados.
+func Redact(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, item := range v {
+			if isSensitive(key) {
+				out[key] = redacted
+				continue
+			}
+			out[key] = Redact(item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = Redact(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// RedactJSON aplica Redact a um corpo JSON. Corpos que não são JSON não são
+// registrados.
+func RedactJSON(body []byte) json.RawMessage {
+	if len(body) == 0 {
+		return nil
+	}
+
+	var payload any
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return json.RawMessage(`"[non-JSON body omitted]"`)
+	}
+
+	redactedBody, err := json.Marshal(Redact(payload))
+	if err != nil {
+		return nil
+	}
+
+	return redactedBody
+}
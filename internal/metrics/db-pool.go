@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// poolCollector lê pgxpool.Stat a cada scrape, sem manter estado próprio.
+type poolCollector struct {
+	pool *pgxpool.Pool
+
+	acquired        *prometheus.Desc
+	idle            *prometheus.Desc
+	constructing    *prometheus.Desc
+	total           *prometheus.Desc
+	max             *prometheus.Desc
+	acquireCount    *prometheus.Desc
+	acquireDuration *prometheus.Desc
+	emptyAcquire    *prometheus.Desc
+	canceledAcquire *prometheus.Desc
+}
+
+func RegisterDBPool(pool *pgxpool.Pool) {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName("nexa", "db_pool", name), help, nil, nil)
+	}
+
+	Registry.MustRegister(&poolCollector{
+		pool:            pool,
+		acquired:        desc("acquired_connections", "Conexões em uso."),
+		idle:            desc("idle_connections", "Conexões ociosas."),
+		constructing:    desc("constructing_connections", "Conexões sendo abertas."),
+		total:           desc("total_connections", "Total de conexões abertas."),
+		max:             desc("max_connections", "Tamanho máximo do pool."),
+		acquireCount:    desc("acquire_total", "Total de aquisições de conexão."),
+		acquireDuration: desc("acquire_duration_seconds_total", "Tempo total gasto aguardando conexões."),
+		emptyAcquire:    desc("empty_acquire_total", "Aquisições que precisaram esperar por uma conexão livre."),
+		canceledAcquire: desc("canceled_acquire_total", "Aquisições canceladas pelo contexto."),
+	})
+}
+
+func (p *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{p.acquired, p.idle, p.constructing, p.total, p.max, p.acquireCount, p.acquireDuration, p.emptyAcquire, p.canceledAcquire} {
+		ch <- d
+	}
+}
+
+func (p *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := p.pool.Stat()
+
+	ch <- prometheus.MustNewConstMetric(p.acquired, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(p.idle, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(p.constructing, prometheus.GaugeValue, float64(stat.ConstructingConns()))
+	ch <- prometheus.MustNewConstMetric(p.total, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(p.max, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(p.acquireCount, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(p.acquireDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(p.emptyAcquire, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(p.canceledAcquire, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+}
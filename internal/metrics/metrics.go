@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry é próprio da aplicação para que /metrics exponha apenas o que é
+// registrado aqui, mais os coletores de runtime do Go e do processo.
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "nexa",
+		Name:      "http_requests_total",
+		Help:      "Total de requisições HTTP por rota, método e status.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "nexa",
+		Name:      "http_request_duration_seconds",
+		Help:      "Latência das requisições HTTP por rota, método e status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	dbQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "nexa",
+		Name:      "db_query_duration_seconds",
+		Help:      "Duração das consultas ao banco por método de repositório.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+	}, []string{"repository", "method"})
+
+	emailsSent = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "nexa",
+		Name:      "emails_sent_total",
+		Help:      "E-mails enviados, por resultado.",
+	}, []string{"result"})
+
+	loginAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "nexa",
+		Name:      "login_attempts_total",
+		Help:      "Tentativas de login, por resultado.",
+	}, []string{"result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		dbQueryDuration,
+		emailsSent,
+		loginAttempts,
+	)
+}
+
+// Handler expõe o Registry no formato texto do Prometheus.
+func Handler() fiber.Handler {
+	return adaptor.HTTPHandler(promhttp.HandlerFor(Registry, promhttp.HandlerOpts{}))
+}
+
+// Middleware registra contagem e latência por rota. A rota é o padrão
+// registrado no Fiber (ex.: /users/:username), nunca o path bruto, para manter
+// a cardinalidade baixa.
+func Middleware(c *fiber.Ctx) error {
+	start := time.Now()
+
+	err := c.Next()
+	if err != nil {
+		if handlerErr := c.App().ErrorHandler(c, err); handlerErr != nil {
+			_ = c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+
+	labels := prometheus.Labels{
+		"method": c.Method(),
+		"route":  c.Route().Path,
+		"status": strconv.Itoa(c.Response().StatusCode()),
+	}
+	httpRequests.With(labels).Inc()
+	httpDuration.With(labels).Observe(time.Since(start).Seconds())
+
+	return nil
+}
+
+// ObserveQuery mede uma consulta de repositório:
+//
+//	defer metrics.ObserveQuery("UserRepository", "FindByFilter")()
+func ObserveQuery(repository, method string) func() {
+	start := time.Now()
+	return func() {
+		dbQueryDuration.WithLabelValues(repository, method).Observe(time.Since(start).Seconds())
+	}
+}
+
+func RecordEmail(success bool) {
+	emailsSent.WithLabelValues(result(success)).Inc()
+}
+
+func RecordLogin(success bool) {
+	loginAttempts.WithLabelValues(result(success)).Inc()
+}
+
+func result(success bool) string {
+	if success {
+		return "success"
+	}
+	return "failure"
+}
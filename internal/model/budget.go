@@ -0,0 +1,19 @@
+package model
+
+import (
+	"nexa/internal/money"
+	"time"
+)
+
+// Budget é o orçamento de uma carteira (ou de uma categoria dela) no mês.
+// CurrentSpent é calculado a partir das despesas do mês, sem transferências.
+type Budget struct {
+	ID             string       `json:"id"`
+	WalletID       string       `json:"walletId"`
+	CategoryID     *string      `json:"categoryId,omitempty"`
+	ReferenceMonth time.Time    `json:"referenceMonth"`
+	TotalLimit     money.Amount `json:"totalLimit"`
+	CurrentSpent   money.Amount `json:"currentSpent"`
+	SavingGoal     money.Amount `json:"savingGoal"`
+	CreatedAt      time.Time    `json:"createdAt"`
+}
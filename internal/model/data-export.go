@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+const (
+	DataExportPending    = "pending"
+	DataExportProcessing = "processing"
+	DataExportReady      = "ready"
+	DataExportFailed     = "failed"
+	DataExportExpired    = "expired"
+)
+
+// DataExport é um pedido de exportação dos dados do usuário (LGPD). O arquivo
+// fica no storage em StorageKey e o link só é exposto enquanto status é ready.
+type DataExport struct {
+	ID          string     `json:"id"`
+	UserID      string     `json:"-"`
+	Status      string     `json:"status"`
+	StorageKey  string     `json:"-"`
+	DownloadURL string     `json:"downloadUrl,omitempty"`
+	Error       string     `json:"-"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	StartedAt   *time.Time `json:"startedAt,omitempty"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+}
+
+// ExportDataset é uma tabela exportada: as colunas na ordem do CSV e as
+// linhas já com valores serializáveis (string, json.Number, bool, time.Time ou nil).
+type ExportDataset struct {
+	Name    string
+	Columns []string
+	Rows    [][]any
+}
@@ -11,10 +11,18 @@ type UserRegisterSuccessfulResponse struct {
 }
 
 type ErrorResponse struct {
-	StatusCode int       `json:"status" bson:"status"`
-	Error      string    `json:"error" bson:"error"`
-	Message    string    `json:"message" bson:"message"`
-	Timestamp  time.Time `json:"timestamp" bson:"timestamp"`
-	Path       string    `json:"path" bson:"path"`
-	Input      string    `json:"input,omitempty" bson:"input"`
+	StatusCode int           `json:"status" bson:"status"`
+	Code       string        `json:"code,omitempty" bson:"code,omitempty"`
+	Error      string        `json:"error" bson:"error"`
+	Message    string        `json:"message" bson:"message"`
+	Timestamp  time.Time     `json:"timestamp" bson:"timestamp"`
+	Path       string        `json:"path" bson:"path"`
+	Input      string        `json:"input,omitempty" bson:"input"`
+	IDUser     string        `json:"idUser,omitempty" bson:"idUser,omitempty"`
+	Details    []ErrorDetail `json:"details,omitempty" bson:"details,omitempty"`
+}
+
+type ErrorDetail struct {
+	Code    string `json:"code" bson:"code"`
+	Message string `json:"message" bson:"message"`
 }
@@ -0,0 +1,15 @@
+package model
+
+import "nexa/internal/money"
+
+// MonthFlow é o fluxo de uma carteira no mês. Income e Expense não incluem
+// transferências, que aparecem à parte.
+type MonthFlow struct {
+	WalletID     string       `json:"walletId"`
+	Month        string       `json:"month"`
+	Income       money.Amount `json:"income"`
+	Expense      money.Amount `json:"expense"`
+	Net          money.Amount `json:"net"`
+	TransfersIn  money.Amount `json:"transfersIn"`
+	TransfersOut money.Amount `json:"transfersOut"`
+}
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"nexa/internal/currency"
+	"nexa/internal/money"
+	"time"
+)
+
+// NetWorth é o patrimônio do usuário numa data, somando o saldo de todas as
+// carteiras convertido para Currency. Carteiras sem cotação disponível ficam
+// fora do total, com Converted nulo, e a moeda aparece em MissingCurrencies.
+type NetWorth struct {
+	Date              time.Time       `json:"date"`
+	Currency          string          `json:"currency"`
+	Total             money.Amount    `json:"total"`
+	Wallets           []WalletBalance `json:"wallets"`
+	MissingCurrencies []string        `json:"missingCurrencies"`
+}
+
+// WalletBalance é o saldo de uma carteira na data, na moeda dela e
+// convertido.
+type WalletBalance struct {
+	WalletID     string         `json:"walletId"`
+	Name         string         `json:"name"`
+	Currency     string         `json:"currency"`
+	Balance      money.Amount   `json:"balance"`
+	Converted    *money.Amount  `json:"converted"`
+	ExchangeRate *currency.Rate `json:"exchangeRate,omitempty"`
+	RateDate     *time.Time     `json:"rateDate,omitempty"`
+}
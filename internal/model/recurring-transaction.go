@@ -0,0 +1,89 @@
+package model
+
+import (
+	"nexa/internal/money"
+	"nexa/internal/recurrence"
+	"time"
+)
+
+// RecurringTransaction é o modelo de um lançamento que se repete conforme
+// Rule. NextIndex é a próxima ocorrência que o agendador vai lançar, em
+// NextDate (já ajustada para dia útil); NextDate é nil quando a série acabou.
+type RecurringTransaction struct {
+	ID            string       `json:"id"`
+	WalletID      string       `json:"walletId"`
+	CategoryID    *string      `json:"categoryId,omitempty"`
+	Amount        money.Amount `json:"amount"`
+	Type          string       `json:"type"`
+	PaymentMethod string       `json:"paymentMethod,omitempty"`
+	Description   string       `json:"description"`
+	recurrence.Rule
+	NextIndex int        `json:"-"`
+	NextDate  *time.Time `json:"nextDate"`
+	Active    bool       `json:"active"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// RecurrenceException pula ou altera uma ocorrência, identificada pela data
+// nominal. Campos nil mantêm o valor do modelo.
+type RecurrenceException struct {
+	Date          time.Time     `json:"date"`
+	Skip          bool          `json:"skip"`
+	Amount        *money.Amount `json:"amount,omitempty"`
+	Description   *string       `json:"description,omitempty"`
+	CategoryID    *string       `json:"categoryId,omitempty"`
+	PaymentMethod *string       `json:"paymentMethod,omitempty"`
+}
+
+// Status de uma ocorrência na listagem.
+const (
+	OccurrencePosted    = "posted"
+	OccurrenceSkipped   = "skipped"
+	OccurrenceScheduled = "scheduled"
+)
+
+// RecurringOccurrence é uma ocorrência com os valores que terá (ou teve) ao
+// ser lançada.
+type RecurringOccurrence struct {
+	recurrence.Occurrence
+	Status        string       `json:"status"`
+	Edited        bool         `json:"edited"`
+	Amount        money.Amount `json:"amount"`
+	Description   string       `json:"description"`
+	CategoryID    *string      `json:"categoryId,omitempty"`
+	PaymentMethod string       `json:"paymentMethod,omitempty"`
+	TransactionID *string      `json:"transactionId,omitempty"`
+}
+
+// Apply devolve o lançamento da ocorrência, ou nil se ela foi pulada.
+func (r *RecurringTransaction) Apply(occurrence recurrence.Occurrence, exception *RecurrenceException) *Transaction {
+	if exception != nil && exception.Skip {
+		return nil
+	}
+
+	transaction := &Transaction{
+		WalletID:      r.WalletID,
+		CategoryID:    r.CategoryID,
+		Amount:        r.Amount,
+		Type:          r.Type,
+		PaymentMethod: r.PaymentMethod,
+		Date:          occurrence.PostingDate,
+		Description:   r.Description,
+	}
+	if exception != nil {
+		if exception.Amount != nil {
+			transaction.Amount = *exception.Amount
+		}
+		if exception.Description != nil {
+			transaction.Description = *exception.Description
+		}
+		if exception.CategoryID != nil {
+			transaction.CategoryID = exception.CategoryID
+		}
+		if exception.PaymentMethod != nil {
+			transaction.PaymentMethod = *exception.PaymentMethod
+		}
+	}
+
+	return transaction
+}
@@ -0,0 +1,9 @@
+package model
+
+type Settings struct {
+	ID       string `json:"id,omitempty"`
+	UserID   string `json:"userID"`
+	Theme    string `json:"theme"`
+	Language string `json:"language"`
+	Currency string `json:"currency"`
+}
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	StatementImportPending  = "pending"
+	StatementImportImported = "imported"
+)
+
+// StatementImport é um extrato enviado para importação em uma carteira.
+// Mapping guarda o último mapeamento usado (só CSV); Content nunca sai na API.
+type StatementImport struct {
+	ID             string          `json:"id"`
+	UserID         string          `json:"-"`
+	WalletID       string          `json:"walletId"`
+	Bank           string          `json:"bank,omitempty"`
+	Format         string          `json:"format"`
+	Filename       string          `json:"filename"`
+	Content        []byte          `json:"-"`
+	Mapping        json.RawMessage `json:"mapping,omitempty"`
+	Status         string          `json:"status"`
+	ImportedCount  int             `json:"importedCount"`
+	DuplicateCount int             `json:"duplicateCount"`
+	InvalidCount   int             `json:"invalidCount"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	ImportedAt     *time.Time      `json:"importedAt,omitempty"`
+}
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"nexa/internal/currency"
+	"nexa/internal/money"
+	"time"
+)
+
+const (
+	TransactionIncome  = "income"
+	TransactionExpense = "expense"
+)
+
+// Transaction é um lançamento de uma carteira. Amount é sempre positivo, na
+// moeda da carteira; o sentido vem de Type. Lançamentos em moeda estrangeira
+// guardam também o valor original e a cotação usada na conversão.
+type Transaction struct {
+	ID               string         `json:"id"`
+	WalletID         string         `json:"walletId"`
+	CategoryID       *string        `json:"categoryId,omitempty"`
+	Amount           money.Amount   `json:"amount"`
+	OriginalAmount   *money.Amount  `json:"originalAmount,omitempty"`
+	OriginalCurrency *string        `json:"originalCurrency,omitempty"`
+	ExchangeRate     *currency.Rate `json:"exchangeRate,omitempty"`
+	Type             string         `json:"type"`
+	PaymentMethod    string         `json:"paymentMethod,omitempty"`
+	Date             time.Time      `json:"date"`
+	Description      string         `json:"description"`
+	PhotoUrl         string         `json:"photoUrl,omitempty"`
+	ImportID         *string        `json:"importId,omitempty"`
+	ExternalID       string         `json:"externalId,omitempty"`
+	TransferID       *string        `json:"transferId,omitempty"`
+	CreatedAt        time.Time      `json:"createdAt"`
+}
+
+// Signed devolve o efeito do lançamento no saldo da carteira.
+func (t *Transaction) Signed() money.Amount {
+	if t.Type == TransactionExpense {
+		return -t.Amount
+	}
+	return t.Amount
+}
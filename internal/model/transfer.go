@@ -0,0 +1,23 @@
+package model
+
+import (
+	"nexa/internal/money"
+	"time"
+)
+
+// Transfer move Amount de FromWalletID para ToWalletID. Os dois lados são
+// lançamentos ligados pelo ID da transferência; se uma das carteiras for
+// apagada, o lado dela some e o campo fica vazio. Entre carteiras de moedas
+// diferentes, ToAmount é o valor que entra no destino, na moeda dele; nas
+// demais é igual a Amount.
+type Transfer struct {
+	ID           string       `json:"id"`
+	UserID       string       `json:"-"`
+	FromWalletID string       `json:"fromWalletId"`
+	ToWalletID   string       `json:"toWalletId"`
+	Amount       money.Amount `json:"amount"`
+	ToAmount     money.Amount `json:"toAmount"`
+	Date         time.Time    `json:"date"`
+	Description  string       `json:"description"`
+	CreatedAt    time.Time    `json:"createdAt"`
+}
@@ -0,0 +1,86 @@
+package model
+
+import "time"
+
+// UserPublicProfile é a projeção visível para qualquer pessoa em
+// GET /users/:username.
+type UserPublicProfile struct {
+	Username string `json:"username"`
+	Name     string `json:"name"`
+	PhotoUrl string `json:"photoUrl"`
+	Banner   string `json:"banner"`
+	Score    int    `json:"score"`
+}
+
+// UserPrivateProfile é o perfil completo do próprio usuário em GET /me.
+// Nenhuma das duas projeções carrega o hash da senha.
+type UserPrivateProfile struct {
+	ID        string    `json:"id"`
+	Username  string    `json:"username"`
+	Name      string    `json:"name"`
+	FirstName string    `json:"firstName"`
+	LastName  string    `json:"lastName"`
+	Email     string    `json:"email"`
+	PhotoUrl  string    `json:"photoUrl"`
+	Banner    string    `json:"banner"`
+	Score     int       `json:"score"`
+	CreatedAt time.Time `json:"createdAt"`
+
+	PendingEmail string `json:"pendingEmail,omitempty"`
+
+	LastLogin time.Time `json:"lastLogin"`
+	IsActive  bool      `json:"isActive"`
+	Settings  *Settings `json:"settings,omitempty"`
+}
+
+func NewUserPublicProfile(user *User) UserPublicProfile {
+	return UserPublicProfile{
+		Username: user.Username,
+		Name:     user.Name,
+		PhotoUrl: user.PhotoUrl,
+		Banner:   user.Banner,
+		Score:    user.Score,
+	}
+}
+
+func NewUserPrivateProfile(user *User, settings *Settings) UserPrivateProfile {
+	return UserPrivateProfile{
+		ID:        user.ID,
+		Username:  user.Username,
+		Name:      user.Name,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Email:     user.Email,
+		PhotoUrl:  user.PhotoUrl,
+		Banner:    user.Banner,
+		Score:     user.Score,
+		CreatedAt: user.CreatedAt,
+		LastLogin: user.LastLogin,
+		IsActive:  user.IsActive,
+		Settings:  settings,
+
+		PendingEmail: user.PendingEmail,
+	}
+}
+
+// UpdateProfileRequest é o corpo do PATCH /me. Campos ausentes (nil) não são
+// alterados; Name é separado em FirstName/LastName, que têm precedência.
+// PhotoUrl e Banner aceitam apenas presets ou "" para remover. Currency é a
+// moeda padrão (ISO 4217) de novas carteiras e dos relatórios consolidados,
+// guardada em Settings.
+type UpdateProfileRequest struct {
+	Name      *string `json:"name"`
+	FirstName *string `json:"firstName"`
+	LastName  *string `json:"lastName"`
+	Username  *string `json:"username"`
+	PhotoUrl  *string `json:"photoUrl"`
+	Banner    *string `json:"banner"`
+	Currency  *string `json:"currency"`
+}
+
+// Availability é a resposta de GET /users/availability para cada campo
+// consultado. Code é o código de erro que o cadastro retornaria.
+type Availability struct {
+	Available bool   `json:"available"`
+	Code      string `json:"code,omitempty"`
+}
@@ -2,15 +2,41 @@ package model
 
 import "time"
 
+// User espelha db_nexa.tb_user. Nunca deve ser a resposta de uma rota: use
+// UserPublicProfile ou UserPrivateProfile. O hash da senha não é serializado.
 type User struct {
 	ID        string    `json:"id,omitempty"`
 	Name      string    `json:"name,omitempty"`
+	FirstName string    `json:"firstName,omitempty"`
+	LastName  string    `json:"lastName,omitempty"`
 	Username  string    `json:"username,omitempty"`
 	Email     string    `json:"email,omitempty"`
-	Password  string    `json:"password,omitempty"`
+	Password  string    `json:"-"`
 	PhotoUrl  string    `json:"photo_url,omitempty"`
+	Banner    string    `json:"banner,omitempty"`
 	Score     int       `json:"score,omitempty"`
 	CreatedAt time.Time `json:"createdAt"`
 	LastLogin time.Time `json:"lastLogin"`
 	IsActive  bool      `json:"isActive"`
+
+	PendingEmail      string     `json:"-"`
+	SessionsRevokedAt *time.Time `json:"-"`
+	DeactivatedAt     *time.Time `json:"-"`
+}
+
+// RegisterUserRequest é o corpo de POST /user. Clientes antigos enviam só
+// Name, que é separado em FirstName e LastName.
+type RegisterUserRequest struct {
+	Name      string `json:"name"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+	Username  string `json:"username"`
+	Email     string `json:"email"`
+	Password  string `json:"password"`
+}
+
+// LoginRequest é o corpo de POST /auth/login.
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
 }
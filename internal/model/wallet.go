@@ -0,0 +1,15 @@
+package model
+
+import (
+	"nexa/internal/money"
+	"time"
+)
+
+type Wallet struct {
+	ID        string       `json:"id"`
+	UserID    string       `json:"-"`
+	Name      string       `json:"name"`
+	Currency  string       `json:"currency"`
+	Total     money.Amount `json:"total"`
+	CreatedAt time.Time    `json:"createdAt"`
+}
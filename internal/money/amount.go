@@ -0,0 +1,184 @@
+// Package money representa valores monetários sem ponto flutuante.
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Amount é um valor em centavos. As colunas NUMERIC(14, 2) do banco cabem com
+// folga em um int64.
+type Amount int64
+
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// Parse lê o formato canônico, com ponto decimal e sem separador de milhar
+// ("1234.5", "-0.99"), que é o que o banco e a API usam. Mais de duas casas
+// decimais só são aceitas se forem zeros.
+func Parse(s string) (Amount, error) {
+	return ParseLocalized(s, '.')
+}
+
+// ParseLocalized lê valores como aparecem em extratos: separador decimal
+// informado, separador de milhar opcional, símbolo de moeda, sinal no início
+// ou no fim ("1.234,56-") e parênteses para negativos ("(12,00)").
+func ParseLocalized(s string, decimalSeparator byte) (Amount, error) {
+	s = strings.TrimSpace(s)
+	for _, symbol := range []string{"R$", "US$", "$", "€"} {
+		s = strings.ReplaceAll(s, symbol, "")
+	}
+	// Espaços, inclusive o não separável que planilhas usam como milhar.
+	s = strings.NewReplacer(" ", "", "\u00a0", "").Replace(s)
+
+	negative := false
+	switch {
+	case strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")"):
+		negative, s = true, s[1:len(s)-1]
+	case strings.HasSuffix(s, "-"):
+		negative, s = true, s[:len(s)-1]
+	case strings.HasSuffix(s, "D"), strings.HasSuffix(s, "d"):
+		// "150,00 D" (débito) em alguns extratos.
+		negative, s = true, s[:len(s)-1]
+	case strings.HasSuffix(s, "C"), strings.HasSuffix(s, "c"):
+		s = s[:len(s)-1]
+	}
+	if strings.HasPrefix(s, "-") {
+		negative, s = !negative, s[1:]
+	} else if strings.HasPrefix(s, "+") {
+		s = s[1:]
+	}
+	if s == "" {
+		return 0, ErrInvalidAmount
+	}
+
+	thousands := byte(',')
+	if decimalSeparator == ',' {
+		thousands = '.'
+	}
+
+	whole, fraction, _ := strings.Cut(s, string(decimalSeparator))
+	whole, ok := ungroup(whole, thousands)
+	if !ok {
+		return 0, ErrInvalidAmount
+	}
+	if whole == "" {
+		whole = "0"
+	}
+
+	if len(fraction) > 2 {
+		if strings.Trim(fraction[2:], "0") != "" {
+			return 0, ErrInvalidAmount
+		}
+		fraction = fraction[:2]
+	}
+	fraction += strings.Repeat("0", 2-len(fraction))
+
+	for _, part := range []string{whole, fraction} {
+		for _, r := range part {
+			if r < '0' || r > '9' {
+				return 0, ErrInvalidAmount
+			}
+		}
+	}
+
+	cents, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidAmount
+	}
+	if negative {
+		cents = -cents
+	}
+
+	return Amount(cents), nil
+}
+
+// ungroup remove o separador de milhar exigindo grupos de três dígitos depois
+// do primeiro ("1.234.567"); "1,23" lido com ponto decimal não vira 123.
+func ungroup(whole string, thousands byte) (string, bool) {
+	groups := strings.Split(whole, string(thousands))
+	if len(groups) == 1 {
+		return whole, true
+	}
+
+	if len(groups[0]) == 0 || len(groups[0]) > 3 {
+		return "", false
+	}
+	for _, group := range groups[1:] {
+		if len(group) != 3 {
+			return "", false
+		}
+	}
+
+	return strings.Join(groups, ""), true
+}
+
+// String devolve o formato canônico com duas casas ("-1234.50").
+func (a Amount) String() string {
+	sign := ""
+	cents := int64(a)
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+// Format devolve o valor com o separador decimal pedido e sem separador de
+// milhar, como planilhas em pt-BR esperam ("1234,50").
+func (a Amount) Format(decimalSeparator byte) string {
+	return strings.Replace(a.String(), ".", string(decimalSeparator), 1)
+}
+
+func (a Amount) Abs() Amount {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+// MarshalJSON escreve o valor como número JSON exato (12.30).
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON aceita número ou string no formato canônico.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	parsed, err := Parse(strings.Trim(string(data), `"`))
+	if err != nil {
+		return err
+	}
+
+	*a = parsed
+	return nil
+}
+
+// Scan lê colunas NUMERIC, que o pgx entrega como texto.
+func (a *Amount) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*a = 0
+		return nil
+	case string:
+		parsed, err := Parse(v)
+		if err != nil {
+			return fmt.Errorf("cannot scan %q into money.Amount: %w", v, err)
+		}
+		*a = parsed
+		return nil
+	case []byte:
+		return a.Scan(string(v))
+	case int64:
+		*a = Amount(v * 100)
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into money.Amount", src)
+	}
+}
+
+// Value grava como texto para o NUMERIC não passar por float.
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
@@ -0,0 +1,73 @@
+package money
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseLocalized(t *testing.T) {
+	tests := []struct {
+		input   string
+		decimal byte
+		want    Amount
+		err     error
+	}{
+		{"1234,56", ',', 123456, nil},
+		{"1.234,56", ',', 123456, nil},
+		{"1.234.567,89", ',', 123456789, nil},
+		{"1,234.56", '.', 123456, nil},
+		{"1234.5", '.', 123450, nil},
+		{"-0.99", '.', -99, nil},
+		{"R$ 1.234,56", ',', 123456, nil},
+		{"1 234,56", ',', 123456, nil},
+		{"1.234,56-", ',', -123456, nil},
+		{"(12,00)", ',', -1200, nil},
+		{"150,00 D", ',', -15000, nil},
+		{"150,00 C", ',', 15000, nil},
+		{"+10", ',', 1000, nil},
+		{",5", ',', 50, nil},
+		{"1,500", '.', 150000, nil},
+		{"1.500", ',', 150000, nil},
+		{"10,000", ',', 1000, nil},
+		{"10,001", ',', 0, ErrInvalidAmount},
+
+		{"1,23", '.', 0, ErrInvalidAmount},
+		{"1.23,45", ',', 0, ErrInvalidAmount},
+		{"1.2345,00", ',', 0, ErrInvalidAmount},
+		{"1234.567,00", ',', 0, ErrInvalidAmount},
+		{".234,00", ',', 0, ErrInvalidAmount},
+		{"1.234.", ',', 0, ErrInvalidAmount},
+		{"12a,00", ',', 0, ErrInvalidAmount},
+		{"", ',', 0, ErrInvalidAmount},
+		{"-", ',', 0, ErrInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseLocalized(tt.input, tt.decimal)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("ParseLocalized(%q, %q): err = %v, want %v", tt.input, tt.decimal, err, tt.err)
+			continue
+		}
+		if err == nil && got != tt.want {
+			t.Errorf("ParseLocalized(%q, %q) = %s, want %s", tt.input, tt.decimal, got, tt.want)
+		}
+	}
+}
+
+func TestAmountString(t *testing.T) {
+	tests := []struct {
+		amount Amount
+		want   string
+	}{
+		{0, "0.00"},
+		{5, "0.05"},
+		{-99, "-0.99"},
+		{123450, "1234.50"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.amount.String(); got != tt.want {
+			t.Errorf("Amount(%d).String() = %q, want %q", int64(tt.amount), got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package recurrence
+
+import "time"
+
+// fixedHolidays são os feriados nacionais de data fixa, em que os bancos não
+// abrem. Feriados estaduais e municipais não entram.
+var fixedHolidays = map[[2]int]bool{
+	{1, 1}:   true, // Confraternização Universal
+	{4, 21}:  true, // Tiradentes
+	{5, 1}:   true, // Dia do Trabalho
+	{9, 7}:   true, // Independência
+	{10, 12}: true, // Nossa Senhora Aparecida
+	{11, 2}:  true, // Finados
+	{11, 15}: true, // Proclamação da República
+	{11, 20}: true, // Consciência Negra
+	{12, 25}: true, // Natal
+}
+
+// IsBusinessDay diz se date é dia útil bancário: não é fim de semana, feriado
+// nacional, Carnaval, Sexta-feira Santa nem Corpus Christi.
+func IsBusinessDay(date time.Time) bool {
+	if weekday := date.Weekday(); weekday == time.Saturday || weekday == time.Sunday {
+		return false
+	}
+	if fixedHolidays[[2]int{int(date.Month()), date.Day()}] {
+		return false
+	}
+
+	easter := easterSunday(date.Year())
+	switch days := int(truncate(date).Sub(easter).Hours() / 24); days {
+	case -48, -47, -2, 60: // Carnaval (segunda e terça), Sexta-feira Santa, Corpus Christi
+		return false
+	}
+
+	return true
+}
+
+func nextBusinessDay(date time.Time, direction int) time.Time {
+	for !IsBusinessDay(date) {
+		date = date.AddDate(0, 0, direction)
+	}
+	return date
+}
+
+// easterSunday usa o algoritmo de Meeus/Jones/Butcher (calendário gregoriano).
+func easterSunday(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,217 @@
+// Package recurrence calcula as datas de lançamentos recorrentes (salário,
+// aluguel, assinaturas) a partir de uma regra.
+package recurrence
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	Daily   = "daily"
+	Weekly  = "weekly"
+	Monthly = "monthly"
+	Yearly  = "yearly"
+)
+
+// Ajuste para dias não úteis (fins de semana e feriados bancários).
+const (
+	AdjustNone              = "none"
+	AdjustFollowing         = "following"
+	AdjustPreceding         = "preceding"
+	AdjustModifiedFollowing = "modified_following"
+)
+
+const maxInterval = 366
+
+var ErrInvalidRule = errors.New("invalid recurrence rule")
+
+// Rule descreve a repetição a partir de StartDate, a cada Interval unidades
+// de Frequency. Em Monthly, DayOfMonth (padrão: o dia de StartDate) acima do
+// último dia do mês cai no último dia; o mesmo vale para 29/02 em Yearly. A
+// série termina em EndDate (inclusivo) ou após Count ocorrências, contando as
+// puladas.
+type Rule struct {
+	Frequency   string     `json:"frequency"`
+	Interval    int        `json:"interval"`
+	DayOfMonth  int        `json:"dayOfMonth,omitempty"`
+	BusinessDay string     `json:"businessDay"`
+	StartDate   time.Time  `json:"startDate"`
+	EndDate     *time.Time `json:"endDate,omitempty"`
+	Count       int        `json:"count,omitempty"`
+}
+
+// Occurrence é a n-ésima ocorrência (a partir de 0). Date é a data nominal,
+// que identifica a ocorrência; PostingDate é Date ajustada para dia útil.
+type Occurrence struct {
+	Index       int       `json:"index"`
+	Date        time.Time `json:"date"`
+	PostingDate time.Time `json:"postingDate"`
+}
+
+// Normalize preenche os padrões e valida a regra.
+func (r *Rule) Normalize() error {
+	if r.Interval == 0 {
+		r.Interval = 1
+	}
+	if r.BusinessDay == "" {
+		r.BusinessDay = AdjustNone
+	}
+	r.StartDate = truncate(r.StartDate)
+	if r.EndDate != nil {
+		end := truncate(*r.EndDate)
+		r.EndDate = &end
+	}
+
+	switch r.Frequency {
+	case Daily, Weekly, Yearly:
+		if r.DayOfMonth != 0 {
+			return ErrInvalidRule
+		}
+	case Monthly:
+		if r.DayOfMonth < 0 || r.DayOfMonth > 31 {
+			return ErrInvalidRule
+		}
+	default:
+		return ErrInvalidRule
+	}
+
+	switch r.BusinessDay {
+	case AdjustNone, AdjustFollowing, AdjustPreceding, AdjustModifiedFollowing:
+	default:
+		return ErrInvalidRule
+	}
+
+	if r.Interval < 1 || r.Interval > maxInterval || r.Count < 0 || r.StartDate.IsZero() {
+		return ErrInvalidRule
+	}
+	if r.EndDate != nil && r.EndDate.Before(r.StartDate) {
+		return ErrInvalidRule
+	}
+
+	return nil
+}
+
+// At devolve a ocorrência de índice n, ou false se a série já terminou.
+func (r *Rule) At(n int) (Occurrence, bool) {
+	if n < 0 || (r.Count > 0 && n >= r.Count) {
+		return Occurrence{}, false
+	}
+
+	date := r.nominal(n)
+	if r.EndDate != nil && date.After(*r.EndDate) {
+		return Occurrence{}, false
+	}
+
+	return Occurrence{Index: n, Date: date, PostingDate: r.Adjust(date)}, true
+}
+
+// Find devolve a ocorrência cuja data nominal é date.
+func (r *Rule) Find(date time.Time) (Occurrence, bool) {
+	date = truncate(date)
+	for n := r.estimate(date); ; n++ {
+		occurrence, ok := r.At(n)
+		if !ok || occurrence.Date.After(date) {
+			return Occurrence{}, false
+		}
+		if occurrence.Date.Equal(date) {
+			return occurrence, true
+		}
+	}
+}
+
+// Between devolve as ocorrências com data nominal em [from, to], até limit.
+func (r *Rule) Between(from, to time.Time, limit int) []Occurrence {
+	from, to = truncate(from), truncate(to)
+
+	occurrences := []Occurrence{}
+	for n := r.estimate(from); len(occurrences) < limit; n++ {
+		occurrence, ok := r.At(n)
+		if !ok || occurrence.Date.After(to) {
+			break
+		}
+		if !occurrence.Date.Before(from) {
+			occurrences = append(occurrences, occurrence)
+		}
+	}
+
+	return occurrences
+}
+
+// Adjust move date para um dia útil conforme BusinessDay.
+func (r *Rule) Adjust(date time.Time) time.Time {
+	switch r.BusinessDay {
+	case AdjustFollowing:
+		return nextBusinessDay(date, 1)
+	case AdjustPreceding:
+		return nextBusinessDay(date, -1)
+	case AdjustModifiedFollowing:
+		// Segue para o próximo dia útil, a menos que isso mude o mês.
+		if next := nextBusinessDay(date, 1); next.Month() == date.Month() {
+			return next
+		}
+		return nextBusinessDay(date, -1)
+	default:
+		return date
+	}
+}
+
+func (r *Rule) nominal(n int) time.Time {
+	start := r.StartDate
+	step := n * r.Interval
+
+	switch r.Frequency {
+	case Daily:
+		return start.AddDate(0, 0, step)
+	case Weekly:
+		return start.AddDate(0, 0, 7*step)
+	case Yearly:
+		return clampedDate(start.Year()+step, start.Month(), start.Day())
+	default:
+		day := r.DayOfMonth
+		if day == 0 {
+			day = start.Day()
+		}
+		// Com DayOfMonth antes do dia de início, a série começa no mês seguinte.
+		if clampedDate(start.Year(), start.Month(), day).Before(start) {
+			step++
+		}
+		return clampedDate(start.Year(), start.Month()+time.Month(step), day)
+	}
+}
+
+// estimate é um índice que não passa da primeira ocorrência em ou após date,
+// para não percorrer a série desde o início.
+func (r *Rule) estimate(date time.Time) int {
+	if !date.After(r.StartDate) {
+		return 0
+	}
+
+	var n int
+	switch r.Frequency {
+	case Daily:
+		n = int(date.Sub(r.StartDate).Hours()/24) / r.Interval
+	case Weekly:
+		n = int(date.Sub(r.StartDate).Hours()/24) / (7 * r.Interval)
+	case Monthly:
+		n = ((date.Year()-r.StartDate.Year())*12 + int(date.Month()-r.StartDate.Month())) / r.Interval
+	case Yearly:
+		n = (date.Year() - r.StartDate.Year()) / r.Interval
+	}
+
+	// A conta acima pode passar em uma unidade (mês seguinte, fim de mês).
+	return max(n-1, 0)
+}
+
+// clampedDate monta a data, usando o último dia do mês quando day não existe
+// nele (31 em abril, 29/02 fora de ano bissexto).
+func clampedDate(year int, month time.Month, day int) time.Time {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1).Day()
+
+	return first.AddDate(0, 0, min(day, last)-1)
+}
+
+func truncate(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+}
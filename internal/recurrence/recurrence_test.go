@@ -0,0 +1,223 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+)
+
+func date(t *testing.T, value string) time.Time {
+	t.Helper()
+
+	parsed, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func normalized(t *testing.T, rule Rule) *Rule {
+	t.Helper()
+
+	if err := rule.Normalize(); err != nil {
+		t.Fatalf("Normalize(%+v): %v", rule, err)
+	}
+	return &rule
+}
+
+func TestNominalDates(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  Rule
+		dates []string
+	}{
+		{
+			name:  "monthly on day 31 through february",
+			rule:  Rule{Frequency: Monthly, StartDate: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)},
+			dates: []string{"2024-01-31", "2024-02-29", "2024-03-31", "2024-04-30", "2024-05-31"},
+		},
+		{
+			name:  "monthly on day 31 in a common year",
+			rule:  Rule{Frequency: Monthly, DayOfMonth: 31, StartDate: time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC)},
+			dates: []string{"2023-01-31", "2023-02-28", "2023-03-31"},
+		},
+		{
+			name:  "day of month earlier than the start day",
+			rule:  Rule{Frequency: Monthly, DayOfMonth: 5, StartDate: time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)},
+			dates: []string{"2024-02-05", "2024-03-05", "2024-04-05"},
+		},
+		{
+			name:  "day of month earlier than the start day every two months",
+			rule:  Rule{Frequency: Monthly, Interval: 2, DayOfMonth: 5, StartDate: time.Date(2024, 11, 20, 0, 0, 0, 0, time.UTC)},
+			dates: []string{"2024-12-05", "2025-02-05", "2025-04-05"},
+		},
+		{
+			name:  "clamped day of month is not before the start",
+			rule:  Rule{Frequency: Monthly, DayOfMonth: 31, StartDate: time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC)},
+			dates: []string{"2024-02-29", "2024-03-31", "2024-04-30"},
+		},
+		{
+			name:  "yearly from 29 february",
+			rule:  Rule{Frequency: Yearly, StartDate: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+			dates: []string{"2024-02-29", "2025-02-28", "2026-02-28", "2027-02-28", "2028-02-29"},
+		},
+		{
+			name:  "weekly every two weeks",
+			rule:  Rule{Frequency: Weekly, Interval: 2, StartDate: time.Date(2024, 12, 20, 0, 0, 0, 0, time.UTC)},
+			dates: []string{"2024-12-20", "2025-01-03", "2025-01-17"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := normalized(t, tt.rule)
+			for n, want := range tt.dates {
+				occurrence, ok := rule.At(n)
+				if !ok {
+					t.Fatalf("At(%d): series ended", n)
+				}
+				if got := occurrence.Date.Format(time.DateOnly); got != want {
+					t.Errorf("At(%d) = %s, want %s", n, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestAdjust(t *testing.T) {
+	tests := []struct {
+		name       string
+		adjustment string
+		date       string
+		want       string
+	}{
+		{"none keeps weekends", AdjustNone, "2024-08-31", "2024-08-31"},
+		{"following over carnaval", AdjustFollowing, "2024-02-10", "2024-02-14"},
+		{"following over christmas", AdjustFollowing, "2024-12-25", "2024-12-26"},
+		{"preceding across tiradentes and good friday", AdjustPreceding, "2025-04-21", "2025-04-17"},
+		{"preceding across new year", AdjustPreceding, "2023-01-01", "2022-12-30"},
+		{"modified following at month end", AdjustModifiedFollowing, "2024-08-31", "2024-08-30"},
+		{"modified following at month end on sunday", AdjustModifiedFollowing, "2025-11-30", "2025-11-28"},
+		{"modified following inside the month", AdjustModifiedFollowing, "2024-06-01", "2024-06-03"},
+		{"modified following on a business day", AdjustModifiedFollowing, "2024-07-31", "2024-07-31"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := &Rule{BusinessDay: tt.adjustment}
+			if got := rule.Adjust(date(t, tt.date)).Format(time.DateOnly); got != tt.want {
+				t.Errorf("Adjust(%s) = %s, want %s", tt.date, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMovableHolidays(t *testing.T) {
+	holidays := map[int][]string{
+		// Carnaval (segunda e terça), Sexta-feira Santa e Corpus Christi.
+		2023: {"2023-02-20", "2023-02-21", "2023-04-07", "2023-06-08"},
+		2024: {"2024-02-12", "2024-02-13", "2024-03-29", "2024-05-30"},
+		2025: {"2025-03-03", "2025-03-04", "2025-04-18", "2025-06-19"},
+		2026: {"2026-02-16", "2026-02-17", "2026-04-03", "2026-06-04"},
+		2038: {"2038-03-08", "2038-03-09", "2038-04-23", "2038-06-24"},
+	}
+
+	for year, dates := range holidays {
+		for _, value := range dates {
+			day := date(t, value)
+			if IsBusinessDay(day) {
+				t.Errorf("%d: %s should be a holiday", year, value)
+			}
+			// Os dias vizinhos de Sexta-feira Santa e Corpus Christi são úteis.
+			if day.Weekday() == time.Friday && !IsBusinessDay(day.AddDate(0, 0, -1)) {
+				t.Errorf("%d: day before %s should be a business day", year, value)
+			}
+		}
+	}
+
+	if !IsBusinessDay(date(t, "2024-02-14")) {
+		t.Error("Ash Wednesday should be a business day")
+	}
+}
+
+func TestCount(t *testing.T) {
+	rule := normalized(t, Rule{Frequency: Monthly, Count: 3, StartDate: time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)})
+
+	if _, ok := rule.At(2); !ok {
+		t.Error("At(2) should exist")
+	}
+	if _, ok := rule.At(3); ok {
+		t.Error("At(3) should be past Count")
+	}
+
+	// Uma ocorrência pulada (exceção no índice 1) continua contando: a série
+	// termina no índice 2, não se estende até abril.
+	occurrences := rule.Between(date(t, "2024-02-01"), date(t, "2024-12-31"), 100)
+	if len(occurrences) != 2 {
+		t.Fatalf("got %d occurrences, want 2", len(occurrences))
+	}
+	if occurrences[0].Index != 1 || occurrences[1].Index != 2 {
+		t.Errorf("indexes = %d, %d, want 1, 2", occurrences[0].Index, occurrences[1].Index)
+	}
+	if _, ok := rule.Find(date(t, "2024-04-10")); ok {
+		t.Error("Find should not return an occurrence past Count")
+	}
+}
+
+func TestEndDate(t *testing.T) {
+	end := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	rule := normalized(t, Rule{Frequency: Monthly, StartDate: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), EndDate: &end})
+
+	if occurrence, ok := rule.At(2); !ok || !occurrence.Date.Equal(end) {
+		t.Errorf("At(2) = %v, %v, want the end date", occurrence.Date, ok)
+	}
+	if _, ok := rule.At(3); ok {
+		t.Error("At(3) should be past EndDate")
+	}
+}
+
+func TestFindAndBetweenMatchAt(t *testing.T) {
+	rules := []Rule{
+		{Frequency: Daily, Interval: 3, StartDate: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{Frequency: Weekly, Interval: 2, StartDate: time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)},
+		{Frequency: Monthly, StartDate: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)},
+		{Frequency: Monthly, DayOfMonth: 3, StartDate: time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)},
+		{Frequency: Monthly, Interval: 5, DayOfMonth: 3, StartDate: time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)},
+		{Frequency: Monthly, Interval: 7, DayOfMonth: 30, BusinessDay: AdjustModifiedFollowing, StartDate: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{Frequency: Yearly, StartDate: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{Frequency: Yearly, Interval: 3, StartDate: time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, input := range rules {
+		rule := normalized(t, input)
+		for _, n := range []int{0, 1, 2, 11, 12, 13, 59, 100, 365, 999, 1000, 1001} {
+			want, ok := rule.At(n)
+			if !ok {
+				t.Fatalf("%s: At(%d) ended", rule.Frequency, n)
+			}
+
+			found, ok := rule.Find(want.Date)
+			if !ok || found != want {
+				t.Errorf("%s/%d: Find(%s) = %+v, %v, want %+v", rule.Frequency, rule.Interval, want.Date.Format(time.DateOnly), found, ok, want)
+			}
+
+			between := rule.Between(want.Date, want.Date, 10)
+			if len(between) != 1 || between[0] != want {
+				t.Errorf("%s/%d: Between(%s) = %+v, want [%+v]", rule.Frequency, rule.Interval, want.Date.Format(time.DateOnly), between, want)
+			}
+
+			// Um dia antes da ocorrência não é nenhuma data da série, exceto
+			// na diária.
+			if rule.Frequency != Daily || rule.Interval > 1 {
+				if _, ok := rule.Find(want.Date.AddDate(0, 0, -1)); ok {
+					t.Errorf("%s/%d: Find(day before %s) should fail", rule.Frequency, rule.Interval, want.Date.Format(time.DateOnly))
+				}
+			}
+
+			next, _ := rule.At(n + 1)
+			window := rule.Between(want.Date.AddDate(0, 0, -1), next.Date, 10)
+			if len(window) != 2 || window[0] != want || window[1] != next {
+				t.Errorf("%s/%d: Between around index %d = %+v", rule.Frequency, rule.Interval, n, window)
+			}
+		}
+	}
+}
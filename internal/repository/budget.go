@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"nexa/internal/metrics"
+	"nexa/internal/model"
+	"nexa/internal/tracing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type BudgetRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewBudgetRepository(db *pgxpool.Pool) *BudgetRepository {
+	return &BudgetRepository{
+		db: db,
+	}
+}
+
+// FindByMonth lista os orçamentos da carteira no mês de month. O gasto é
+// somado das despesas do mês (da categoria, quando o orçamento tem uma), sem
+// transferências, em vez de lido de current_spent.
+func (r *BudgetRepository) FindByMonth(ctx context.Context, walletID string, month time.Time) ([]model.Budget, error) {
+	defer metrics.ObserveQuery("BudgetRepository", "FindByMonth")()
+	ctx, span := tracing.Start(ctx, "BudgetRepository.FindByMonth")
+	defer span.End()
+
+	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	rows, err := r.db.Query(ctx, `
+		SELECT b.id, b.wallet_id, b.category_id, b.reference_month, b.total_limit,
+			COALESCE((
+				SELECT SUM(t.amount)
+				FROM db_nexa.tb_transaction t
+				WHERE t.wallet_id = b.wallet_id
+					AND t.type = 'expense'
+					AND t.transfer_id IS NULL
+					AND t.date >= $2 AND t.date < $3
+					AND (b.category_id IS NULL OR t.category_id = b.category_id)
+			), 0),
+			b.saving_goal, b.created_at
+		FROM db_nexa.tb_budget b
+		WHERE b.wallet_id = $1 AND b.reference_month >= $2 AND b.reference_month < $3
+		ORDER BY b.category_id NULLS FIRST, b.created_at
+	`, walletID, start, start.AddDate(0, 1, 0))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list budgets: %w", err)
+	}
+	defer rows.Close()
+
+	budgets := []model.Budget{}
+	for rows.Next() {
+		var budget model.Budget
+		if err := rows.Scan(&budget.ID, &budget.WalletID, &budget.CategoryID, &budget.ReferenceMonth, &budget.TotalLimit,
+			&budget.CurrentSpent, &budget.SavingGoal, &budget.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan budget: %w", err)
+		}
+		budgets = append(budgets, budget)
+	}
+
+	return budgets, rows.Err()
+}
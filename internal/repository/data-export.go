@@ -0,0 +1,420 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"nexa/internal/metrics"
+	"nexa/internal/model"
+	"nexa/internal/tracing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DataExportHeartbeatInterval é a frequência com que o worker avisa que ainda
+// está montando um pedido. Sem heartbeat por staleExportAfter, o pedido é
+// considerado abandonado (worker reiniciado no meio) e volta à fila, não
+// importa quanto tempo a montagem leve.
+const (
+	DataExportHeartbeatInterval = time.Minute
+	staleExportAfter            = 5 * DataExportHeartbeatInterval
+)
+
+const dataExportColumns = "id, user_id, status, storage_key, download_url, error, created_at, started_at, completed_at, expires_at"
+
+// ErrDataExportInProgress indica que o usuário já tem um pedido na fila.
+var ErrDataExportInProgress = errors.New("data export already in progress")
+
+type DataExportRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewDataExportRepository(db *pgxpool.Pool) *DataExportRepository {
+	return &DataExportRepository{
+		db: db,
+	}
+}
+
+func scanDataExport(row pgx.Row) (*model.DataExport, error) {
+	var export model.DataExport
+	err := row.Scan(
+		&export.ID,
+		&export.UserID,
+		&export.Status,
+		&export.StorageKey,
+		&export.DownloadURL,
+		&export.Error,
+		&export.CreatedAt,
+		&export.StartedAt,
+		&export.CompletedAt,
+		&export.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &export, nil
+}
+
+func (r *DataExportRepository) Create(ctx context.Context, userID string) (*model.DataExport, error) {
+	defer metrics.ObserveQuery("DataExportRepository", "Create")()
+	ctx, span := tracing.Start(ctx, "DataExportRepository.Create")
+	defer span.End()
+
+	export, err := scanDataExport(r.db.QueryRow(ctx,
+		"INSERT INTO db_nexa.tb_data_export (user_id) VALUES ($1) RETURNING "+dataExportColumns, userID))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, ErrDataExportInProgress
+		}
+		return nil, fmt.Errorf("failed to create data export: %w", err)
+	}
+
+	return export, nil
+}
+
+// FindActiveByUserID devolve o pedido ainda na fila, em processamento ou com
+// link válido, para que pedidos repetidos não gerem arquivos novos.
+func (r *DataExportRepository) FindActiveByUserID(ctx context.Context, userID string) (*model.DataExport, error) {
+	defer metrics.ObserveQuery("DataExportRepository", "FindActiveByUserID")()
+	ctx, span := tracing.Start(ctx, "DataExportRepository.FindActiveByUserID")
+	defer span.End()
+
+	export, err := scanDataExport(r.db.QueryRow(ctx, `
+		SELECT `+dataExportColumns+`
+		FROM db_nexa.tb_data_export
+		WHERE user_id = $1
+		  AND (status IN ('pending', 'processing') OR (status = 'ready' AND expires_at > now()))
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find data export: %w", err)
+	}
+
+	return export, nil
+}
+
+// FindByID só encontra pedidos do próprio usuário.
+func (r *DataExportRepository) FindByID(ctx context.Context, id, userID string) (*model.DataExport, error) {
+	defer metrics.ObserveQuery("DataExportRepository", "FindByID")()
+	ctx, span := tracing.Start(ctx, "DataExportRepository.FindByID")
+	defer span.End()
+
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, nil
+	}
+
+	export, err := scanDataExport(r.db.QueryRow(ctx,
+		"SELECT "+dataExportColumns+" FROM db_nexa.tb_data_export WHERE id = $1 AND user_id = $2", id, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find data export: %w", err)
+	}
+
+	return export, nil
+}
+
+// ClaimNext marca como processing o pedido pendente mais antigo (ou um
+// abandonado em processing) e o devolve. SKIP LOCKED permite várias réplicas
+// consumindo a mesma fila. Retorna nil quando não há trabalho.
+func (r *DataExportRepository) ClaimNext(ctx context.Context) (*model.DataExport, error) {
+	defer metrics.ObserveQuery("DataExportRepository", "ClaimNext")()
+	ctx, span := tracing.Start(ctx, "DataExportRepository.ClaimNext")
+	defer span.End()
+
+	export, err := scanDataExport(r.db.QueryRow(ctx, `
+		UPDATE db_nexa.tb_data_export
+		SET status = 'processing', started_at = now(), heartbeat_at = now()
+		WHERE id = (
+			SELECT id FROM db_nexa.tb_data_export
+			WHERE status = 'pending' OR (status = 'processing' AND COALESCE(heartbeat_at, started_at) < $1)
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+dataExportColumns, time.Now().Add(-staleExportAfter)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to claim data export: %w", err)
+	}
+
+	return export, nil
+}
+
+// Heartbeat renova o pedido em processamento. O started_at do ClaimNext
+// identifica a posse: owned é false se o pedido foi reivindicado por outro
+// worker (ou saiu de processing) e a montagem deve parar.
+func (r *DataExportRepository) Heartbeat(ctx context.Context, export *model.DataExport) (owned bool, err error) {
+	defer metrics.ObserveQuery("DataExportRepository", "Heartbeat")()
+	ctx, span := tracing.Start(ctx, "DataExportRepository.Heartbeat")
+	defer span.End()
+
+	tag, err := r.db.Exec(ctx, `
+		UPDATE db_nexa.tb_data_export
+		SET heartbeat_at = now()
+		WHERE id = $1 AND status = 'processing' AND started_at = $2
+	`, export.ID, export.StartedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to renew data export: %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *DataExportRepository) MarkReady(ctx context.Context, export *model.DataExport) error {
+	defer metrics.ObserveQuery("DataExportRepository", "MarkReady")()
+	ctx, span := tracing.Start(ctx, "DataExportRepository.MarkReady")
+	defer span.End()
+
+	_, err := r.db.Exec(ctx, `
+		UPDATE db_nexa.tb_data_export
+		SET status = 'ready', storage_key = $2, download_url = $3, expires_at = $4, completed_at = now(), error = ''
+		WHERE id = $1
+	`, export.ID, export.StorageKey, export.DownloadURL, export.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to mark data export as ready: %w", err)
+	}
+
+	return nil
+}
+
+func (r *DataExportRepository) MarkFailed(ctx context.Context, id string, cause error) error {
+	defer metrics.ObserveQuery("DataExportRepository", "MarkFailed")()
+	ctx, span := tracing.Start(ctx, "DataExportRepository.MarkFailed")
+	defer span.End()
+
+	_, err := r.db.Exec(ctx,
+		"UPDATE db_nexa.tb_data_export SET status = 'failed', error = $2, completed_at = now() WHERE id = $1",
+		id, cause.Error())
+	if err != nil {
+		return fmt.Errorf("failed to mark data export as failed: %w", err)
+	}
+
+	return nil
+}
+
+// FindExpired lista até limit pedidos prontos cujo link já venceu.
+func (r *DataExportRepository) FindExpired(ctx context.Context, limit int) ([]model.DataExport, error) {
+	defer metrics.ObserveQuery("DataExportRepository", "FindExpired")()
+	ctx, span := tracing.Start(ctx, "DataExportRepository.FindExpired")
+	defer span.End()
+
+	return r.list(ctx, `
+		SELECT `+dataExportColumns+`
+		FROM db_nexa.tb_data_export
+		WHERE status = 'ready' AND expires_at <= now()
+		ORDER BY expires_at
+		LIMIT $1
+	`, limit)
+}
+
+// MarkExpired apaga o link de um pedido cujo arquivo já saiu do storage.
+func (r *DataExportRepository) MarkExpired(ctx context.Context, id string) error {
+	defer metrics.ObserveQuery("DataExportRepository", "MarkExpired")()
+	ctx, span := tracing.Start(ctx, "DataExportRepository.MarkExpired")
+	defer span.End()
+
+	_, err := r.db.Exec(ctx,
+		"UPDATE db_nexa.tb_data_export SET status = 'expired', storage_key = '', download_url = '' WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to mark data export as expired: %w", err)
+	}
+
+	return nil
+}
+
+// FindStorageKeysByUserID lista os arquivos de exportação ainda no storage,
+// usados pela exclusão de conta antes de a cascata apagar os pedidos.
+func (r *DataExportRepository) FindStorageKeysByUserID(ctx context.Context, userID string) ([]string, error) {
+	defer metrics.ObserveQuery("DataExportRepository", "FindStorageKeysByUserID")()
+	ctx, span := tracing.Start(ctx, "DataExportRepository.FindStorageKeysByUserID")
+	defer span.End()
+
+	rows, err := r.db.Query(ctx,
+		"SELECT storage_key FROM db_nexa.tb_data_export WHERE user_id = $1 AND storage_key <> ''", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find data export keys: %w", err)
+	}
+
+	keys, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan data export keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+func (r *DataExportRepository) list(ctx context.Context, query string, args ...any) ([]model.DataExport, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list data exports: %w", err)
+	}
+	defer rows.Close()
+
+	var exports []model.DataExport
+	for rows.Next() {
+		export, err := scanDataExport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan data export: %w", err)
+		}
+		exports = append(exports, *export)
+	}
+
+	return exports, rows.Err()
+}
+
+// exportQueries define o que entra na exportação, na ordem do arquivo. Todas
+// recebem o id do usuário em $1; a senha e os campos internos ficam de fora.
+var exportQueries = []struct {
+	name  string
+	query string
+}{
+	{"profile", `
+		SELECT id, name, first_name, last_name, username, email, photo_url, banner, score, created_at, last_login, is_active
+		FROM db_nexa.tb_user WHERE id = $1`},
+	{"settings", `
+		SELECT theme, language, currency FROM db_nexa.tb_settings WHERE user_id = $1`},
+	{"wallets", `
+		SELECT id, name, currency, total, created_at FROM db_nexa.tb_wallet WHERE user_id = $1 ORDER BY created_at`},
+	{"categories", `
+		SELECT c.id, c.wallet_id, c.name, c.icon, c.color
+		FROM db_nexa.tb_category c
+		JOIN db_nexa.tb_wallet w ON w.id = c.wallet_id
+		WHERE w.user_id = $1 ORDER BY c.name`},
+	{"transactions", `
+		SELECT t.id, t.wallet_id, t.category_id, t.amount, t.original_amount, t.original_currency, t.exchange_rate, t.type,
+			t.payment_method, t.date, t.description, t.photo_url, t.transfer_id, t.created_at
+		FROM db_nexa.tb_transaction t
+		JOIN db_nexa.tb_wallet w ON w.id = t.wallet_id
+		WHERE w.user_id = $1 ORDER BY t.date, t.created_at`},
+	{"recurring_transactions", `
+		SELECT r.id, r.wallet_id, r.category_id, r.amount, r.type, r.payment_method, r.description, r.frequency,
+			r.interval_count, r.day_of_month, r.business_day, r.start_date, r.end_date, r.max_occurrences, r.active, r.created_at
+		FROM db_nexa.tb_recurring_transaction r
+		JOIN db_nexa.tb_wallet w ON w.id = r.wallet_id
+		WHERE w.user_id = $1 ORDER BY r.created_at`},
+	{"transfers", `
+		SELECT id, amount, to_amount, date, description, created_at
+		FROM db_nexa.tb_transfer WHERE user_id = $1 ORDER BY date, created_at`},
+	{"budgets", `
+		SELECT b.id, b.wallet_id, b.category_id, b.reference_month, b.total_limit, b.current_spent, b.saving_goal, b.created_at
+		FROM db_nexa.tb_budget b
+		JOIN db_nexa.tb_wallet w ON w.id = b.wallet_id
+		WHERE w.user_id = $1 ORDER BY b.reference_month`},
+	{"cards", `
+		SELECT cc.id, cc.wallet_id, cc.name, cc.credit_limit, cc.closing_day, cc.due_day
+		FROM db_nexa.tb_credit_card cc
+		JOIN db_nexa.tb_wallet w ON w.id = cc.wallet_id
+		WHERE w.user_id = $1 ORDER BY cc.name`},
+	{"purchases", `
+		SELECT p.id, p.card_id, p.category_id, p.description, p.total, p.date, p.installments, p.interest, p.status
+		FROM db_nexa.tb_purchase p
+		JOIN db_nexa.tb_credit_card cc ON cc.id = p.card_id
+		JOIN db_nexa.tb_wallet w ON w.id = cc.wallet_id
+		WHERE w.user_id = $1 ORDER BY p.date`},
+	{"installments", `
+		SELECT i.id, i.purchase_id, i.number, i.value, i.date, i.status
+		FROM db_nexa.tb_installment i
+		JOIN db_nexa.tb_purchase p ON p.id = i.purchase_id
+		JOIN db_nexa.tb_credit_card cc ON cc.id = p.card_id
+		JOIN db_nexa.tb_wallet w ON w.id = cc.wallet_id
+		WHERE w.user_id = $1 ORDER BY i.date, i.number`},
+}
+
+// DumpUserData lê todos os dados do usuário numa única transação somente
+// leitura, para que o arquivo seja um retrato consistente.
+func (r *DataExportRepository) DumpUserData(ctx context.Context, userID string) ([]model.ExportDataset, error) {
+	defer metrics.ObserveQuery("DataExportRepository", "DumpUserData")()
+	ctx, span := tracing.Start(ctx, "DataExportRepository.DumpUserData")
+	defer span.End()
+
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	datasets := make([]model.ExportDataset, 0, len(exportQueries))
+	for _, q := range exportQueries {
+		dataset, err := dumpDataset(ctx, tx, q.name, q.query, userID)
+		if err != nil {
+			return nil, err
+		}
+		datasets = append(datasets, dataset)
+	}
+
+	return datasets, nil
+}
+
+func dumpDataset(ctx context.Context, tx pgx.Tx, name, query, userID string) (model.ExportDataset, error) {
+	dataset := model.ExportDataset{Name: name}
+
+	rows, err := tx.Query(ctx, query, userID)
+	if err != nil {
+		return dataset, fmt.Errorf("failed to dump %s: %w", name, err)
+	}
+	defer rows.Close()
+
+	fields := rows.FieldDescriptions()
+	for _, field := range fields {
+		dataset.Columns = append(dataset.Columns, field.Name)
+	}
+
+	for rows.Next() {
+		values, err := rows.Values()
+		if err != nil {
+			return dataset, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		for i, value := range values {
+			values[i] = exportValue(value, fields[i].DataTypeOID)
+		}
+		dataset.Rows = append(dataset.Rows, values)
+	}
+
+	if err := rows.Err(); err != nil {
+		return dataset, fmt.Errorf("failed to dump %s: %w", name, err)
+	}
+
+	return dataset, nil
+}
+
+// exportValue troca os tipos do pgx por valores que serializam bem em JSON e
+// CSV: UUID como texto, NUMERIC como número exato (sem passar por float) e
+// DATE como yyyy-mm-dd, sem um horário que o banco não guarda.
+func exportValue(value any, oid uint32) any {
+	switch v := value.(type) {
+	case time.Time:
+		if oid == pgtype.DateOID {
+			return v.Format(time.DateOnly)
+		}
+		return v
+	case [16]byte:
+		return uuid.UUID(v).String()
+	case pgtype.Numeric:
+		if !v.Valid {
+			return nil
+		}
+		text, err := v.Value()
+		if err != nil {
+			return nil
+		}
+		return json.Number(text.(string))
+	default:
+		return v
+	}
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"nexa/internal/currency"
+	"nexa/internal/metrics"
+	"nexa/internal/tracing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	ExchangeRateSourceManual = "manual"
+	ExchangeRateSourceFile   = "file"
+)
+
+type ExchangeRateRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewExchangeRateRepository(db *pgxpool.Pool) *ExchangeRateRepository {
+	return &ExchangeRateRepository{
+		db: db,
+	}
+}
+
+// Upsert grava as cotações numa única transação; uma cotação já cadastrada
+// para o mesmo par e data é substituída.
+func (r *ExchangeRateRepository) Upsert(ctx context.Context, rates []currency.ExchangeRate, source string) error {
+	defer metrics.ObserveQuery("ExchangeRateRepository", "Upsert")()
+	ctx, span := tracing.Start(ctx, "ExchangeRateRepository.Upsert")
+	defer span.End()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for _, rate := range rates {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO db_nexa.tb_exchange_rate (base, quote, date, rate, source)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (base, quote, date) DO UPDATE
+			SET rate = EXCLUDED.rate, source = EXCLUDED.source, updated_at = now()
+		`, rate.Base, rate.Quote, rate.Date, rate.Rate, source)
+		if err != nil {
+			return fmt.Errorf("failed to upsert exchange rate: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit exchange rates: %w", err)
+	}
+
+	return nil
+}
+
+// Find devolve a cotação from -> to válida em date (a última cadastrada até
+// essa data), direta, inversa ou cruzada; nil se não houver.
+func (r *ExchangeRateRepository) Find(ctx context.Context, from, to string, date time.Time) (*currency.ExchangeRate, error) {
+	defer metrics.ObserveQuery("ExchangeRateRepository", "Find")()
+	ctx, span := tracing.Start(ctx, "ExchangeRateRepository.Find")
+	defer span.End()
+
+	rates, err := latestRates(ctx, r.db, date, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	rate, ok := currency.Resolve(rates, from, to)
+	if !ok {
+		return nil, nil
+	}
+
+	return &rate, nil
+}
+
+// Latest devolve, para cada par que envolve alguma das moedas, a última
+// cotação até date; é a entrada de currency.Resolve.
+func (r *ExchangeRateRepository) Latest(ctx context.Context, date time.Time, currencies ...string) ([]currency.ExchangeRate, error) {
+	defer metrics.ObserveQuery("ExchangeRateRepository", "Latest")()
+	ctx, span := tracing.Start(ctx, "ExchangeRateRepository.Latest")
+	defer span.End()
+
+	return latestRates(ctx, r.db, date, currencies...)
+}
+
+func latestRates(ctx context.Context, q querier, date time.Time, currencies ...string) ([]currency.ExchangeRate, error) {
+	rows, err := q.Query(ctx, `
+		SELECT DISTINCT ON (base, quote) base, quote, date, rate
+		FROM db_nexa.tb_exchange_rate
+		WHERE date <= $1 AND (base = ANY($2::text[]) OR quote = ANY($2::text[]))
+		ORDER BY base, quote, date DESC
+	`, date, currencies)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find exchange rates: %w", err)
+	}
+	defer rows.Close()
+
+	var rates []currency.ExchangeRate
+	for rows.Next() {
+		var rate currency.ExchangeRate
+		if err := rows.Scan(&rate.Base, &rate.Quote, &rate.Date, &rate.Rate); err != nil {
+			return nil, fmt.Errorf("failed to scan exchange rate: %w", err)
+		}
+		rates = append(rates, rate)
+	}
+
+	return rates, rows.Err()
+}
@@ -0,0 +1,492 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"nexa/internal/metrics"
+	"nexa/internal/model"
+	"nexa/internal/money"
+	"nexa/internal/recurrence"
+	"nexa/internal/tracing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrCategoryNotFound = errors.New("category not found in wallet")
+
+const recurringTransactionColumns = `id, wallet_id, category_id, amount, type, payment_method, description,
+	frequency, interval_count, COALESCE(day_of_month, 0), business_day, start_date, end_date,
+	COALESCE(max_occurrences, 0), next_index, next_date, active, created_at`
+
+type RecurringTransactionRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewRecurringTransactionRepository(db *pgxpool.Pool) *RecurringTransactionRepository {
+	return &RecurringTransactionRepository{
+		db: db,
+	}
+}
+
+func scanRecurringTransaction(row pgx.Row) (*model.RecurringTransaction, error) {
+	var r model.RecurringTransaction
+	err := row.Scan(
+		&r.ID,
+		&r.WalletID,
+		&r.CategoryID,
+		&r.Amount,
+		&r.Type,
+		&r.PaymentMethod,
+		&r.Description,
+		&r.Frequency,
+		&r.Interval,
+		&r.DayOfMonth,
+		&r.BusinessDay,
+		&r.StartDate,
+		&r.EndDate,
+		&r.Count,
+		&r.NextIndex,
+		&r.NextDate,
+		&r.Active,
+		&r.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// Create grava o modelo com a regra já normalizada. A primeira ocorrência
+// fica agendada; se a data de início já passou, o agendador lança as
+// ocorrências atrasadas.
+func (r *RecurringTransactionRepository) Create(ctx context.Context, recurring *model.RecurringTransaction) error {
+	defer metrics.ObserveQuery("RecurringTransactionRepository", "Create")()
+	ctx, span := tracing.Start(ctx, "RecurringTransactionRepository.Create")
+	defer span.End()
+
+	ok, err := categoryInWallet(ctx, r.db, recurring.WalletID, recurring.CategoryID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrCategoryNotFound
+	}
+
+	recurring.NextIndex = 0
+	recurring.NextDate = nil
+	if first, ok := recurring.At(0); ok {
+		recurring.NextDate = &first.PostingDate
+	}
+	recurring.Active = true
+
+	err = r.db.QueryRow(ctx, `
+		INSERT INTO db_nexa.tb_recurring_transaction
+			(wallet_id, category_id, amount, type, payment_method, description, frequency, interval_count,
+			 day_of_month, business_day, start_date, end_date, max_occurrences, next_date)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, 0), $10, $11, $12, NULLIF($13, 0), $14)
+		RETURNING id, created_at
+	`, recurring.WalletID, recurring.CategoryID, recurring.Amount, recurring.Type, recurring.PaymentMethod,
+		recurring.Description, recurring.Frequency, recurring.Interval, recurring.DayOfMonth, recurring.BusinessDay,
+		recurring.StartDate, recurring.EndDate, recurring.Count, recurring.NextDate).
+		Scan(&recurring.ID, &recurring.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create recurring transaction: %w", err)
+	}
+
+	return nil
+}
+
+// FindByID só encontra modelos de carteiras do próprio usuário.
+func (r *RecurringTransactionRepository) FindByID(ctx context.Context, id, userID string) (*model.RecurringTransaction, error) {
+	defer metrics.ObserveQuery("RecurringTransactionRepository", "FindByID")()
+	ctx, span := tracing.Start(ctx, "RecurringTransactionRepository.FindByID")
+	defer span.End()
+
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, nil
+	}
+
+	recurring, err := scanRecurringTransaction(r.db.QueryRow(ctx, `
+		SELECT `+recurringTransactionColumns+`
+		FROM db_nexa.tb_recurring_transaction
+		WHERE id = $1 AND wallet_id IN (SELECT id FROM db_nexa.tb_wallet WHERE user_id = $2)
+	`, id, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find recurring transaction: %w", err)
+	}
+
+	return recurring, nil
+}
+
+// FindByUserID lista os modelos do usuário, de uma carteira se walletID não
+// for vazio.
+func (r *RecurringTransactionRepository) FindByUserID(ctx context.Context, userID, walletID string) ([]model.RecurringTransaction, error) {
+	defer metrics.ObserveQuery("RecurringTransactionRepository", "FindByUserID")()
+	ctx, span := tracing.Start(ctx, "RecurringTransactionRepository.FindByUserID")
+	defer span.End()
+
+	rows, err := r.db.Query(ctx, `
+		SELECT `+recurringTransactionColumns+`
+		FROM db_nexa.tb_recurring_transaction
+		WHERE wallet_id IN (SELECT id FROM db_nexa.tb_wallet WHERE user_id = $1 AND ($2 = '' OR id::text = $2))
+		ORDER BY active DESC, next_date NULLS LAST, created_at
+	`, userID, walletID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recurring transactions: %w", err)
+	}
+	defer rows.Close()
+
+	recurring := []model.RecurringTransaction{}
+	for rows.Next() {
+		item, err := scanRecurringTransaction(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan recurring transaction: %w", err)
+		}
+		recurring = append(recurring, *item)
+	}
+
+	return recurring, rows.Err()
+}
+
+// Stop encerra a série: nada mais é lançado, e o que já foi lançado fica.
+func (r *RecurringTransactionRepository) Stop(ctx context.Context, id string) error {
+	defer metrics.ObserveQuery("RecurringTransactionRepository", "Stop")()
+	ctx, span := tracing.Start(ctx, "RecurringTransactionRepository.Stop")
+	defer span.End()
+
+	_, err := r.db.Exec(ctx,
+		"UPDATE db_nexa.tb_recurring_transaction SET active = FALSE, next_date = NULL WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to stop recurring transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Occurrences lista as ocorrências com data nominal em [from, to]: as já
+// lançadas com os valores do lançamento, as demais com os do modelo e da
+// exceção. Ocorrências futuras de uma série encerrada não aparecem.
+func (r *RecurringTransactionRepository) Occurrences(ctx context.Context, recurring *model.RecurringTransaction, from, to time.Time, limit int) ([]model.RecurringOccurrence, error) {
+	defer metrics.ObserveQuery("RecurringTransactionRepository", "Occurrences")()
+	ctx, span := tracing.Start(ctx, "RecurringTransactionRepository.Occurrences")
+	defer span.End()
+
+	exceptions, err := recurrenceExceptions(ctx, r.db, recurring.ID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT id, occurrence_date, amount, description, category_id, payment_method, date
+		FROM db_nexa.tb_transaction
+		WHERE recurring_id = $1 AND occurrence_date BETWEEN $2 AND $3
+	`, recurring.ID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recurring occurrences: %w", err)
+	}
+	defer rows.Close()
+
+	posted := map[time.Time]*model.Transaction{}
+	for rows.Next() {
+		var transaction model.Transaction
+		var date time.Time
+		if err := rows.Scan(&transaction.ID, &date, &transaction.Amount, &transaction.Description,
+			&transaction.CategoryID, &transaction.PaymentMethod, &transaction.Date); err != nil {
+			return nil, fmt.Errorf("failed to scan recurring occurrence: %w", err)
+		}
+		posted[date] = &transaction
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	occurrences := []model.RecurringOccurrence{}
+	for _, occurrence := range recurring.Between(from, to, limit) {
+		exception := exceptions[occurrence.Date]
+		item := model.RecurringOccurrence{
+			Occurrence: occurrence,
+			Edited:     exception != nil && !exception.Skip,
+		}
+
+		transaction, isPosted := posted[occurrence.Date]
+		switch {
+		case isPosted:
+			item.Status = model.OccurrencePosted
+			item.TransactionID = &transaction.ID
+			item.PostingDate = transaction.Date
+		case exception != nil && exception.Skip:
+			item.Status = model.OccurrenceSkipped
+			transaction = recurring.Apply(occurrence, nil)
+		case occurrence.Index >= recurring.NextIndex && recurring.Active:
+			item.Status = model.OccurrenceScheduled
+			transaction = recurring.Apply(occurrence, exception)
+		default:
+			continue
+		}
+
+		item.Amount = transaction.Amount
+		item.Description = transaction.Description
+		item.CategoryID = transaction.CategoryID
+		item.PaymentMethod = transaction.PaymentMethod
+		occurrences = append(occurrences, item)
+	}
+
+	return occurrences, nil
+}
+
+// SetException pula ou altera uma ocorrência. Uma exceção sem alterações
+// devolve a ocorrência ao padrão do modelo. Se a ocorrência já foi lançada,
+// o lançamento é criado, alterado ou apagado na hora, com o saldo da
+// carteira.
+func (r *RecurringTransactionRepository) SetException(ctx context.Context, recurring *model.RecurringTransaction, occurrence recurrence.Occurrence, exception *model.RecurrenceException) error {
+	defer metrics.ObserveQuery("RecurringTransactionRepository", "SetException")()
+	ctx, span := tracing.Start(ctx, "RecurringTransactionRepository.SetException")
+	defer span.End()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// O lock no modelo serializa com o agendador, que também o trava.
+	var nextIndex int
+	err = tx.QueryRow(ctx, "SELECT next_index FROM db_nexa.tb_recurring_transaction WHERE id = $1 FOR UPDATE", recurring.ID).
+		Scan(&nextIndex)
+	if err != nil {
+		return fmt.Errorf("failed to lock recurring transaction: %w", err)
+	}
+
+	ok, err := categoryInWallet(ctx, tx, recurring.WalletID, exception.CategoryID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrCategoryNotFound
+	}
+
+	exception.Date = occurrence.Date
+	if isDefaultException(exception) {
+		_, err = tx.Exec(ctx,
+			"DELETE FROM db_nexa.tb_recurring_exception WHERE recurring_id = $1 AND occurrence_date = $2",
+			recurring.ID, occurrence.Date)
+		exception = nil
+	} else {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO db_nexa.tb_recurring_exception
+				(recurring_id, occurrence_date, skip, amount, description, category_id, payment_method)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (recurring_id, occurrence_date) DO UPDATE SET
+				skip = EXCLUDED.skip, amount = EXCLUDED.amount, description = EXCLUDED.description,
+				category_id = EXCLUDED.category_id, payment_method = EXCLUDED.payment_method
+		`, recurring.ID, occurrence.Date, exception.Skip, exception.Amount, exception.Description,
+			exception.CategoryID, exception.PaymentMethod)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save recurrence exception: %w", err)
+	}
+
+	if occurrence.Index < nextIndex {
+		if _, err := syncOccurrence(ctx, tx, recurring, occurrence, exception); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit recurrence exception: %w", err)
+	}
+
+	return nil
+}
+
+// PostNextDue trava um modelo com ocorrência vencida até today e lança as
+// ocorrências pendentes, no máximo limit por chamada. processed é false
+// quando nada está vencido. Como o índice avança na mesma transação dos
+// lançamentos e cada ocorrência tem no máximo um lançamento, repetir a
+// chamada não duplica nada.
+func (r *RecurringTransactionRepository) PostNextDue(ctx context.Context, today time.Time, limit int) (posted int, processed bool, err error) {
+	defer metrics.ObserveQuery("RecurringTransactionRepository", "PostNextDue")()
+	ctx, span := tracing.Start(ctx, "RecurringTransactionRepository.PostNextDue")
+	defer span.End()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	recurring, err := scanRecurringTransaction(tx.QueryRow(ctx, `
+		SELECT `+recurringTransactionColumns+`
+		FROM db_nexa.tb_recurring_transaction
+		WHERE active AND next_date <= $1
+		ORDER BY next_date
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	`, today))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("failed to claim recurring transaction: %w", err)
+	}
+
+	var exceptions map[time.Time]*model.RecurrenceException
+	if first, ok := recurring.At(recurring.NextIndex); ok {
+		// Com ajuste para o dia útil anterior, a data nominal pode ser posterior a today.
+		exceptions, err = recurrenceExceptions(ctx, tx, recurring.ID, first.Date, time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC))
+		if err != nil {
+			return 0, false, err
+		}
+	}
+
+	n := recurring.NextIndex
+	var nextDate *time.Time
+	for {
+		occurrence, ok := recurring.At(n)
+		if !ok {
+			break
+		}
+		if occurrence.PostingDate.After(today) || n-recurring.NextIndex >= limit {
+			nextDate = &occurrence.PostingDate
+			break
+		}
+
+		inserted, err := syncOccurrence(ctx, tx, recurring, occurrence, exceptions[occurrence.Date])
+		if err != nil {
+			return 0, false, err
+		}
+		if inserted {
+			posted++
+		}
+		n++
+	}
+
+	_, err = tx.Exec(ctx,
+		"UPDATE db_nexa.tb_recurring_transaction SET next_index = $2, next_date = $3 WHERE id = $1",
+		recurring.ID, n, nextDate)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to advance recurring transaction: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, false, fmt.Errorf("failed to commit recurring transaction: %w", err)
+	}
+
+	return posted, true, nil
+}
+
+// syncOccurrence deixa o lançamento da ocorrência como o modelo e a exceção
+// mandam (criado, alterado ou apagado) e ajusta o saldo da carteira. inserted
+// indica um lançamento novo.
+func syncOccurrence(ctx context.Context, tx pgx.Tx, recurring *model.RecurringTransaction, occurrence recurrence.Occurrence, exception *model.RecurrenceException) (inserted bool, err error) {
+	desired := recurring.Apply(occurrence, exception)
+
+	var existing model.Transaction
+	err = tx.QueryRow(ctx, `
+		SELECT id, amount, type FROM db_nexa.tb_transaction
+		WHERE recurring_id = $1 AND occurrence_date = $2
+		FOR UPDATE
+	`, recurring.ID, occurrence.Date).Scan(&existing.ID, &existing.Amount, &existing.Type)
+	found := err == nil
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return false, fmt.Errorf("failed to find recurring occurrence: %w", err)
+	}
+
+	var delta money.Amount
+	switch {
+	case desired == nil && !found:
+		return false, nil
+	case desired == nil:
+		_, err = tx.Exec(ctx, "DELETE FROM db_nexa.tb_transaction WHERE id = $1", existing.ID)
+		delta = -existing.Signed()
+	case !found:
+		var tag pgconn.CommandTag
+		tag, err = tx.Exec(ctx, `
+			INSERT INTO db_nexa.tb_transaction
+				(wallet_id, category_id, amount, type, payment_method, date, description, recurring_id, occurrence_date)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			ON CONFLICT (recurring_id, occurrence_date) WHERE recurring_id IS NOT NULL DO NOTHING
+		`, desired.WalletID, desired.CategoryID, desired.Amount, desired.Type, desired.PaymentMethod,
+			desired.Date, desired.Description, recurring.ID, occurrence.Date)
+		if err == nil && tag.RowsAffected() == 1 {
+			inserted = true
+			delta = desired.Signed()
+		}
+	default:
+		_, err = tx.Exec(ctx, `
+			UPDATE db_nexa.tb_transaction
+			SET amount = $2, category_id = $3, payment_method = $4, description = $5
+			WHERE id = $1
+		`, existing.ID, desired.Amount, desired.CategoryID, desired.PaymentMethod, desired.Description)
+		delta = desired.Signed() - existing.Signed()
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to post recurring occurrence: %w", err)
+	}
+
+	if delta != 0 {
+		if _, err := tx.Exec(ctx, "UPDATE db_nexa.tb_wallet SET total = total + $2 WHERE id = $1", recurring.WalletID, delta); err != nil {
+			return false, fmt.Errorf("failed to update wallet total: %w", err)
+		}
+	}
+
+	return inserted, nil
+}
+
+func recurrenceExceptions(ctx context.Context, q querier, recurringID string, from, to time.Time) (map[time.Time]*model.RecurrenceException, error) {
+	rows, err := q.Query(ctx, `
+		SELECT occurrence_date, skip, amount, description, category_id, payment_method
+		FROM db_nexa.tb_recurring_exception
+		WHERE recurring_id = $1 AND occurrence_date BETWEEN $2 AND $3
+	`, recurringID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recurrence exceptions: %w", err)
+	}
+	defer rows.Close()
+
+	exceptions := map[time.Time]*model.RecurrenceException{}
+	for rows.Next() {
+		var exception model.RecurrenceException
+		if err := rows.Scan(&exception.Date, &exception.Skip, &exception.Amount, &exception.Description,
+			&exception.CategoryID, &exception.PaymentMethod); err != nil {
+			return nil, fmt.Errorf("failed to scan recurrence exception: %w", err)
+		}
+		exceptions[exception.Date] = &exception
+	}
+
+	return exceptions, rows.Err()
+}
+
+// categoryInWallet confere que a categoria (opcional) é da carteira.
+func categoryInWallet(ctx context.Context, q querier, walletID string, categoryID *string) (bool, error) {
+	if categoryID == nil {
+		return true, nil
+	}
+	if _, err := uuid.Parse(*categoryID); err != nil {
+		return false, nil
+	}
+
+	var exists bool
+	err := q.QueryRow(ctx,
+		"SELECT EXISTS (SELECT 1 FROM db_nexa.tb_category WHERE id = $1 AND wallet_id = $2)", *categoryID, walletID).
+		Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check category: %w", err)
+	}
+
+	return exists, nil
+}
+
+func isDefaultException(exception *model.RecurrenceException) bool {
+	return !exception.Skip && exception.Amount == nil && exception.Description == nil &&
+		exception.CategoryID == nil && exception.PaymentMethod == nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"nexa/internal/metrics"
+	"nexa/internal/model"
+	"nexa/internal/tracing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SettingsRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewSettingsRepository(db *pgxpool.Pool) *SettingsRepository {
+	return &SettingsRepository{
+		db: db,
+	}
+}
+
+func (r *SettingsRepository) FindByUserID(ctx context.Context, userID string) (*model.Settings, error) {
+	defer metrics.ObserveQuery("SettingsRepository", "FindByUserID")()
+	ctx, span := tracing.Start(ctx, "SettingsRepository.FindByUserID")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var settings model.Settings
+	err := r.db.QueryRow(ctx, "SELECT id, user_id, theme, language, currency FROM db_nexa.tb_settings WHERE user_id = $1", userID).
+		Scan(&settings.ID, &settings.UserID, &settings.Theme, &settings.Language, &settings.Currency)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find settings: %w", err)
+	}
+
+	return &settings, nil
+}
+
+func (r *SettingsRepository) Upsert(ctx context.Context, settings *model.Settings) error {
+	defer metrics.ObserveQuery("SettingsRepository", "Upsert")()
+	ctx, span := tracing.Start(ctx, "SettingsRepository.Upsert")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return upsertSettings(ctx, r.db, settings)
+}
+
+func upsertSettings(ctx context.Context, q querier, settings *model.Settings) error {
+	query := `
+		INSERT INTO db_nexa.tb_settings (user_id, theme, language, currency)
+		VALUES ($1, COALESCE(NULLIF($2, ''), 'light'), COALESCE(NULLIF($3, ''), 'pt-BR'), COALESCE(NULLIF($4, ''), 'BRL'))
+		ON CONFLICT (user_id) DO UPDATE
+		SET theme = COALESCE(NULLIF($2, ''), db_nexa.tb_settings.theme),
+		    language = COALESCE(NULLIF($3, ''), db_nexa.tb_settings.language),
+		    currency = COALESCE(NULLIF($4, ''), db_nexa.tb_settings.currency)
+		RETURNING id
+	`
+
+	if err := q.QueryRow(ctx, query, settings.UserID, settings.Theme, settings.Language, settings.Currency).
+		Scan(&settings.ID); err != nil {
+		return fmt.Errorf("failed to upsert settings: %w", err)
+	}
+
+	return nil
+}
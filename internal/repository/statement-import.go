@@ -0,0 +1,349 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"nexa/internal/metrics"
+	"nexa/internal/model"
+	"nexa/internal/money"
+	"nexa/internal/statement"
+	"nexa/internal/tracing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrImportAlreadyCommitted = errors.New("statement import already committed")
+
+const statementImportColumns = "id, user_id, wallet_id, bank, format, filename, content, mapping, status, imported_count, duplicate_count, invalid_count, created_at, imported_at"
+
+type StatementImportRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewStatementImportRepository(db *pgxpool.Pool) *StatementImportRepository {
+	return &StatementImportRepository{
+		db: db,
+	}
+}
+
+func scanStatementImport(row pgx.Row) (*model.StatementImport, error) {
+	var imp model.StatementImport
+	var mapping []byte
+	err := row.Scan(
+		&imp.ID,
+		&imp.UserID,
+		&imp.WalletID,
+		&imp.Bank,
+		&imp.Format,
+		&imp.Filename,
+		&imp.Content,
+		&mapping,
+		&imp.Status,
+		&imp.ImportedCount,
+		&imp.DuplicateCount,
+		&imp.InvalidCount,
+		&imp.CreatedAt,
+		&imp.ImportedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	imp.Mapping = mapping
+	return &imp, nil
+}
+
+func (r *StatementImportRepository) Create(ctx context.Context, imp *model.StatementImport) error {
+	defer metrics.ObserveQuery("StatementImportRepository", "Create")()
+	ctx, span := tracing.Start(ctx, "StatementImportRepository.Create")
+	defer span.End()
+
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO db_nexa.tb_statement_import (user_id, wallet_id, bank, format, filename, content, mapping)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, status, created_at
+	`, imp.UserID, imp.WalletID, imp.Bank, imp.Format, imp.Filename, imp.Content, nullableJSON(imp.Mapping)).
+		Scan(&imp.ID, &imp.Status, &imp.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create statement import: %w", err)
+	}
+
+	return nil
+}
+
+// FindByID só encontra importações do próprio usuário.
+func (r *StatementImportRepository) FindByID(ctx context.Context, id, userID string) (*model.StatementImport, error) {
+	defer metrics.ObserveQuery("StatementImportRepository", "FindByID")()
+	ctx, span := tracing.Start(ctx, "StatementImportRepository.FindByID")
+	defer span.End()
+
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, nil
+	}
+
+	imp, err := scanStatementImport(r.db.QueryRow(ctx,
+		"SELECT "+statementImportColumns+" FROM db_nexa.tb_statement_import WHERE id = $1 AND user_id = $2", id, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find statement import: %w", err)
+	}
+
+	return imp, nil
+}
+
+func (r *StatementImportRepository) UpdateMapping(ctx context.Context, id string, mapping json.RawMessage) error {
+	defer metrics.ObserveQuery("StatementImportRepository", "UpdateMapping")()
+	ctx, span := tracing.Start(ctx, "StatementImportRepository.UpdateMapping")
+	defer span.End()
+
+	_, err := r.db.Exec(ctx, "UPDATE db_nexa.tb_statement_import SET mapping = $2 WHERE id = $1", id, nullableJSON(mapping))
+	if err != nil {
+		return fmt.Errorf("failed to update statement import mapping: %w", err)
+	}
+
+	return nil
+}
+
+// FindBankMapping devolve o mapeamento salvo para o banco, ou nil.
+func (r *StatementImportRepository) FindBankMapping(ctx context.Context, userID, bank string) (json.RawMessage, error) {
+	defer metrics.ObserveQuery("StatementImportRepository", "FindBankMapping")()
+	ctx, span := tracing.Start(ctx, "StatementImportRepository.FindBankMapping")
+	defer span.End()
+
+	var mapping []byte
+	err := r.db.QueryRow(ctx,
+		"SELECT mapping FROM db_nexa.tb_import_mapping WHERE user_id = $1 AND bank = $2", userID, bank).Scan(&mapping)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find import mapping: %w", err)
+	}
+
+	return mapping, nil
+}
+
+func (r *StatementImportRepository) SaveBankMapping(ctx context.Context, userID, bank string, mapping json.RawMessage) error {
+	defer metrics.ObserveQuery("StatementImportRepository", "SaveBankMapping")()
+	ctx, span := tracing.Start(ctx, "StatementImportRepository.SaveBankMapping")
+	defer span.End()
+
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO db_nexa.tb_import_mapping (user_id, bank, mapping)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, bank) DO UPDATE SET mapping = EXCLUDED.mapping, updated_at = now()
+	`, userID, bank, mapping)
+	if err != nil {
+		return fmt.Errorf("failed to save import mapping: %w", err)
+	}
+
+	return nil
+}
+
+// ExistingEntries lê os lançamentos da carteira entre from e to no formato
+// do extrato, para a detecção de duplicados.
+func (r *StatementImportRepository) ExistingEntries(ctx context.Context, walletID string, from, to time.Time) ([]statement.Entry, error) {
+	defer metrics.ObserveQuery("StatementImportRepository", "ExistingEntries")()
+	ctx, span := tracing.Start(ctx, "StatementImportRepository.ExistingEntries")
+	defer span.End()
+
+	return existingEntries(ctx, r.db, walletID, from, to)
+}
+
+// querier é o que pool e transação têm em comum.
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func existingEntries(ctx context.Context, q querier, walletID string, from, to time.Time) ([]statement.Entry, error) {
+	rows, err := q.Query(ctx, `
+		SELECT date, CASE WHEN type = 'expense' THEN -amount ELSE amount END, description, external_id
+		FROM db_nexa.tb_transaction
+		WHERE wallet_id = $1 AND date BETWEEN $2 AND $3
+	`, walletID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read wallet transactions: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []statement.Entry
+	for rows.Next() {
+		var entry statement.Entry
+		if err := rows.Scan(&entry.Date, &entry.Amount, &entry.Description, &entry.ExternalID); err != nil {
+			return nil, fmt.Errorf("failed to scan wallet transaction: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+func balanceAt(ctx context.Context, q querier, walletID string, date time.Time) (money.Amount, error) {
+	var balance money.Amount
+	err := q.QueryRow(ctx, `
+		SELECT w.total - COALESCE((
+			SELECT SUM(CASE WHEN t.type = 'expense' THEN -t.amount ELSE t.amount END)
+			FROM db_nexa.tb_transaction t
+			WHERE t.wallet_id = w.id AND t.date > $2
+		), 0)
+		FROM db_nexa.tb_wallet w
+		WHERE w.id = $1
+	`, walletID, date).Scan(&balance)
+	if err != nil {
+		return 0, fmt.Errorf("failed to compute wallet balance: %w", err)
+	}
+
+	return balance, nil
+}
+
+// ReconcileDescription é a descrição do lançamento de ajuste criado na
+// conciliação com o saldo do extrato.
+const ReconcileDescription = "Ajuste de saldo (conciliação do extrato)"
+
+// ImportResult resume uma importação confirmada. Adjustment é o ajuste
+// lançado para o saldo da carteira bater com o do extrato, quando pedido.
+type ImportResult struct {
+	Imported   int             `json:"imported"`
+	Duplicates int             `json:"duplicates"`
+	Invalid    int             `json:"invalid"`
+	Skipped    []statement.Row `json:"skipped"`
+	Adjustment *money.Amount   `json:"adjustment,omitempty"`
+	Balance    money.Amount    `json:"balance"`
+}
+
+// Commit grava numa transação os lançamentos que ainda não existem na
+// carteira, atualiza o saldo e marca a importação como concluída. A carteira
+// fica bloqueada durante a operação, então duas importações simultâneas do
+// mesmo extrato não geram duplicados. Com reconcile, um lançamento de ajuste
+// acerta o saldo da carteira na data do saldo informado pelo banco.
+func (r *StatementImportRepository) Commit(ctx context.Context, imp *model.StatementImport, entries []statement.Entry, invalid int, reconcile *statement.Balance) (*ImportResult, error) {
+	defer metrics.ObserveQuery("StatementImportRepository", "Commit")()
+	ctx, span := tracing.Start(ctx, "StatementImportRepository.Commit")
+	defer span.End()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	err = tx.QueryRow(ctx, "SELECT status FROM db_nexa.tb_statement_import WHERE id = $1 FOR UPDATE", imp.ID).Scan(&status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock statement import: %w", err)
+	}
+	if status != model.StatementImportPending {
+		return nil, ErrImportAlreadyCommitted
+	}
+
+	if _, err := tx.Exec(ctx, "SELECT 1 FROM db_nexa.tb_wallet WHERE id = $1 FOR UPDATE", imp.WalletID); err != nil {
+		return nil, fmt.Errorf("failed to lock wallet: %w", err)
+	}
+
+	result := &ImportResult{Invalid: invalid}
+
+	var fresh []statement.Entry
+	if len(entries) > 0 {
+		from, to := statement.DateRange(entries)
+		existing, err := existingEntries(ctx, tx, imp.WalletID, from, to)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, duplicate := range statement.MarkDuplicates(entries, existing) {
+			if duplicate {
+				result.Duplicates++
+				continue
+			}
+			fresh = append(fresh, entries[i])
+		}
+	}
+
+	var delta money.Amount
+	if len(fresh) > 0 {
+		_, err = tx.CopyFrom(ctx,
+			pgx.Identifier{"db_nexa", "tb_transaction"},
+			[]string{"wallet_id", "amount", "type", "date", "description", "import_id", "external_id"},
+			pgx.CopyFromSlice(len(fresh), func(i int) ([]any, error) {
+				entry := fresh[i]
+				kind := model.TransactionIncome
+				if entry.Amount < 0 {
+					kind = model.TransactionExpense
+				}
+				delta += entry.Amount
+				return []any{imp.WalletID, entry.Amount.Abs(), kind, entry.Date, entry.Description, imp.ID, entry.ExternalID}, nil
+			}),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert transactions: %w", err)
+		}
+	}
+	result.Imported = len(fresh)
+
+	err = tx.QueryRow(ctx, "UPDATE db_nexa.tb_wallet SET total = total + $2 WHERE id = $1 RETURNING total", imp.WalletID, delta).
+		Scan(&result.Balance)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update wallet total: %w", err)
+	}
+
+	if reconcile != nil {
+		balance, err := balanceAt(ctx, tx, imp.WalletID, reconcile.Date)
+		if err != nil {
+			return nil, err
+		}
+
+		if adjustment := reconcile.Amount - balance; adjustment != 0 {
+			kind := model.TransactionIncome
+			if adjustment < 0 {
+				kind = model.TransactionExpense
+			}
+			_, err = tx.Exec(ctx, `
+				INSERT INTO db_nexa.tb_transaction (wallet_id, amount, type, date, description, import_id)
+				VALUES ($1, $2, $3, $4, $5, $6)
+			`, imp.WalletID, adjustment.Abs(), kind, reconcile.Date, ReconcileDescription, imp.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to insert reconciliation adjustment: %w", err)
+			}
+
+			err = tx.QueryRow(ctx, "UPDATE db_nexa.tb_wallet SET total = total + $2 WHERE id = $1 RETURNING total", imp.WalletID, adjustment).
+				Scan(&result.Balance)
+			if err != nil {
+				return nil, fmt.Errorf("failed to update wallet total: %w", err)
+			}
+			result.Adjustment = &adjustment
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE db_nexa.tb_statement_import
+		SET status = 'imported', mapping = $2, imported_count = $3, duplicate_count = $4, invalid_count = $5, imported_at = now()
+		WHERE id = $1
+	`, imp.ID, nullableJSON(imp.Mapping), result.Imported, result.Duplicates, result.Invalid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to finish statement import: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit statement import: %w", err)
+	}
+
+	return result, nil
+}
+
+// nullableJSON grava NULL em vez de um JSONB vazio.
+func nullableJSON(raw json.RawMessage) any {
+	if len(raw) == 0 {
+		return nil
+	}
+	return []byte(raw)
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"nexa/internal/metrics"
+	"nexa/internal/model"
+	"nexa/internal/statement"
+	"nexa/internal/tracing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TransactionRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewTransactionRepository(db *pgxpool.Pool) *TransactionRepository {
+	return &TransactionRepository{
+		db: db,
+	}
+}
+
+// Create grava um lançamento avulso e atualiza o saldo da carteira na mesma
+// transação. A categoria, se houver, precisa ser da carteira
+// (ErrCategoryNotFound).
+func (r *TransactionRepository) Create(ctx context.Context, transaction *model.Transaction) error {
+	defer metrics.ObserveQuery("TransactionRepository", "Create")()
+	ctx, span := tracing.Start(ctx, "TransactionRepository.Create")
+	defer span.End()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "SELECT 1 FROM db_nexa.tb_wallet WHERE id = $1 FOR UPDATE", transaction.WalletID); err != nil {
+		return fmt.Errorf("failed to lock wallet: %w", err)
+	}
+
+	ok, err := categoryInWallet(ctx, tx, transaction.WalletID, transaction.CategoryID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrCategoryNotFound
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO db_nexa.tb_transaction (wallet_id, category_id, amount, original_amount, original_currency,
+			exchange_rate, type, payment_method, date, description)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at
+	`, transaction.WalletID, transaction.CategoryID, transaction.Amount, transaction.OriginalAmount,
+		transaction.OriginalCurrency, transaction.ExchangeRate, transaction.Type, transaction.PaymentMethod,
+		transaction.Date, transaction.Description).Scan(&transaction.ID, &transaction.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create transaction: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, "UPDATE db_nexa.tb_wallet SET total = total + $2 WHERE id = $1",
+		transaction.WalletID, transaction.Signed()); err != nil {
+		return fmt.Errorf("failed to update wallet total: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// TransferFilter é o Type que seleciona só transferências.
+const TransferFilter = "transfer"
+
+// TransactionFilter restringe a exportação. CategoryID e Type vazios não
+// filtram; Type "income" ou "expense" deixa as transferências de fora e
+// "transfer" traz só elas. From e To são inclusivos.
+type TransactionFilter struct {
+	WalletID   string
+	From       time.Time
+	To         time.Time
+	CategoryID string
+	Type       string
+}
+
+// Export percorre os lançamentos do filtro em ordem de data, chamando fn para
+// cada um à medida que chegam do banco, sem montar a lista em memória.
+func (r *TransactionRepository) Export(ctx context.Context, filter TransactionFilter, fn func(*statement.Record) error) error {
+	defer metrics.ObserveQuery("TransactionRepository", "Export")()
+	ctx, span := tracing.Start(ctx, "TransactionRepository.Export")
+	defer span.End()
+
+	rows, err := r.db.Query(ctx, `
+		SELECT t.id, t.date, t.type, t.transfer_id IS NOT NULL, t.amount, COALESCE(c.name, ''), t.description,
+			t.payment_method, t.external_id
+		FROM db_nexa.tb_transaction t
+		LEFT JOIN db_nexa.tb_category c ON c.id = t.category_id
+		WHERE t.wallet_id = $1
+			AND t.date BETWEEN $2 AND $3
+			AND ($4 = '' OR t.category_id::text = $4)
+			AND ($5 = ''
+				OR ($5 = $6 AND t.transfer_id IS NOT NULL)
+				OR (t.type = $5 AND t.transfer_id IS NULL))
+		ORDER BY t.date, t.created_at, t.id
+	`, filter.WalletID, filter.From, filter.To, filter.CategoryID, filter.Type, TransferFilter)
+	if err != nil {
+		return fmt.Errorf("failed to export transactions: %w", err)
+	}
+	defer rows.Close()
+
+	var record statement.Record
+	for rows.Next() {
+		if err := rows.Scan(&record.ID, &record.Date, &record.Type, &record.Transfer, &record.Amount, &record.Category,
+			&record.Description, &record.PaymentMethod, &record.ExternalID); err != nil {
+			return fmt.Errorf("failed to scan transaction: %w", err)
+		}
+		if err := fn(&record); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// MonthFlow soma receitas e despesas da carteira no mês de month. As
+// transferências entre carteiras não são receita nem despesa e vêm à parte.
+func (r *TransactionRepository) MonthFlow(ctx context.Context, walletID string, month time.Time) (*model.MonthFlow, error) {
+	defer metrics.ObserveQuery("TransactionRepository", "MonthFlow")()
+	ctx, span := tracing.Start(ctx, "TransactionRepository.MonthFlow")
+	defer span.End()
+
+	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	flow := model.MonthFlow{WalletID: walletID, Month: start.Format("2006-01")}
+
+	err := r.db.QueryRow(ctx, `
+		SELECT
+			COALESCE(SUM(amount) FILTER (WHERE type = 'income' AND transfer_id IS NULL), 0),
+			COALESCE(SUM(amount) FILTER (WHERE type = 'expense' AND transfer_id IS NULL), 0),
+			COALESCE(SUM(amount) FILTER (WHERE type = 'income' AND transfer_id IS NOT NULL), 0),
+			COALESCE(SUM(amount) FILTER (WHERE type = 'expense' AND transfer_id IS NOT NULL), 0)
+		FROM db_nexa.tb_transaction
+		WHERE wallet_id = $1 AND date >= $2 AND date < $3
+	`, walletID, start, start.AddDate(0, 1, 0)).Scan(&flow.Income, &flow.Expense, &flow.TransfersIn, &flow.TransfersOut)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute month flow: %w", err)
+	}
+
+	flow.Net = flow.Income - flow.Expense
+	return &flow, nil
+}
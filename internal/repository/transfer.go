@@ -0,0 +1,324 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"nexa/internal/currency"
+	"nexa/internal/metrics"
+	"nexa/internal/model"
+	"nexa/internal/tracing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// transferColumns monta a transferência com as carteiras dos dois lados.
+const transferColumns = `tr.id, tr.user_id,
+	COALESCE((SELECT wallet_id::text FROM db_nexa.tb_transaction WHERE transfer_id = tr.id AND type = 'expense'), ''),
+	COALESCE((SELECT wallet_id::text FROM db_nexa.tb_transaction WHERE transfer_id = tr.id AND type = 'income'), ''),
+	tr.amount, tr.to_amount, tr.date, tr.description, tr.created_at`
+
+type TransferRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewTransferRepository(db *pgxpool.Pool) *TransferRepository {
+	return &TransferRepository{
+		db: db,
+	}
+}
+
+func scanTransfer(row pgx.Row) (*model.Transfer, error) {
+	var transfer model.Transfer
+	err := row.Scan(
+		&transfer.ID,
+		&transfer.UserID,
+		&transfer.FromWalletID,
+		&transfer.ToWalletID,
+		&transfer.Amount,
+		&transfer.ToAmount,
+		&transfer.Date,
+		&transfer.Description,
+		&transfer.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &transfer, nil
+}
+
+// Create grava a transferência e os dois lançamentos numa única transação,
+// atualizando o saldo das duas carteiras.
+func (r *TransferRepository) Create(ctx context.Context, transfer *model.Transfer) error {
+	defer metrics.ObserveQuery("TransferRepository", "Create")()
+	ctx, span := tracing.Start(ctx, "TransferRepository.Create")
+	defer span.End()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockWallets(ctx, tx, transfer.FromWalletID, transfer.ToWalletID); err != nil {
+		return err
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO db_nexa.tb_transfer (user_id, amount, to_amount, date, description)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`, transfer.UserID, transfer.Amount, transfer.ToAmount, transfer.Date, transfer.Description).
+		Scan(&transfer.ID, &transfer.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create transfer: %w", err)
+	}
+
+	if err := writeTransferLegs(ctx, tx, transfer); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transfer: %w", err)
+	}
+
+	return nil
+}
+
+// FindByID só encontra transferências do próprio usuário.
+func (r *TransferRepository) FindByID(ctx context.Context, id, userID string) (*model.Transfer, error) {
+	defer metrics.ObserveQuery("TransferRepository", "FindByID")()
+	ctx, span := tracing.Start(ctx, "TransferRepository.FindByID")
+	defer span.End()
+
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, nil
+	}
+
+	transfer, err := scanTransfer(r.db.QueryRow(ctx,
+		"SELECT "+transferColumns+" FROM db_nexa.tb_transfer tr WHERE tr.id = $1 AND tr.user_id = $2", id, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find transfer: %w", err)
+	}
+
+	return transfer, nil
+}
+
+// FindByUserID lista as transferências do usuário, mais recentes primeiro;
+// com walletID, só as que entram ou saem dessa carteira.
+func (r *TransferRepository) FindByUserID(ctx context.Context, userID, walletID string) ([]model.Transfer, error) {
+	defer metrics.ObserveQuery("TransferRepository", "FindByUserID")()
+	ctx, span := tracing.Start(ctx, "TransferRepository.FindByUserID")
+	defer span.End()
+
+	rows, err := r.db.Query(ctx, `
+		SELECT `+transferColumns+`
+		FROM db_nexa.tb_transfer tr
+		WHERE tr.user_id = $1
+			AND ($2 = '' OR EXISTS (
+				SELECT 1 FROM db_nexa.tb_transaction t WHERE t.transfer_id = tr.id AND t.wallet_id::text = $2))
+		ORDER BY tr.date DESC, tr.created_at DESC
+	`, userID, walletID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transfers: %w", err)
+	}
+	defer rows.Close()
+
+	transfers := []model.Transfer{}
+	for rows.Next() {
+		transfer, err := scanTransfer(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transfer: %w", err)
+		}
+		transfers = append(transfers, *transfer)
+	}
+
+	return transfers, rows.Err()
+}
+
+// Update troca valor, data, descrição ou carteiras. Os lançamentos antigos
+// são desfeitos e os novos gravados na mesma transação, então os dois lados
+// e os saldos nunca ficam divergentes.
+func (r *TransferRepository) Update(ctx context.Context, transfer *model.Transfer) error {
+	defer metrics.ObserveQuery("TransferRepository", "Update")()
+	ctx, span := tracing.Start(ctx, "TransferRepository.Update")
+	defer span.End()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := lockTransfer(ctx, tx, transfer.ID)
+	if err != nil {
+		return err
+	}
+	if err := lockWallets(ctx, tx, append(current, transfer.FromWalletID, transfer.ToWalletID)...); err != nil {
+		return err
+	}
+
+	if err := removeTransferLegs(ctx, tx, transfer.ID); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx,
+		"UPDATE db_nexa.tb_transfer SET amount = $2, to_amount = $3, date = $4, description = $5 WHERE id = $1",
+		transfer.ID, transfer.Amount, transfer.ToAmount, transfer.Date, transfer.Description)
+	if err != nil {
+		return fmt.Errorf("failed to update transfer: %w", err)
+	}
+
+	if err := writeTransferLegs(ctx, tx, transfer); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transfer: %w", err)
+	}
+
+	return nil
+}
+
+// Delete apaga a transferência com os dois lançamentos e desfaz o efeito no
+// saldo das carteiras.
+func (r *TransferRepository) Delete(ctx context.Context, transfer *model.Transfer) error {
+	defer metrics.ObserveQuery("TransferRepository", "Delete")()
+	ctx, span := tracing.Start(ctx, "TransferRepository.Delete")
+	defer span.End()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := lockTransfer(ctx, tx, transfer.ID)
+	if err != nil {
+		return err
+	}
+	if err := lockWallets(ctx, tx, current...); err != nil {
+		return err
+	}
+
+	if err := removeTransferLegs(ctx, tx, transfer.ID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM db_nexa.tb_transfer WHERE id = $1", transfer.ID); err != nil {
+		return fmt.Errorf("failed to delete transfer: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transfer: %w", err)
+	}
+
+	return nil
+}
+
+// lockTransfer trava a transferência e os lançamentos e devolve as carteiras
+// deles lidas dentro da transação: as que o handler carregou antes podem ter
+// mudado numa edição concorrente.
+func lockTransfer(ctx context.Context, tx pgx.Tx, transferID string) ([]string, error) {
+	if _, err := tx.Exec(ctx, "SELECT 1 FROM db_nexa.tb_transfer WHERE id = $1 FOR UPDATE", transferID); err != nil {
+		return nil, fmt.Errorf("failed to lock transfer: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, "SELECT wallet_id::text FROM db_nexa.tb_transaction WHERE transfer_id = $1 FOR UPDATE", transferID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock transfer transactions: %w", err)
+	}
+	walletIDs, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock transfer transactions: %w", err)
+	}
+
+	return walletIDs, nil
+}
+
+// lockWallets trava as carteiras sempre na mesma ordem (por id), para que
+// transferências em sentidos opostos não entrem em deadlock.
+func lockWallets(ctx context.Context, tx pgx.Tx, walletIDs ...string) error {
+	ids := make([]string, 0, len(walletIDs))
+	for _, id := range walletIDs {
+		if id != "" {
+			ids = append(ids, id)
+		}
+	}
+
+	_, err := tx.Exec(ctx,
+		"SELECT 1 FROM db_nexa.tb_wallet WHERE id = ANY($1::uuid[]) ORDER BY id FOR UPDATE", ids)
+	if err != nil {
+		return fmt.Errorf("failed to lock wallets: %w", err)
+	}
+
+	return nil
+}
+
+// writeTransferLegs grava a saída na origem e a entrada no destino. Entre
+// moedas diferentes, a entrada guarda o valor de origem e a cotação efetiva.
+func writeTransferLegs(ctx context.Context, tx pgx.Tx, transfer *model.Transfer) error {
+	var fromCurrency, toCurrency string
+	err := tx.QueryRow(ctx, `
+		SELECT (SELECT currency FROM db_nexa.tb_wallet WHERE id = $1), (SELECT currency FROM db_nexa.tb_wallet WHERE id = $2)
+	`, transfer.FromWalletID, transfer.ToWalletID).Scan(&fromCurrency, &toCurrency)
+	if err != nil {
+		return fmt.Errorf("failed to find wallet currencies: %w", err)
+	}
+
+	income := model.Transaction{WalletID: transfer.ToWalletID, Type: model.TransactionIncome, Amount: transfer.ToAmount}
+	if fromCurrency != toCurrency {
+		rate := currency.Implied(transfer.Amount, transfer.ToAmount)
+		income.OriginalAmount = &transfer.Amount
+		income.OriginalCurrency = &fromCurrency
+		income.ExchangeRate = &rate
+	}
+	legs := []model.Transaction{
+		{WalletID: transfer.FromWalletID, Type: model.TransactionExpense, Amount: transfer.Amount},
+		income,
+	}
+
+	for _, leg := range legs {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO db_nexa.tb_transaction (wallet_id, amount, original_amount, original_currency, exchange_rate,
+				type, date, description, transfer_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		`, leg.WalletID, leg.Amount, leg.OriginalAmount, leg.OriginalCurrency, leg.ExchangeRate, leg.Type,
+			transfer.Date, transfer.Description, transfer.ID)
+		if err != nil {
+			return fmt.Errorf("failed to insert transfer transaction: %w", err)
+		}
+
+		if _, err := tx.Exec(ctx, "UPDATE db_nexa.tb_wallet SET total = total + $2 WHERE id = $1", leg.WalletID, leg.Signed()); err != nil {
+			return fmt.Errorf("failed to update wallet total: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// removeTransferLegs apaga os lançamentos da transferência e desfaz o efeito
+// deles no saldo. Um lado cuja carteira já foi apagada simplesmente não existe.
+func removeTransferLegs(ctx context.Context, tx pgx.Tx, transferID string) error {
+	_, err := tx.Exec(ctx, `
+		WITH removed AS (
+			DELETE FROM db_nexa.tb_transaction WHERE transfer_id = $1
+			RETURNING wallet_id, CASE WHEN type = 'expense' THEN amount ELSE -amount END AS reverted
+		)
+		UPDATE db_nexa.tb_wallet w
+		SET total = w.total + removed.reverted
+		FROM removed
+		WHERE w.id = removed.wallet_id
+	`, transferID)
+	if err != nil {
+		return fmt.Errorf("failed to remove transfer transactions: %w", err)
+	}
+
+	return nil
+}
@@ -3,19 +3,22 @@ package repository
 import (
 	"context"
 	"fmt"
+	"nexa/internal/metrics"
 	"nexa/internal/model"
+	"nexa/internal/tracing"
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type UserAuthenticationTokenRepository struct {
-	db     *pgx.Conn
+	db     *pgxpool.Pool
 	schema string
 	table  string
 }
 
-func NewUserAuthenticationTokenRepository(db *pgx.Conn, schema, table string) *UserAuthenticationTokenRepository {
+func NewUserAuthenticationTokenRepository(db *pgxpool.Pool, schema, table string) *UserAuthenticationTokenRepository {
 	return &UserAuthenticationTokenRepository{
 		db:     db,
 		schema: schema,
@@ -27,8 +30,12 @@ func (r *UserAuthenticationTokenRepository) tableFQN() string {
 	return fmt.Sprintf("%s.%s", r.schema, r.table)
 }
 
-func (r *UserAuthenticationTokenRepository) FindTokenByUserID(userID string) (*model.UserAuthenticationToken, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (r *UserAuthenticationTokenRepository) FindTokenByUserID(ctx context.Context, userID string) (*model.UserAuthenticationToken, error) {
+	defer metrics.ObserveQuery("UserAuthenticationTokenRepository", "FindTokenByUserID")()
+	ctx, span := tracing.Start(ctx, "UserAuthenticationTokenRepository.FindTokenByUserID")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	query := fmt.Sprintf("SELECT id, user_id, code, expires_at, fails FROM %s WHERE user_id = $1 LIMIT 1", r.tableFQN())
@@ -45,8 +52,12 @@ func (r *UserAuthenticationTokenRepository) FindTokenByUserID(userID string) (*m
 	return &token, nil
 }
 
-func (r *UserAuthenticationTokenRepository) Insert(token *model.UserAuthenticationToken) (string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (r *UserAuthenticationTokenRepository) Insert(ctx context.Context, token *model.UserAuthenticationToken) (string, error) {
+	defer metrics.ObserveQuery("UserAuthenticationTokenRepository", "Insert")()
+	ctx, span := tracing.Start(ctx, "UserAuthenticationTokenRepository.Insert")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	// Inserimos os campos e retornamos o id gerado
@@ -61,8 +72,12 @@ func (r *UserAuthenticationTokenRepository) Insert(token *model.UserAuthenticati
 	return id, nil
 }
 
-func (r *UserAuthenticationTokenRepository) IncrementFails(id string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (r *UserAuthenticationTokenRepository) IncrementFails(ctx context.Context, id string) error {
+	defer metrics.ObserveQuery("UserAuthenticationTokenRepository", "IncrementFails")()
+	ctx, span := tracing.Start(ctx, "UserAuthenticationTokenRepository.IncrementFails")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	query := fmt.Sprintf("UPDATE %s SET fails = fails + 1 WHERE id = $1", r.tableFQN())
@@ -79,8 +94,12 @@ func (r *UserAuthenticationTokenRepository) IncrementFails(id string) error {
 	return nil
 }
 
-func (r *UserAuthenticationTokenRepository) Delete(id string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (r *UserAuthenticationTokenRepository) Delete(ctx context.Context, id string) error {
+	defer metrics.ObserveQuery("UserAuthenticationTokenRepository", "Delete")()
+	ctx, span := tracing.Start(ctx, "UserAuthenticationTokenRepository.Delete")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1", r.tableFQN())
@@ -2,62 +2,91 @@ package repository
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"nexa/internal/metrics"
 	"nexa/internal/model"
+	"nexa/internal/tracing"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type UserRepository struct {
-	db *pgx.Conn
+	db *pgxpool.Pool
 }
 
-func NewUserRepository(conn *pgx.Conn) *UserRepository {
+func NewUserRepository(conn *pgxpool.Pool) *UserRepository {
 	return &UserRepository{
 		db: conn,
 	}
 }
 
-func (b *UserRepository) InsertUser(user *model.User) error {
-	_, err := b.db.Exec(context.Background(), "INSERT INTO db_nexa.tb_user (name, username, email, password, photo_url, last_login) VALUES ($1, $2, $3, $4, $5, $6)",
-		user.Name, user.Username, user.Email, user.Password, user.PhotoUrl, user.LastLogin)
+func (b *UserRepository) InsertUser(ctx context.Context, user *model.User) error {
+	defer metrics.ObserveQuery("UserRepository", "InsertUser")()
+	ctx, span := tracing.Start(ctx, "UserRepository.InsertUser")
+	defer span.End()
 
-	return err
+	err := b.db.QueryRow(ctx, "INSERT INTO db_nexa.tb_user (name, first_name, last_name, username, email, password, photo_url, last_login) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id",
+		user.Name, user.FirstName, user.LastName, user.Username, user.Email, user.Password, user.PhotoUrl, user.LastLogin).Scan(&user.ID)
+	if err != nil {
+		return uniqueViolation(err)
+	}
+
+	return nil
 }
 
-func (u *UserRepository) FindByFilter(key string, value any) (*model.User, error) {
+func (u *UserRepository) FindByFilter(ctx context.Context, key string, value any) (*model.User, error) {
+	defer metrics.ObserveQuery("UserRepository", "FindByFilter")()
+	ctx, span := tracing.Start(ctx, "UserRepository.FindByFilter")
+	defer span.End()
+
 	var user model.User
 
-	validKeys := map[string]bool{
-		"id":       true,
-		"email":    true,
-		"username": true,
-		"name":     true,
+	// E-mail e username são únicos sem diferenciar maiúsculas de minúsculas.
+	conditions := map[string]string{
+		"id":       "id = $1",
+		"email":    "lower(email) = lower($1)",
+		"username": "lower(username) = lower($1)",
+		"name":     "name = $1",
 	}
 
-	if !validKeys[key] {
+	condition, ok := conditions[key]
+	if !ok {
 		return nil, fmt.Errorf("invalid filter key: %s", key)
 	}
 
 	query := fmt.Sprintf(`
-		SELECT id, name, username, email, password, photo_url, score, created_at, last_login, is_active
+		SELECT id, name, first_name, last_name, username, email, password, photo_url, banner, score, created_at, last_login, is_active,
+		       pending_email, sessions_revoked_at, deactivated_at
 		FROM db_nexa.tb_user 
-		WHERE %s = $1 
+		WHERE %s 
 		LIMIT 1
-	`, key)
+	`, condition)
 
-	err := u.db.QueryRow(context.Background(), query, value).Scan(
+	err := u.db.QueryRow(ctx, query, value).Scan(
 		&user.ID,
 		&user.Name,
+		&user.FirstName,
+		&user.LastName,
 		&user.Username,
 		&user.Email,
 		&user.Password,
 		&user.PhotoUrl,
+		&user.Banner,
 		&user.Score,
 		&user.CreatedAt,
 		&user.LastLogin,
 		&user.IsActive,
+		&user.PendingEmail,
+		&user.SessionsRevokedAt,
+		&user.DeactivatedAt,
 	)
 
 	if err != nil {
@@ -70,27 +99,90 @@ func (u *UserRepository) FindByFilter(key string, value any) (*model.User, error
 	return &user, nil
 }
 
-func (u *UserRepository) UpdateByID(id string, updateData map[string]interface{}) error {
-	if len(updateData) == 0 {
-		return fmt.Errorf("update data is empty")
-	}
+// updatableColumns são as únicas colunas aceitas por UpdateByID. As chaves do
+// mapa são sempre constantes do código, nunca campos vindos do cliente.
+var updatableColumns = map[string]bool{
+	"name":       true,
+	"first_name": true,
+	"last_name":  true,
+	"username":   true,
+	"photo_url":  true,
+	"banner":     true,
+	"password":   true,
+	"is_active":  true,
+	"last_login": true,
 
-	user, err := u.FindByFilter("id", id)
+	"email":               true,
+	"pending_email":       true,
+	"sessions_revoked_at": true,
+	"deactivated_at":      true,
+}
+
+var (
+	ErrUserNotFound  = errors.New("user not found")
+	ErrEmailTaken    = errors.New("email already registered")
+	ErrUsernameTaken = errors.New("username already taken")
+)
+
+func (u *UserRepository) UpdateByID(ctx context.Context, id string, updateData map[string]interface{}) error {
+	defer metrics.ObserveQuery("UserRepository", "UpdateByID")()
+	ctx, span := tracing.Start(ctx, "UserRepository.UpdateByID")
+	defer span.End()
+
+	return updateUser(ctx, u.db, id, updateData)
+}
+
+// UpdateProfile grava as colunas do perfil e as configurações na mesma
+// transação, para que um PATCH em /me não aplique só parte dos campos.
+// settings pode ser nil.
+func (u *UserRepository) UpdateProfile(ctx context.Context, id string, updateData map[string]interface{}, settings *model.Settings) error {
+	defer metrics.ObserveQuery("UserRepository", "UpdateProfile")()
+	ctx, span := tracing.Start(ctx, "UserRepository.UpdateProfile")
+	defer span.End()
+
+	tx, err := u.db.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to verify user before update: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	if user == nil {
-		return fmt.Errorf("user not found")
+	defer tx.Rollback(ctx)
+
+	if len(updateData) > 0 {
+		if err := updateUser(ctx, tx, id, updateData); err != nil {
+			return err
+		}
+	}
+	if settings != nil {
+		if err := upsertSettings(ctx, tx, settings); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit profile update: %w", err)
+	}
+
+	return nil
+}
+
+func updateUser(ctx context.Context, q querier, id string, updateData map[string]interface{}) error {
+	if len(updateData) == 0 {
+		return fmt.Errorf("update data is empty")
 	}
 
-	setClauses := []string{}
-	values := []interface{}{}
-	i := 1
+	columns := make([]string, 0, len(updateData))
+	for column := range updateData {
+		if !updatableColumns[column] {
+			return fmt.Errorf("invalid update column: %s", column)
+		}
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
 
-	for column, value := range updateData {
-		setClauses = append(setClauses, fmt.Sprintf("%s = $%d", column, i))
-		values = append(values, value)
-		i++
+	setClauses := make([]string, 0, len(columns))
+	values := make([]interface{}, 0, len(columns)+1)
+	for i, column := range columns {
+		setClauses = append(setClauses, fmt.Sprintf("%s = $%d", pgx.Identifier{column}.Sanitize(), i+1))
+		values = append(values, updateData[column])
 	}
 
 	values = append(values, id)
@@ -98,13 +190,126 @@ func (u *UserRepository) UpdateByID(id string, updateData map[string]interface{}
 	query := fmt.Sprintf(
 		"UPDATE db_nexa.tb_user SET %s WHERE id = $%d",
 		strings.Join(setClauses, ", "),
-		i,
+		len(values),
 	)
 
-	_, err = u.db.Exec(context.Background(), query, values...)
+	tag, err := q.Exec(ctx, query, values...)
 	if err != nil {
-		return fmt.Errorf("failed to update user: %w", err)
+		return fmt.Errorf("failed to update user: %w", uniqueViolation(err))
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrUserNotFound
 	}
 
 	return nil
 }
+
+// SessionsRevokedAt devolve o segundo até o qual (inclusive) os JWTs do
+// usuário não valem mais (zero se nunca houve revogação). found é false se o
+// usuário não existe.
+func (u *UserRepository) SessionsRevokedAt(ctx context.Context, id string) (revokedAt time.Time, found bool, err error) {
+	defer metrics.ObserveQuery("UserRepository", "SessionsRevokedAt")()
+	ctx, span := tracing.Start(ctx, "UserRepository.SessionsRevokedAt")
+	defer span.End()
+
+	var value *time.Time
+	err = u.db.QueryRow(ctx, "SELECT sessions_revoked_at FROM db_nexa.tb_user WHERE id = $1", id).Scan(&value)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return time.Time{}, false, nil
+		}
+		return time.Time{}, false, fmt.Errorf("failed to read sessions_revoked_at: %w", err)
+	}
+
+	if value != nil {
+		revokedAt = *value
+	}
+
+	return revokedAt, true, nil
+}
+
+// FindDeactivatedBefore lista até limit contas desativadas antes de cutoff,
+// já fora do período de reativação. Só os campos usados na exclusão são lidos.
+func (u *UserRepository) FindDeactivatedBefore(ctx context.Context, cutoff time.Time, limit int) ([]model.User, error) {
+	defer metrics.ObserveQuery("UserRepository", "FindDeactivatedBefore")()
+	ctx, span := tracing.Start(ctx, "UserRepository.FindDeactivatedBefore")
+	defer span.End()
+
+	rows, err := u.db.Query(ctx, `
+		SELECT id, photo_url, banner, created_at, deactivated_at
+		FROM db_nexa.tb_user
+		WHERE deactivated_at IS NOT NULL AND deactivated_at < $1
+		ORDER BY deactivated_at
+		LIMIT $2
+	`, cutoff, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find deactivated users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []model.User
+	for rows.Next() {
+		var user model.User
+		if err := rows.Scan(&user.ID, &user.PhotoUrl, &user.Banner, &user.CreatedAt, &user.DeactivatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan deactivated user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+// HardDelete apaga o usuário desativado (o resto sai em cascata) e grava o
+// registro anônimo em tb_deleted_user na mesma transação. Retorna
+// ErrUserNotFound se a conta foi reativada nesse meio tempo.
+func (u *UserRepository) HardDelete(ctx context.Context, user *model.User) error {
+	defer metrics.ObserveQuery("UserRepository", "HardDelete")()
+	ctx, span := tracing.Start(ctx, "UserRepository.HardDelete")
+	defer span.End()
+
+	tx, err := u.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, "DELETE FROM db_nexa.tb_user WHERE id = $1 AND deactivated_at IS NOT NULL", user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+
+	subjectHash := sha256.Sum256([]byte(user.ID))
+	_, err = tx.Exec(ctx, `
+		INSERT INTO db_nexa.tb_deleted_user (subject_hash, created_month, deactivated_at)
+		VALUES ($1, date_trunc('month', $2::timestamptz)::date, $3)
+		ON CONFLICT (subject_hash) DO NOTHING
+	`, hex.EncodeToString(subjectHash[:]), user.CreatedAt, user.DeactivatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to write deletion record: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit user deletion: %w", err)
+	}
+
+	return nil
+}
+
+// uniqueViolation traduz violações dos índices únicos de tb_user, que cobrem
+// a corrida entre a checagem de disponibilidade e a escrita.
+func uniqueViolation(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		switch pgErr.ConstraintName {
+		case "ux_tb_user_email":
+			return ErrEmailTaken
+		case "ux_tb_user_username":
+			return ErrUsernameTaken
+		}
+	}
+
+	return err
+}
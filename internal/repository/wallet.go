@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"nexa/internal/metrics"
+	"nexa/internal/model"
+	"nexa/internal/money"
+	"nexa/internal/tracing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const walletColumns = "id, user_id, name, currency, total, created_at"
+
+type WalletRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewWalletRepository(db *pgxpool.Pool) *WalletRepository {
+	return &WalletRepository{
+		db: db,
+	}
+}
+
+func scanWallet(row pgx.Row) (*model.Wallet, error) {
+	var wallet model.Wallet
+	err := row.Scan(&wallet.ID, &wallet.UserID, &wallet.Name, &wallet.Currency, &wallet.Total, &wallet.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &wallet, nil
+}
+
+// FindByID só encontra carteiras do próprio usuário.
+func (r *WalletRepository) FindByID(ctx context.Context, id, userID string) (*model.Wallet, error) {
+	defer metrics.ObserveQuery("WalletRepository", "FindByID")()
+	ctx, span := tracing.Start(ctx, "WalletRepository.FindByID")
+	defer span.End()
+
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, nil
+	}
+
+	wallet, err := scanWallet(r.db.QueryRow(ctx,
+		"SELECT "+walletColumns+" FROM db_nexa.tb_wallet WHERE id = $1 AND user_id = $2", id, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find wallet: %w", err)
+	}
+
+	return wallet, nil
+}
+
+// FindByUserID lista as carteiras do usuário na ordem de criação.
+func (r *WalletRepository) FindByUserID(ctx context.Context, userID string) ([]model.Wallet, error) {
+	defer metrics.ObserveQuery("WalletRepository", "FindByUserID")()
+	ctx, span := tracing.Start(ctx, "WalletRepository.FindByUserID")
+	defer span.End()
+
+	rows, err := r.db.Query(ctx,
+		"SELECT "+walletColumns+" FROM db_nexa.tb_wallet WHERE user_id = $1 ORDER BY created_at, id", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list wallets: %w", err)
+	}
+	defer rows.Close()
+
+	wallets := []model.Wallet{}
+	for rows.Next() {
+		wallet, err := scanWallet(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan wallet: %w", err)
+		}
+		wallets = append(wallets, *wallet)
+	}
+
+	return wallets, rows.Err()
+}
+
+// Create cria uma carteira vazia na moeda escolhida.
+func (r *WalletRepository) Create(ctx context.Context, wallet *model.Wallet) error {
+	defer metrics.ObserveQuery("WalletRepository", "Create")()
+	ctx, span := tracing.Start(ctx, "WalletRepository.Create")
+	defer span.End()
+
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO db_nexa.tb_wallet (user_id, name, currency)
+		VALUES ($1, $2, $3)
+		RETURNING id, total, created_at
+	`, wallet.UserID, wallet.Name, wallet.Currency).Scan(&wallet.ID, &wallet.Total, &wallet.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create wallet: %w", err)
+	}
+
+	return nil
+}
+
+// BalancesAt devolve o saldo de cada carteira do usuário ao fim do dia date,
+// na moeda da carteira.
+func (r *WalletRepository) BalancesAt(ctx context.Context, userID string, date time.Time) ([]model.WalletBalance, error) {
+	defer metrics.ObserveQuery("WalletRepository", "BalancesAt")()
+	ctx, span := tracing.Start(ctx, "WalletRepository.BalancesAt")
+	defer span.End()
+
+	rows, err := r.db.Query(ctx, `
+		SELECT w.id, w.name, w.currency, w.total - COALESCE((
+			SELECT SUM(CASE WHEN t.type = 'expense' THEN -t.amount ELSE t.amount END)
+			FROM db_nexa.tb_transaction t
+			WHERE t.wallet_id = w.id AND t.date > $2
+		), 0)
+		FROM db_nexa.tb_wallet w
+		WHERE w.user_id = $1
+		ORDER BY w.created_at, w.id
+	`, userID, date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute wallet balances: %w", err)
+	}
+	defer rows.Close()
+
+	balances := []model.WalletBalance{}
+	for rows.Next() {
+		var balance model.WalletBalance
+		if err := rows.Scan(&balance.WalletID, &balance.Name, &balance.Currency, &balance.Balance); err != nil {
+			return nil, fmt.Errorf("failed to scan wallet balance: %w", err)
+		}
+		balances = append(balances, balance)
+	}
+
+	return balances, rows.Err()
+}
+
+// BalanceAt devolve o saldo da carteira ao fim do dia date: o total atual sem
+// os lançamentos posteriores.
+func (r *WalletRepository) BalanceAt(ctx context.Context, walletID string, date time.Time) (money.Amount, error) {
+	defer metrics.ObserveQuery("WalletRepository", "BalanceAt")()
+	ctx, span := tracing.Start(ctx, "WalletRepository.BalanceAt")
+	defer span.End()
+
+	return balanceAt(ctx, r.db, walletID, date)
+}
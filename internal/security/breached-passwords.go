@@ -0,0 +1,51 @@
+package security
+
+import (
+	"bufio"
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"strings"
+	"sync"
+)
+
+//go:embed breached-passwords.txt
+var breachedPasswordsFile string
+
+// BreachedPasswords consulta a lista embarcada no formato de k-anonimato do
+// HIBP: o SHA-1 é dividido em prefixo de 5 caracteres e sufixo de 35, e a
+// busca é feita apenas entre os sufixos daquele prefixo.
+type BreachedPasswords struct {
+	once     sync.Once
+	prefixes map[string]map[string]struct{}
+}
+
+var breachedPasswords = &BreachedPasswords{}
+
+func (b *BreachedPasswords) load() {
+	b.prefixes = make(map[string]map[string]struct{})
+
+	scanner := bufio.NewScanner(strings.NewReader(breachedPasswordsFile))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) != sha1.Size*2 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		prefix, suffix := line[:5], line[5:]
+		if b.prefixes[prefix] == nil {
+			b.prefixes[prefix] = make(map[string]struct{})
+		}
+		b.prefixes[prefix][suffix] = struct{}{}
+	}
+}
+
+func (b *BreachedPasswords) Contains(password string) bool {
+	b.once.Do(b.load)
+
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	_, found := b.prefixes[hash[:5]][hash[5:]]
+	return found
+}
@@ -8,7 +8,6 @@ import (
 	"encoding/base64"
 	"encoding/pem"
 	"fmt"
-	"os"
 	"strings"
 )
 
@@ -16,8 +15,7 @@ func Base64Decode(input string) ([]byte, error) {
 	return base64.StdEncoding.DecodeString(input)
 }
 
-func LoadPrivateKeyFromEnv() (interface{}, error) {
-	b64 := os.Getenv("PRIVATE_KEY")
+func LoadPrivateKey(b64 string) (interface{}, error) {
 	if b64 == "" {
 		return nil, fmt.Errorf("env PRIVATE_KEY não definida")
 	}
@@ -74,8 +72,7 @@ func LoadPrivateKeyFromEnv() (interface{}, error) {
 	}
 }
 
-func LoadPublicKeyPEMFlatString() (string, error) {
-	b64 := os.Getenv("PUBLIC_KEY")
+func LoadPublicKeyPEMFlatString(b64 string) (string, error) {
 	if b64 == "" {
 		return "", fmt.Errorf("variável de ambiente PUBLIC_KEY não definida")
 	}
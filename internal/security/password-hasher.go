@@ -0,0 +1,104 @@
+package security
+
+import (
+	"errors"
+	"fmt"
+	"nexa/internal/config"
+	"strings"
+)
+
+var ErrPasswordMismatch = errors.New("senha inválida")
+
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+)
+
+// PasswordHasher gera e verifica hashes codificados. O hash carrega o
+// identificador do algoritmo e seus parâmetros, então hashes antigos
+// continuam verificáveis depois de uma troca de configuração.
+type PasswordHasher interface {
+	Algorithm() string
+	Hash(password string) (string, error)
+	Verify(password, encodedHash string) (bool, error)
+	NeedsRehash(encodedHash string) bool
+}
+
+// PasswordManager usa o hasher padrão para novos hashes e delega a
+// verificação ao hasher que reconhece o prefixo do hash armazenado.
+type PasswordManager struct {
+	Default PasswordHasher
+	hashers []PasswordHasher
+}
+
+func NewPasswordManager(defaultHasher PasswordHasher, legacy ...PasswordHasher) *PasswordManager {
+	return &PasswordManager{
+		Default: defaultHasher,
+		hashers: append([]PasswordHasher{defaultHasher}, legacy...),
+	}
+}
+
+func (pm *PasswordManager) Hash(password string) (string, error) {
+	return pm.Default.Hash(password)
+}
+
+// Verify confere a senha e informa se o hash deve ser regravado com a
+// configuração atual (algoritmo diferente ou parâmetros mais fracos).
+// Retorna ErrPasswordMismatch quando a senha não confere.
+func (pm *PasswordManager) Verify(password, encodedHash string) (bool, error) {
+	hasher, err := pm.hasherFor(encodedHash)
+	if err != nil {
+		return false, err
+	}
+
+	ok, err := hasher.Verify(password, encodedHash)
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		return false, ErrPasswordMismatch
+	}
+
+	return hasher.Algorithm() != pm.Default.Algorithm() || pm.Default.NeedsRehash(encodedHash), nil
+}
+
+func (pm *PasswordManager) hasherFor(encodedHash string) (PasswordHasher, error) {
+	algorithm := DetectAlgorithm(encodedHash)
+	for _, h := range pm.hashers {
+		if h.Algorithm() == algorithm {
+			return h, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unsupported password hash algorithm: %q", algorithm)
+}
+
+func DetectAlgorithm(encodedHash string) string {
+	switch {
+	case strings.HasPrefix(encodedHash, "$argon2id$"):
+		return AlgorithmArgon2id
+	case strings.HasPrefix(encodedHash, "$2a$"),
+		strings.HasPrefix(encodedHash, "$2b$"),
+		strings.HasPrefix(encodedHash, "$2y$"):
+		return AlgorithmBcrypt
+	default:
+		return ""
+	}
+}
+
+func InitPasswordManager(cfg config.PasswordConfig) *PasswordManager {
+	argon2id := NewArgon2idHasher(Argon2idParams{
+		Memory:      uint32(cfg.Argon2MemoryKiB),
+		Iterations:  uint32(cfg.Argon2Iterations),
+		Parallelism: uint8(cfg.Argon2Parallelism),
+		SaltLength:  DefaultArgon2idParams.SaltLength,
+		KeyLength:   DefaultArgon2idParams.KeyLength,
+	})
+	bcryptHasher := NewBcryptHasher(cfg.BcryptCost)
+
+	if cfg.Algorithm == AlgorithmBcrypt {
+		return NewPasswordManager(bcryptHasher, argon2id)
+	}
+
+	return NewPasswordManager(argon2id, bcryptHasher)
+}
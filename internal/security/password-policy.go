@@ -0,0 +1,183 @@
+package security
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/dlclark/regexp2"
+)
+
+const (
+	minUniquePasswordChars = 5
+	maxRepeatedCharRun     = 3
+	minSequenceLength      = 4
+	minPersonalTokenLength = 3
+)
+
+var passwordCompositionRegex = regexp2.MustCompile(`^(?=.*[A-Z])(?=.*\d)(?=.*[!@#\$%\^&\*\(\)_\+\-=\[\]{};':"\\|,.<>\/?]).{6,}$`, 0)
+
+var keyboardRows = []string{
+	"qwertyuiop",
+	"asdfghjkl",
+	"zxcvbnm",
+	"1234567890",
+	"1qaz2wsx3edc4rfv5tgb6yhn7ujm8ik9ol0p",
+}
+
+var leetReplacer = strings.NewReplacer(
+	"0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "@", "a", "$", "s",
+)
+
+// PasswordPolicy agrupa as regras aplicadas a uma nova senha. Cada violação é
+// devolvida como uma chave do catálogo utils.HTTPErrors.
+type PasswordPolicy struct {
+	Breached *BreachedPasswords
+}
+
+func NewPasswordPolicy() *PasswordPolicy {
+	return &PasswordPolicy{Breached: breachedPasswords}
+}
+
+// PersonalInfo são os dados do usuário que não podem aparecer na senha.
+type PersonalInfo struct {
+	Name     string
+	Username string
+	Email    string
+}
+
+func (p *PasswordPolicy) Check(password string, info PersonalInfo) []string {
+	var violations []string
+
+	if match, _ := passwordCompositionRegex.MatchString(password); !match {
+		violations = append(violations, "INVALID_PASSWORD")
+	}
+
+	if p.Breached.Contains(password) {
+		violations = append(violations, "BREACHED_PASSWORD")
+	}
+
+	if containsPersonalInfo(password, info) {
+		violations = append(violations, "PASSWORD_CONTAINS_PERSONAL_INFO")
+	}
+
+	if isLowEntropy(password) {
+		violations = append(violations, "LOW_ENTROPY_PASSWORD")
+	}
+
+	return violations
+}
+
+func containsPersonalInfo(password string, info PersonalInfo) bool {
+	lowered := strings.ToLower(password)
+	normalized := leetReplacer.Replace(lowered)
+
+	for _, token := range personalTokens(info) {
+		if strings.Contains(lowered, token) || strings.Contains(normalized, token) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func personalTokens(info PersonalInfo) []string {
+	var tokens []string
+
+	localPart, _, _ := strings.Cut(info.Email, "@")
+	fields := strings.Fields(info.Name)
+	fields = append(fields, info.Username, localPart)
+	fields = append(fields, strings.FieldsFunc(localPart, func(r rune) bool {
+		return r == '.' || r == '_' || r == '-' || r == '+'
+	})...)
+
+	for _, field := range fields {
+		token := strings.ToLower(strings.TrimSpace(field))
+		if len([]rune(token)) >= minPersonalTokenLength {
+			tokens = append(tokens, token)
+		}
+	}
+
+	return tokens
+}
+
+func isLowEntropy(password string) bool {
+	lowered := strings.ToLower(password)
+
+	unique := make(map[rune]struct{})
+	for _, r := range lowered {
+		unique[r] = struct{}{}
+	}
+	if len(unique) < minUniquePasswordChars {
+		return true
+	}
+
+	return hasRepeatedRun(lowered) || hasSequence(lowered) || hasKeyboardPattern(lowered)
+}
+
+func hasRepeatedRun(password string) bool {
+	runes := []rune(password)
+	run := 1
+	for i := 1; i < len(runes); i++ {
+		if runes[i] == runes[i-1] {
+			run++
+			if run > maxRepeatedCharRun {
+				return true
+			}
+			continue
+		}
+		run = 1
+	}
+
+	return false
+}
+
+// hasSequence detecta sequências alfanuméricas como "abcd" ou "4321".
+func hasSequence(password string) bool {
+	runes := []rune(password)
+	ascending, descending := 1, 1
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+		sameClass := (unicode.IsDigit(prev) && unicode.IsDigit(cur)) || (unicode.IsLetter(prev) && unicode.IsLetter(cur))
+
+		if sameClass && cur == prev+1 {
+			ascending++
+		} else {
+			ascending = 1
+		}
+
+		if sameClass && cur == prev-1 {
+			descending++
+		} else {
+			descending = 1
+		}
+
+		if ascending >= minSequenceLength || descending >= minSequenceLength {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasKeyboardPattern(password string) bool {
+	runes := []rune(password)
+	for i := 0; i+minSequenceLength <= len(runes); i++ {
+		chunk := string(runes[i : i+minSequenceLength])
+		reversed := reverse(chunk)
+		for _, row := range keyboardRows {
+			if strings.Contains(row, chunk) || strings.Contains(row, reversed) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func reverse(s string) string {
+	runes := []rune(s)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
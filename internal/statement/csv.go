@@ -0,0 +1,577 @@
+package statement
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"nexa/internal/money"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ErrInvalidMapping indica um CSVMapping incompleto ou com valores
+// desconhecidos.
+var ErrInvalidMapping = errors.New("invalid csv mapping")
+
+// dateFormats são os formatos de data aceitos no mapeamento, com o layout Go
+// equivalente (dia e mês com um ou dois dígitos).
+var dateFormats = map[string]string{
+	"dd/mm/yyyy": "2/1/2006",
+	"dd/mm/yy":   "2/1/06",
+	"dd-mm-yyyy": "2-1-2006",
+	"dd.mm.yyyy": "2.1.2006",
+	"yyyy-mm-dd": "2006-1-2",
+	"yyyy/mm/dd": "2006/1/2",
+	"mm/dd/yyyy": "1/2/2006",
+}
+
+// dateFormatOrder é a ordem de tentativa na detecção: o padrão brasileiro
+// vem antes do americano.
+var dateFormatOrder = []string{"dd/mm/yyyy", "yyyy-mm-dd", "dd/mm/yy", "dd-mm-yyyy", "dd.mm.yyyy", "yyyy/mm/dd", "mm/dd/yyyy"}
+
+var delimiters = []string{";", ",", "\t", "|"}
+
+// CSVMapping diz como ler um CSV: colunas (a partir de 0) e formatos. O valor
+// vem de AmountColumn (com sinal) ou de DebitColumn/CreditColumn. É salvo por
+// usuário e banco para ser reaproveitado nas próximas importações.
+type CSVMapping struct {
+	Encoding          string `json:"encoding"`
+	Delimiter         string `json:"delimiter"`
+	SkipRows          int    `json:"skipRows"`
+	HasHeader         bool   `json:"hasHeader"`
+	DateColumn        *int   `json:"dateColumn"`
+	DescriptionColumn *int   `json:"descriptionColumn"`
+	AmountColumn      *int   `json:"amountColumn,omitempty"`
+	DebitColumn       *int   `json:"debitColumn,omitempty"`
+	CreditColumn      *int   `json:"creditColumn,omitempty"`
+	// ExternalIDColumn é um identificador único do banco (ex.: o
+	// "Identificador" do Nubank), usado na detecção de duplicados.
+	ExternalIDColumn *int   `json:"externalIdColumn,omitempty"`
+	DateFormat       string `json:"dateFormat"`
+	DecimalSeparator string `json:"decimalSeparator"`
+	// InvertSign troca o sinal dos valores, para faturas de cartão que
+	// listam compras como positivas.
+	InvertSign bool `json:"invertSign"`
+}
+
+func (m *CSVMapping) Validate() error {
+	if m.Encoding != "" && m.Encoding != EncodingUTF8 && m.Encoding != EncodingWindows1252 {
+		return ErrInvalidMapping
+	}
+	if len([]rune(m.Delimiter)) != 1 || m.Delimiter == "\"" || m.Delimiter == "\n" {
+		return ErrInvalidMapping
+	}
+	if m.SkipRows < 0 || m.DateColumn == nil || m.DescriptionColumn == nil {
+		return ErrInvalidMapping
+	}
+	if m.AmountColumn == nil && m.DebitColumn == nil && m.CreditColumn == nil {
+		return ErrInvalidMapping
+	}
+	for _, column := range []*int{m.DateColumn, m.DescriptionColumn, m.AmountColumn, m.DebitColumn, m.CreditColumn, m.ExternalIDColumn} {
+		if column != nil && *column < 0 {
+			return ErrInvalidMapping
+		}
+	}
+	if _, ok := dateFormats[m.DateFormat]; !ok {
+		return ErrInvalidMapping
+	}
+	if m.DecimalSeparator != "," && m.DecimalSeparator != "." {
+		return ErrInvalidMapping
+	}
+
+	return nil
+}
+
+// CSVDetection é o resultado da análise de um CSV: o mapeamento sugerido, o
+// cabeçalho (se houver) e algumas linhas para o usuário conferir.
+type CSVDetection struct {
+	Mapping CSVMapping `json:"mapping"`
+	Header  []string   `json:"header,omitempty"`
+	Sample  [][]string `json:"sample"`
+}
+
+const (
+	detectionLines = 50
+	sampleRows     = 10
+)
+
+// DetectCSV sugere um mapeamento: encoding, delimitador, linhas de
+// cabeçalho do banco a pular, colunas e formatos de data e de valor.
+func DetectCSV(data []byte) (*CSVDetection, error) {
+	text, encoding := Decode(data, "")
+
+	delimiter := detectDelimiter(text)
+	records, err := readRecords(text, delimiter)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	width := modeWidth(records)
+	start := 0
+	for start < len(records) && len(records[start].fields) != width {
+		start++
+	}
+
+	detection := &CSVDetection{
+		Mapping: CSVMapping{
+			Encoding:         encoding,
+			Delimiter:        delimiter,
+			SkipRows:         start,
+			DateFormat:       "dd/mm/yyyy",
+			DecimalSeparator: ",",
+		},
+	}
+
+	body := records[start:]
+	if len(body) > 1 && looksLikeHeader(body[0].fields, body[1].fields) {
+		detection.Mapping.HasHeader = true
+		detection.Header = body[0].fields
+		body = body[1:]
+	}
+
+	rows := make([][]string, 0, sampleRows)
+	for _, record := range body {
+		if len(rows) == cap(rows) {
+			break
+		}
+		rows = append(rows, record.fields)
+	}
+	detection.Sample = rows
+
+	suggestColumns(&detection.Mapping, detection.Header, rows)
+
+	return detection, nil
+}
+
+// ParseCSV lê o arquivo inteiro com o mapeamento. Linhas vazias são
+// ignoradas; as inválidas voltam com o código do erro.
+func ParseCSV(data []byte, m CSVMapping) ([]Row, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+
+	text, _ := Decode(data, m.Encoding)
+	records, err := readRecords(text, m.Delimiter)
+	if err != nil {
+		return nil, err
+	}
+
+	skip := m.SkipRows
+	if m.HasHeader {
+		skip++
+	}
+	if skip > len(records) {
+		skip = len(records)
+	}
+
+	layout := dateFormats[m.DateFormat]
+	decimal := m.DecimalSeparator[0]
+
+	rows := make([]Row, 0, len(records)-skip)
+	for _, record := range records[skip:] {
+		if isBlank(record.fields) {
+			continue
+		}
+
+		row := Row{Line: record.line}
+		entry, code := parseRecord(record.fields, m, layout, decimal)
+		if code != "" {
+			row.Error = code
+		} else {
+			row.Entry = entry
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func parseRecord(fields []string, m CSVMapping, layout string, decimal byte) (*Entry, string) {
+	cell := func(column *int) (string, bool) {
+		if column == nil {
+			return "", true
+		}
+		if *column >= len(fields) {
+			return "", false
+		}
+		return strings.TrimSpace(fields[*column]), true
+	}
+
+	rawDate, ok := cell(m.DateColumn)
+	if !ok {
+		return nil, ErrMissingColumn
+	}
+	date, err := parseDate(rawDate, layout)
+	if err != nil {
+		return nil, ErrInvalidDate
+	}
+
+	description, ok := cell(m.DescriptionColumn)
+	if !ok {
+		return nil, ErrMissingColumn
+	}
+
+	var amount money.Amount
+	if m.AmountColumn != nil {
+		raw, ok := cell(m.AmountColumn)
+		if !ok {
+			return nil, ErrMissingColumn
+		}
+		if raw != "" {
+			amount, err = money.ParseLocalized(raw, decimal)
+			if err != nil {
+				return nil, ErrInvalidAmount
+			}
+		}
+	} else {
+		debit, debitOK := cell(m.DebitColumn)
+		credit, creditOK := cell(m.CreditColumn)
+		if !debitOK || !creditOK {
+			return nil, ErrMissingColumn
+		}
+		if debit != "" {
+			value, err := money.ParseLocalized(debit, decimal)
+			if err != nil {
+				return nil, ErrInvalidAmount
+			}
+			amount -= value.Abs()
+		}
+		if credit != "" {
+			value, err := money.ParseLocalized(credit, decimal)
+			if err != nil {
+				return nil, ErrInvalidAmount
+			}
+			amount += value.Abs()
+		}
+	}
+
+	if m.InvertSign {
+		amount = -amount
+	}
+	if amount == 0 {
+		// Linhas de saldo e informativas dos bancos costumam vir zeradas.
+		return nil, ErrZeroAmount
+	}
+
+	externalID, ok := cell(m.ExternalIDColumn)
+	if !ok {
+		return nil, ErrMissingColumn
+	}
+
+	return &Entry{Date: date, Amount: amount, Description: description, ExternalID: externalID}, ""
+}
+
+// parseDate aceita horário depois da data ("31/01/2024 10:15").
+func parseDate(raw, layout string) (time.Time, error) {
+	if date, _, found := strings.Cut(raw, " "); found {
+		raw = date
+	}
+	if date, _, found := strings.Cut(raw, "T"); found {
+		raw = date
+	}
+
+	return time.Parse(layout, raw)
+}
+
+type record struct {
+	line   int
+	fields []string
+}
+
+func readRecords(text, delimiter string) ([]record, error) {
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.Comma = []rune(delimiter)[0]
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	var records []record
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		records = append(records, record{line: line, fields: fields})
+	}
+}
+
+// detectDelimiter escolhe o delimitador que divide as primeiras linhas no
+// mesmo número de colunas (maior que 1) mais vezes. Empates favorecem quem
+// gera mais colunas (a vírgula decimal também "divide" as linhas) e depois o
+// ";", padrão das planilhas em pt-BR.
+func detectDelimiter(text string) string {
+	lines := strings.SplitN(text, "\n", detectionLines+1)
+	if len(lines) > detectionLines {
+		lines = lines[:detectionLines]
+	}
+	sample := strings.Join(lines, "\n")
+
+	best, bestScore, bestWidth := delimiters[0], 0, 0
+	for _, delimiter := range delimiters {
+		records, err := readRecords(sample, delimiter)
+		if err != nil {
+			continue
+		}
+
+		width := modeWidth(records)
+		if width < 2 {
+			continue
+		}
+
+		score := 0
+		for _, record := range records {
+			if len(record.fields) == width {
+				score++
+			}
+		}
+		if score > bestScore || (score == bestScore && width > bestWidth) {
+			best, bestScore, bestWidth = delimiter, score, width
+		}
+	}
+
+	return best
+}
+
+// modeWidth devolve o número de colunas mais comum; em empate, o maior.
+func modeWidth(records []record) int {
+	counts := map[int]int{}
+	for _, record := range records {
+		counts[len(record.fields)]++
+	}
+
+	width, best := 0, 0
+	for w, count := range counts {
+		if count > best || (count == best && w > width) {
+			width, best = w, count
+		}
+	}
+
+	return width
+}
+
+func looksLikeHeader(first, second []string) bool {
+	return !hasDateOrAmount(first) && hasDateOrAmount(second)
+}
+
+func hasDateOrAmount(fields []string) bool {
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		if detectDateFormat([]string{field}) != "" {
+			return true
+		}
+		if _, err := money.ParseLocalized(field, ','); err == nil && field != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isBlank(fields []string) bool {
+	for _, field := range fields {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// detectDateFormat devolve o primeiro formato que lê todos os valores.
+func detectDateFormat(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	for _, format := range dateFormatOrder {
+		ok := true
+		for _, value := range values {
+			if _, err := parseDate(strings.TrimSpace(value), dateFormats[format]); err != nil {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return format
+		}
+	}
+
+	return ""
+}
+
+var (
+	commaDecimal = regexp.MustCompile(`,\d{1,2}\)?-?\s*[CD]?$`)
+	dotDecimal   = regexp.MustCompile(`\.\d{1,2}\)?-?\s*[CD]?$`)
+)
+
+// detectDecimalSeparator olha a última pontuação antes dos centavos.
+func detectDecimalSeparator(values []string) string {
+	comma, dot := 0, 0
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		switch {
+		case commaDecimal.MatchString(value):
+			comma++
+		case dotDecimal.MatchString(value):
+			dot++
+		}
+	}
+
+	if dot > comma {
+		return "."
+	}
+	return ","
+}
+
+var headerKeywords = map[string][]string{
+	"date":        {"data", "date", "dt"},
+	"description": {"descri", "hist", "lançamento", "lancamento", "memo", "estabelecimento", "title", "título", "titulo"},
+	"amount":      {"valor", "amount", "value", "quantia", "montante"},
+	"debit":       {"débito", "debito", "saída", "saida", "debit"},
+	"credit":      {"crédito", "credito", "entrada", "credit"},
+	"externalID":  {"identificador", "fitid", "transaction id"},
+	// Colunas de saldo nunca são o valor do lançamento.
+	"ignored": {"saldo", "balance"},
+}
+
+// suggestColumns preenche as colunas pelo cabeçalho e, no que faltar, pelo
+// conteúdo das linhas de amostra.
+func suggestColumns(m *CSVMapping, header []string, rows [][]string) {
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		for _, kind := range []string{"ignored", "externalID", "debit", "credit", "date", "amount", "description"} {
+			if _, taken := columns[kind]; taken {
+				continue
+			}
+			if matchesAny(name, headerKeywords[kind]) {
+				if kind != "ignored" {
+					columns[kind] = i
+				}
+				break
+			}
+		}
+	}
+
+	values := func(column int) []string {
+		var out []string
+		for _, row := range rows {
+			if column < len(row) && strings.TrimSpace(row[column]) != "" {
+				out = append(out, row[column])
+			}
+		}
+		return out
+	}
+
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+
+	if _, ok := columns["date"]; !ok {
+		for i := 0; i < width; i++ {
+			if detectDateFormat(values(i)) != "" {
+				columns["date"] = i
+				break
+			}
+		}
+	}
+
+	_, hasDebit := columns["debit"]
+	_, hasCredit := columns["credit"]
+	if _, ok := columns["amount"]; !ok && !(hasDebit && hasCredit) {
+		for i := width - 1; i >= 0; i-- {
+			if isAssigned(columns, i) || isIgnored(header, i) || len(values(i)) == 0 {
+				continue
+			}
+			if allAmounts(values(i)) {
+				columns["amount"] = i
+				break
+			}
+		}
+	}
+
+	if _, ok := columns["description"]; !ok {
+		longest := 0
+		for i := 0; i < width; i++ {
+			if isAssigned(columns, i) || isIgnored(header, i) {
+				continue
+			}
+			total := 0
+			for _, value := range values(i) {
+				total += len(value)
+			}
+			if total > longest {
+				longest = total
+				columns["description"] = i
+			}
+		}
+	}
+
+	if column, ok := columns["date"]; ok {
+		m.DateColumn = &column
+		if format := detectDateFormat(values(column)); format != "" {
+			m.DateFormat = format
+		}
+	}
+	if column, ok := columns["description"]; ok {
+		m.DescriptionColumn = &column
+	}
+	if column, ok := columns["externalID"]; ok {
+		m.ExternalIDColumn = &column
+	}
+
+	var amountValues []string
+	if column, ok := columns["amount"]; ok {
+		m.AmountColumn = &column
+		amountValues = values(column)
+	} else if hasDebit && hasCredit {
+		debit, credit := columns["debit"], columns["credit"]
+		m.DebitColumn, m.CreditColumn = &debit, &credit
+		amountValues = append(values(debit), values(credit)...)
+	}
+	m.DecimalSeparator = detectDecimalSeparator(amountValues)
+}
+
+func matchesAny(name string, keywords []string) bool {
+	for _, keyword := range keywords {
+		if strings.HasPrefix(name, keyword) || strings.Contains(name, " "+keyword) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func allAmounts(values []string) bool {
+	separator := detectDecimalSeparator(values)[0]
+	for _, value := range values {
+		if _, err := money.ParseLocalized(value, separator); err != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isIgnored(header []string, column int) bool {
+	return column < len(header) && matchesAny(strings.ToLower(strings.TrimSpace(header[column])), headerKeywords["ignored"])
+}
+
+func isAssigned(columns map[string]int, column int) bool {
+	for _, assigned := range columns {
+		if assigned == column {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,201 @@
+package statement
+
+import (
+	"strings"
+	"testing"
+)
+
+// none marca uma coluna que a detecção não deve sugerir.
+const none = -1
+
+func column(p *int) int {
+	if p == nil {
+		return none
+	}
+	return *p
+}
+
+func TestDetectCSV(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+
+		encoding    string
+		delimiter   string
+		skipRows    int
+		hasHeader   bool
+		date        int
+		description int
+		amount      int
+		debit       int
+		credit      int
+		externalID  int
+		dateFormat  string
+		decimal     string
+	}{
+		{
+			name:      "nubank",
+			data:      "Data,Valor,Identificador,Descrição\n31/01/2024,-12.50,a1,Padaria\n01/02/2024,5000.00,b2,Salário\n",
+			encoding:  EncodingUTF8,
+			delimiter: ",", hasHeader: true,
+			date: 0, description: 3, amount: 1, debit: none, credit: none, externalID: 2,
+			dateFormat: "dd/mm/yyyy", decimal: ".",
+		},
+		{
+			name: "bank preamble and balance column",
+			data: "Extrato Conta Corrente\nAgência: 1234;Conta: 5678\n\n" +
+				"Data;Histórico;Valor;Saldo\n" +
+				"31/01/2024;PIX ENVIADO;-1.234,56;100,00\n" +
+				"01/02/2024;SALARIO;5.000,00;5.100,00\n" +
+				"02/02/2024;TARIFA;-12,90;5.087,10\n",
+			encoding:  EncodingUTF8,
+			delimiter: ";", skipRows: 2, hasHeader: true,
+			date: 0, description: 1, amount: 2, debit: none, credit: none, externalID: none,
+			dateFormat: "dd/mm/yyyy", decimal: ",",
+		},
+		{
+			name: "debit and credit columns",
+			data: "Data\tDescrição\tDébito\tCrédito\n" +
+				"31/01/2024\tMercado\t150,00\t\n" +
+				"01/02/2024\tSalário\t\t3.000,00\n",
+			encoding:  EncodingUTF8,
+			delimiter: "\t", hasHeader: true,
+			date: 0, description: 1, amount: none, debit: 2, credit: 3, externalID: none,
+			dateFormat: "dd/mm/yyyy", decimal: ",",
+		},
+		{
+			name:      "no header",
+			data:      "2024-01-31|Mercado do bairro|-12.50\n2024-02-01|Cafe|-3.00\n",
+			encoding:  EncodingUTF8,
+			delimiter: "|",
+			date:      0, description: 1, amount: 2, debit: none, credit: none, externalID: none,
+			dateFormat: "yyyy-mm-dd", decimal: ".",
+		},
+		{
+			name:      "month first with grouped thousands",
+			data:      "01/31/2024,Coffee,-3.50\n02/15/2024,Rent,\"-1,200.00\"\n",
+			encoding:  EncodingUTF8,
+			delimiter: ",",
+			date:      0, description: 1, amount: 2, debit: none, credit: none, externalID: none,
+			dateFormat: "mm/dd/yyyy", decimal: ".",
+		},
+		{
+			name:      "latin-1",
+			data:      "Data;Descri\xe7\xe3o;Valor\n31/01/2024;Padaria S\xe3o Jo\xe3o;-12,50\n01/02/2024;Pre\xe7o;3,00\n",
+			encoding:  EncodingWindows1252,
+			delimiter: ";", hasHeader: true,
+			date: 0, description: 1, amount: 2, debit: none, credit: none, externalID: none,
+			dateFormat: "dd/mm/yyyy", decimal: ",",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detection, err := DetectCSV([]byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			m := detection.Mapping
+			if m.Encoding != tt.encoding {
+				t.Errorf("encoding = %q, want %q", m.Encoding, tt.encoding)
+			}
+			if m.Delimiter != tt.delimiter {
+				t.Errorf("delimiter = %q, want %q", m.Delimiter, tt.delimiter)
+			}
+			if m.SkipRows != tt.skipRows {
+				t.Errorf("skipRows = %d, want %d", m.SkipRows, tt.skipRows)
+			}
+			if m.HasHeader != tt.hasHeader {
+				t.Errorf("hasHeader = %v, want %v", m.HasHeader, tt.hasHeader)
+			}
+			for _, c := range []struct {
+				name      string
+				got, want int
+			}{
+				{"date", column(m.DateColumn), tt.date},
+				{"description", column(m.DescriptionColumn), tt.description},
+				{"amount", column(m.AmountColumn), tt.amount},
+				{"debit", column(m.DebitColumn), tt.debit},
+				{"credit", column(m.CreditColumn), tt.credit},
+				{"externalID", column(m.ExternalIDColumn), tt.externalID},
+			} {
+				if c.got != c.want {
+					t.Errorf("%s column = %d, want %d", c.name, c.got, c.want)
+				}
+			}
+			if m.DateFormat != tt.dateFormat {
+				t.Errorf("dateFormat = %q, want %q", m.DateFormat, tt.dateFormat)
+			}
+			if m.DecimalSeparator != tt.decimal {
+				t.Errorf("decimalSeparator = %q, want %q", m.DecimalSeparator, tt.decimal)
+			}
+			if err := m.Validate(); err != nil {
+				t.Errorf("detected mapping is invalid: %v", err)
+			}
+		})
+	}
+}
+
+func TestParseCSVLatin1(t *testing.T) {
+	data := []byte("Data;Descri\xe7\xe3o;Valor\n31/01/2024;Padaria S\xe3o Jo\xe3o;-1.234,50\n01/02/2024;Pre\xe7o;3,00\n")
+
+	detection, err := DetectCSV(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(detection.Header, "|"); got != "Data|Descrição|Valor" {
+		t.Errorf("header = %q", got)
+	}
+
+	rows, err := ParseCSV(data, detection.Mapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkRows(t, rows, []expectedRow{
+		{date: "2024-01-31", amount: -123450, description: "Padaria São João"},
+		{date: "2024-02-01", amount: 300, description: "Preço"},
+	})
+}
+
+func TestParseCSVInvalidGrouping(t *testing.T) {
+	zero, one, two := 0, 1, 2
+	mapping := CSVMapping{
+		Delimiter:         ",",
+		DateColumn:        &zero,
+		DescriptionColumn: &one,
+		AmountColumn:      &two,
+		DateFormat:        "mm/dd/yyyy",
+		DecimalSeparator:  ".",
+	}
+
+	// "1,23" com ponto decimal não é um agrupamento de milhar válido.
+	rows, err := ParseCSV([]byte("01/31/2024,Coffee,\"1,23\"\n02/01/2024,Rent,\"1,200.00\"\n"), mapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkRows(t, rows, []expectedRow{
+		{err: ErrInvalidAmount},
+		{date: "2024-02-01", amount: 120000, description: "Rent"},
+	})
+}
+
+func TestDetectDecimalSeparator(t *testing.T) {
+	tests := []struct {
+		values []string
+		want   string
+	}{
+		{[]string{"-1.234,56", "12,00"}, ","},
+		{[]string{"1,234.56", "12.00"}, "."},
+		{[]string{"(12.50)", "150.00 D"}, "."},
+		{[]string{"1.234,56-", "150,00 C"}, ","},
+		{[]string{"100", "250"}, ","},
+		{nil, ","},
+	}
+
+	for _, tt := range tests {
+		if got := detectDecimalSeparator(tt.values); got != tt.want {
+			t.Errorf("detectDecimalSeparator(%q) = %q, want %q", tt.values, got, tt.want)
+		}
+	}
+}
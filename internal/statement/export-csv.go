@@ -0,0 +1,96 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"nexa/internal/i18n"
+	"strings"
+	"time"
+)
+
+type csvWriter struct {
+	w          *csv.Writer
+	out        io.Writer
+	options    ExportOptions
+	decimal    byte
+	dateLayout string
+	started    bool
+}
+
+// newCSVWriter escreve no padrão do locale: em pt-BR, ";" como delimitador,
+// vírgula decimal, datas dd/mm/aaaa e BOM para o Excel reconhecer o UTF-8;
+// nos demais, "," com ponto decimal e datas ISO.
+func newCSVWriter(out io.Writer, options ExportOptions) *csvWriter {
+	w := &csvWriter{
+		w:          csv.NewWriter(out),
+		out:        out,
+		options:    options,
+		decimal:    '.',
+		dateLayout: time.DateOnly,
+	}
+
+	if options.Locale == i18n.PtBR {
+		w.w.Comma = ';'
+		w.decimal = ','
+		w.dateLayout = "02/01/2006"
+	}
+
+	return w
+}
+
+func (w *csvWriter) start() error {
+	w.started = true
+
+	if w.options.Locale == i18n.PtBR {
+		if _, err := io.WriteString(w.out, "\ufeff"); err != nil {
+			return err
+		}
+	}
+
+	locale := w.options.Locale
+	return w.w.Write([]string{
+		i18n.Translate(locale, "EXPORT_DATE"),
+		i18n.Translate(locale, "EXPORT_TYPE"),
+		i18n.Translate(locale, "EXPORT_CATEGORY"),
+		i18n.Translate(locale, "EXPORT_DESCRIPTION"),
+		i18n.Translate(locale, "EXPORT_PAYMENT_METHOD"),
+		i18n.Translate(locale, "EXPORT_AMOUNT"),
+	})
+}
+
+func (w *csvWriter) Write(record *Record) error {
+	if !w.started {
+		if err := w.start(); err != nil {
+			return err
+		}
+	}
+
+	return w.w.Write([]string{
+		record.Date.Format(w.dateLayout),
+		i18n.Translate(w.options.Locale, "EXPORT_TYPE_"+record.Kind()),
+		escapeFormula(record.Category),
+		escapeFormula(record.Description),
+		escapeFormula(record.PaymentMethod),
+		record.Signed().Format(w.decimal),
+	})
+}
+
+// escapeFormula prefixa com "'" os textos que o Excel e o LibreOffice
+// interpretariam como fórmula ("=HYPERLINK(...)" numa descrição importada).
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func (w *csvWriter) Close() error {
+	if !w.started {
+		if err := w.start(); err != nil {
+			return err
+		}
+	}
+
+	w.w.Flush()
+	return w.w.Error()
+}
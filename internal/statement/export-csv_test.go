@@ -0,0 +1,59 @@
+package statement
+
+import (
+	"bytes"
+	"encoding/csv"
+	"nexa/internal/i18n"
+	"testing"
+	"time"
+)
+
+func TestCSVWriterEscapesFormulas(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"Mercado", "Mercado"},
+		{"", ""},
+		{"=HYPERLINK(\"http://x\")", "'=HYPERLINK(\"http://x\")"},
+		{"+55 11 99999-9999", "'+55 11 99999-9999"},
+		{"-2+3", "'-2+3"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\t=1", "'\t=1"},
+		{"\r=1", "'\r=1"},
+		{"a=1", "a=1"},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		writer := newCSVWriter(&out, ExportOptions{Locale: i18n.EnUS})
+		record := &Record{
+			Date:          time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+			Type:          "expense",
+			Amount:        1250,
+			Category:      tt.value,
+			Description:   tt.value,
+			PaymentMethod: tt.value,
+		}
+		if err := writer.Write(record); err != nil {
+			t.Fatal(err)
+		}
+		if err := writer.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		records, err := csv.NewReader(&out).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		row := records[1]
+		for _, cell := range []string{row[2], row[3], row[4]} {
+			if cell != tt.want {
+				t.Errorf("cell for %q = %q, want %q", tt.value, cell, tt.want)
+			}
+		}
+		if row[5] != "-12.50" {
+			t.Errorf("amount = %q, want -12.50", row[5])
+		}
+	}
+}
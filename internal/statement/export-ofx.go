@@ -0,0 +1,98 @@
+package statement
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ofxNameLength é o limite do campo NAME na especificação OFX.
+const ofxNameLength = 32
+
+type ofxWriter struct {
+	w       *bufio.Writer
+	options ExportOptions
+	started bool
+}
+
+// newOFXWriter escreve OFX 2.x (XML) de conta corrente. O FITID é o
+// identificador original do banco, quando o lançamento veio de uma
+// importação, ou o id do lançamento.
+func newOFXWriter(out io.Writer, options ExportOptions) *ofxWriter {
+	return &ofxWriter{w: bufio.NewWriter(out), options: options}
+}
+
+func ofxDate(t time.Time) string {
+	return t.Format("20060102") + "120000"
+}
+
+func (w *ofxWriter) start() {
+	w.started = true
+
+	currency := w.options.Currency
+	if currency == "" {
+		currency = "BRL"
+	}
+
+	fmt.Fprintf(w.w, `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+<OFX>
+<SIGNONMSGSRSV1><SONRS><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS><DTSERVER>%s</DTSERVER><LANGUAGE>POR</LANGUAGE></SONRS></SIGNONMSGSRSV1>
+<BANKMSGSRSV1><STMTTRNRS><TRNUID>1</TRNUID><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>
+<STMTRS><CURDEF>%s</CURDEF>
+<BANKACCTFROM><BANKID>NEXA</BANKID><ACCTID>%s</ACCTID><ACCTTYPE>CHECKING</ACCTTYPE></BANKACCTFROM>
+<BANKTRANLIST><DTSTART>%s</DTSTART><DTEND>%s</DTEND>
+`, time.Now().UTC().Format("20060102150405"), escapeXML(currency), escapeXML(w.options.AccountID),
+		ofxDate(w.options.From), ofxDate(w.options.To))
+}
+
+func (w *ofxWriter) Write(record *Record) error {
+	if !w.started {
+		w.start()
+	}
+
+	kind := "CREDIT"
+	switch {
+	case record.Transfer:
+		kind = "XFER"
+	case record.Type == "expense":
+		kind = "DEBIT"
+	}
+
+	fitID := record.ExternalID
+	if fitID == "" {
+		fitID = record.ID
+	}
+
+	name := []rune(record.Description)
+	if len(name) > ofxNameLength {
+		name = name[:ofxNameLength]
+	}
+
+	_, err := fmt.Fprintf(w.w, "<STMTTRN><TRNTYPE>%s</TRNTYPE><DTPOSTED>%s</DTPOSTED><TRNAMT>%s</TRNAMT><FITID>%s</FITID><NAME>%s</NAME><MEMO>%s</MEMO></STMTTRN>\n",
+		kind, ofxDate(record.Date), record.Signed(), escapeXML(fitID), escapeXML(strings.TrimSpace(string(name))), escapeXML(record.Description))
+	return err
+}
+
+func (w *ofxWriter) Close() error {
+	if !w.started {
+		w.start()
+	}
+
+	fmt.Fprintf(w.w, `</BANKTRANLIST>
+<LEDGERBAL><BALAMT>%s</BALAMT><DTASOF>%s</DTASOF></LEDGERBAL>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`, w.options.LedgerBalance, ofxDate(w.options.To))
+
+	return w.w.Flush()
+}
+
+func escapeXML(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,257 @@
+package statement
+
+import (
+	"archive/zip"
+	"bufio"
+	"fmt"
+	"io"
+	"nexa/internal/i18n"
+	"nexa/internal/money"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Estilos de célula definidos em xlsxStyles (índices de cellXfs).
+const (
+	xlsxStyleDefault = 0
+	xlsxStyleDate    = 1
+	xlsxStyleMoney   = 2
+	xlsxStyleBold    = 3
+)
+
+const xlsxNamespace = `xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"`
+
+// xlsxEpoch é o dia zero das datas do Excel (com o bug de 1900 embutido).
+var xlsxEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+type categoryTotals struct {
+	income  money.Amount
+	expense money.Amount
+}
+
+// xlsxWriter gera a planilha direto no zip, sem bibliotecas: a aba de
+// lançamentos é escrita linha a linha e a de resumo, com os totais, no Close.
+// O zip não precisa voltar no arquivo, então tudo sai em streaming.
+type xlsxWriter struct {
+	zip     *zip.Writer
+	sheet   *bufio.Writer
+	options ExportOptions
+	row     int
+
+	income       money.Amount
+	expense      money.Amount
+	transfersIn  money.Amount
+	transfersOut money.Amount
+	count        int
+	categories   map[string]*categoryTotals
+}
+
+func newXLSXWriter(out io.Writer, options ExportOptions) (*xlsxWriter, error) {
+	w := &xlsxWriter{
+		zip:        zip.NewWriter(out),
+		options:    options,
+		categories: map[string]*categoryTotals{},
+	}
+
+	locale := options.Locale
+	summaryName := escapeXML(i18n.Translate(locale, "EXPORT_SUMMARY_SHEET"))
+	transactionsName := escapeXML(i18n.Translate(locale, "EXPORT_TRANSACTIONS_SHEET"))
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/><Override PartName="/xl/worksheets/sheet2.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/><Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/></Types>`},
+		{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+		{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook ` + xlsxNamespace + ` xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="` + summaryName + `" sheetId="1" r:id="rId1"/><sheet name="` + transactionsName + `" sheetId="2" r:id="rId2"/></sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet2.xml"/><Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/></Relationships>`},
+		{"xl/styles.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet ` + xlsxNamespace + `><fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts><fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills><borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders><cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs><cellXfs count="4"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="14" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/><xf numFmtId="4" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs></styleSheet>`},
+	}
+
+	for _, part := range parts {
+		file, err := w.zip.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(file, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	// A aba de lançamentos é a sheet2; a de resumo (sheet1) só sai no Close.
+	file, err := w.zip.Create("xl/worksheets/sheet2.xml")
+	if err != nil {
+		return nil, err
+	}
+	w.sheet = bufio.NewWriter(file)
+
+	fmt.Fprint(w.sheet, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet `+xlsxNamespace+`><cols><col min="1" max="1" width="12" customWidth="1"/><col min="2" max="3" width="16" customWidth="1"/><col min="4" max="4" width="48" customWidth="1"/><col min="5" max="5" width="20" customWidth="1"/><col min="6" max="6" width="14" customWidth="1"/></cols><sheetData>`)
+	w.writeRow(w.sheet, &w.row, []xlsxCell{
+		textCell(i18n.Translate(locale, "EXPORT_DATE"), xlsxStyleBold),
+		textCell(i18n.Translate(locale, "EXPORT_TYPE"), xlsxStyleBold),
+		textCell(i18n.Translate(locale, "EXPORT_CATEGORY"), xlsxStyleBold),
+		textCell(i18n.Translate(locale, "EXPORT_DESCRIPTION"), xlsxStyleBold),
+		textCell(i18n.Translate(locale, "EXPORT_PAYMENT_METHOD"), xlsxStyleBold),
+		textCell(i18n.Translate(locale, "EXPORT_AMOUNT"), xlsxStyleBold),
+	})
+
+	return w, nil
+}
+
+func (w *xlsxWriter) Write(record *Record) error {
+	w.count++
+	switch {
+	case record.Transfer && record.Type == "expense":
+		w.transfersOut += record.Amount
+	case record.Transfer:
+		w.transfersIn += record.Amount
+	default:
+		totals := w.categories[record.Category]
+		if totals == nil {
+			totals = &categoryTotals{}
+			w.categories[record.Category] = totals
+		}
+		if record.Type == "expense" {
+			w.expense += record.Amount
+			totals.expense += record.Amount
+		} else {
+			w.income += record.Amount
+			totals.income += record.Amount
+		}
+	}
+
+	w.writeRow(w.sheet, &w.row, []xlsxCell{
+		dateCell(record.Date),
+		textCell(i18n.Translate(w.options.Locale, "EXPORT_TYPE_"+record.Kind()), xlsxStyleDefault),
+		textCell(record.Category, xlsxStyleDefault),
+		textCell(record.Description, xlsxStyleDefault),
+		textCell(record.PaymentMethod, xlsxStyleDefault),
+		moneyCell(record.Signed()),
+	})
+
+	// Erros de escrita aparecem no Flush; ele também mantém o buffer curto.
+	if w.sheet.Buffered() > 32<<10 {
+		return w.sheet.Flush()
+	}
+	return nil
+}
+
+func (w *xlsxWriter) Close() error {
+	fmt.Fprint(w.sheet, `</sheetData></worksheet>`)
+	if err := w.sheet.Flush(); err != nil {
+		return err
+	}
+
+	file, err := w.zip.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	summary := bufio.NewWriter(file)
+	w.writeSummary(summary)
+	if err := summary.Flush(); err != nil {
+		return err
+	}
+
+	return w.zip.Close()
+}
+
+func (w *xlsxWriter) writeSummary(out *bufio.Writer) {
+	locale := w.options.Locale
+	label := func(code string) xlsxCell {
+		return textCell(i18n.Translate(locale, code), xlsxStyleBold)
+	}
+
+	fmt.Fprint(out, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet `+xlsxNamespace+`><cols><col min="1" max="1" width="28" customWidth="1"/><col min="2" max="4" width="16" customWidth="1"/></cols><sheetData>`)
+
+	row := 0
+	w.writeRow(out, &row, []xlsxCell{textCell(w.options.AccountName, xlsxStyleBold)})
+	w.writeRow(out, &row, []xlsxCell{label("EXPORT_PERIOD"), dateCell(w.options.From), dateCell(w.options.To)})
+	w.writeRow(out, &row, []xlsxCell{label("EXPORT_INCOME"), moneyCell(w.income)})
+	w.writeRow(out, &row, []xlsxCell{label("EXPORT_EXPENSE"), moneyCell(w.expense)})
+	w.writeRow(out, &row, []xlsxCell{label("EXPORT_NET"), moneyCell(w.income - w.expense)})
+	w.writeRow(out, &row, []xlsxCell{label("EXPORT_TRANSFERS_IN"), moneyCell(w.transfersIn)})
+	w.writeRow(out, &row, []xlsxCell{label("EXPORT_TRANSFERS_OUT"), moneyCell(w.transfersOut)})
+	w.writeRow(out, &row, []xlsxCell{label("EXPORT_COUNT"), numberCell(fmt.Sprint(w.count))})
+	w.writeRow(out, &row, nil)
+	w.writeRow(out, &row, []xlsxCell{label("EXPORT_CATEGORY"), label("EXPORT_INCOME"), label("EXPORT_EXPENSE"), label("EXPORT_NET")})
+
+	names := make([]string, 0, len(w.categories))
+	for name := range w.categories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		totals := w.categories[name]
+		display := name
+		if display == "" {
+			display = i18n.Translate(locale, "EXPORT_UNCATEGORIZED")
+		}
+		w.writeRow(out, &row, []xlsxCell{
+			textCell(display, xlsxStyleDefault),
+			moneyCell(totals.income),
+			moneyCell(totals.expense),
+			moneyCell(totals.income - totals.expense),
+		})
+	}
+
+	fmt.Fprint(out, `</sheetData></worksheet>`)
+}
+
+type xlsxCell struct {
+	value string
+	text  bool
+	style int
+}
+
+func textCell(value string, style int) xlsxCell {
+	return xlsxCell{value: value, text: true, style: style}
+}
+
+func numberCell(value string) xlsxCell {
+	return xlsxCell{value: value}
+}
+
+func moneyCell(amount money.Amount) xlsxCell {
+	return xlsxCell{value: amount.String(), style: xlsxStyleMoney}
+}
+
+func dateCell(date time.Time) xlsxCell {
+	days := int(date.Sub(xlsxEpoch).Hours() / 24)
+	return xlsxCell{value: fmt.Sprint(days), style: xlsxStyleDate}
+}
+
+func (w *xlsxWriter) writeRow(out *bufio.Writer, row *int, cells []xlsxCell) {
+	*row++
+	fmt.Fprintf(out, `<row r="%d">`, *row)
+	for i, cell := range cells {
+		ref := fmt.Sprintf("%s%d", xlsxColumn(i), *row)
+		switch {
+		case cell.text:
+			fmt.Fprintf(out, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, cell.style, escapeXML(cell.value))
+		default:
+			fmt.Fprintf(out, `<c r="%s" s="%d"><v>%s</v></c>`, ref, cell.style, cell.value)
+		}
+	}
+	fmt.Fprint(out, `</row>`)
+}
+
+// xlsxColumn converte o índice (a partir de 0) na letra da coluna.
+func xlsxColumn(index int) string {
+	var name strings.Builder
+	for index++; index > 0; index = (index - 1) / 26 {
+		name.WriteByte(byte('A' + (index-1)%26))
+	}
+
+	runes := []rune(name.String())
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+
+	return string(runes)
+}
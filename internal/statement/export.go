@@ -0,0 +1,98 @@
+package statement
+
+import (
+	"errors"
+	"io"
+	"nexa/internal/money"
+	"time"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported export format")
+
+const FormatXLSX = "xlsx"
+
+// Record é um lançamento exportado. Amount é sempre positivo; o sentido vem
+// de Type ("income" ou "expense"). Transfer marca um lado de transferência
+// entre carteiras, que não entra nos totais de receita e despesa.
+type Record struct {
+	ID            string
+	Date          time.Time
+	Type          string
+	Transfer      bool
+	Amount        money.Amount
+	Category      string
+	Description   string
+	PaymentMethod string
+	ExternalID    string
+}
+
+// Signed devolve o valor com sinal: negativo para despesas.
+func (r *Record) Signed() money.Amount {
+	if r.Type == "expense" {
+		return -r.Amount
+	}
+	return r.Amount
+}
+
+// Kind é Type, ou "transfer_in"/"transfer_out" para transferências; é o
+// sufixo dos rótulos EXPORT_TYPE_*.
+func (r *Record) Kind() string {
+	switch {
+	case r.Transfer && r.Type == "expense":
+		return "transfer_out"
+	case r.Transfer:
+		return "transfer_in"
+	default:
+		return r.Type
+	}
+}
+
+// ExportOptions descreve o extrato exportado. LedgerBalance é o saldo da
+// carteira ao fim de To, usado no OFX.
+type ExportOptions struct {
+	Locale        string
+	AccountID     string
+	AccountName   string
+	Currency      string
+	From          time.Time
+	To            time.Time
+	LedgerBalance money.Amount
+}
+
+// Writer recebe os lançamentos um a um, sem precisar de todos em memória.
+// Close escreve o que depende do conjunto (totais, rodapés) e deve ser
+// chamado uma vez no fim.
+type Writer interface {
+	Write(record *Record) error
+	Close() error
+}
+
+// ContentType devolve o Content-Type do formato de exportação.
+func ContentType(format string) string {
+	switch format {
+	case FormatOFX:
+		return "application/x-ofx"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// NewWriter cria o Writer do formato.
+func NewWriter(format string, w io.Writer, options ExportOptions) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, options), nil
+	case FormatOFX:
+		return newOFXWriter(w, options), nil
+	case FormatXLSX:
+		writer, err := newXLSXWriter(w, options)
+		if err != nil {
+			return nil, err
+		}
+		return writer, nil
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
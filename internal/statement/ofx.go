@@ -0,0 +1,241 @@
+package statement
+
+import (
+	"errors"
+	"nexa/internal/money"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidOFX = errors.New("invalid ofx file")
+
+// ofxNode é um elemento do OFX. No SGML (OFX 1.x) os elementos folha não são
+// fechados, então o texto de um elemento termina na próxima tag.
+type ofxNode struct {
+	name     string
+	text     string
+	line     int
+	children []*ofxNode
+}
+
+func (n *ofxNode) child(name string) *ofxNode {
+	for _, child := range n.children {
+		if child.name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+func (n *ofxNode) value(name string) string {
+	if child := n.child(name); child != nil {
+		return child.text
+	}
+	return ""
+}
+
+func (n *ofxNode) findAll(name string, out []*ofxNode) []*ofxNode {
+	for _, child := range n.children {
+		if child.name == name {
+			out = append(out, child)
+			continue
+		}
+		out = child.findAll(name, out)
+	}
+	return out
+}
+
+// ParseOFX lê extratos OFX 1.x (SGML) e 2.x (XML), de conta corrente ou de
+// cartão. Cada STMTTRN vira uma linha; LEDGERBAL, se presente, vira o saldo
+// para conciliação.
+func ParseOFX(data []byte) (*Statement, error) {
+	text, _ := Decode(data, "")
+
+	start := strings.Index(strings.ToUpper(text), "<OFX>")
+	if start < 0 {
+		return nil, ErrInvalidOFX
+	}
+	// O OFX 2.x é XML e fecha todo elemento; o 1.x é SGML, com folhas abertas.
+	sgml := !strings.Contains(text[:start], "<?")
+	root := parseOFXTree(text[start:], strings.Count(text[:start], "\n")+1, sgml)
+
+	transactions := root.findAll("STMTTRN", nil)
+	result := &Statement{Rows: make([]Row, 0, len(transactions))}
+
+	for _, transaction := range transactions {
+		row := Row{Line: transaction.line}
+		entry, code := parseOFXTransaction(transaction)
+		if code != "" {
+			row.Error = code
+		} else {
+			row.Entry = entry
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	qualifyRepeatedIDs(result.Rows)
+
+	if ledger := root.findAll("LEDGERBAL", nil); len(ledger) > 0 {
+		amount, amountErr := parseOFXAmount(ledger[0].value("BALAMT"))
+		date, dateErr := parseOFXDate(ledger[0].value("DTASOF"))
+		if amountErr == nil && dateErr == nil {
+			result.LedgerBalance = &Balance{Amount: amount, Date: date}
+		}
+	}
+
+	return result, nil
+}
+
+func parseOFXTransaction(node *ofxNode) (*Entry, string) {
+	date, err := parseOFXDate(node.value("DTPOSTED"))
+	if err != nil {
+		return nil, ErrInvalidDate
+	}
+
+	amount, err := parseOFXAmount(node.value("TRNAMT"))
+	if err != nil {
+		return nil, ErrInvalidAmount
+	}
+	// Alguns bancos mandam débitos com valor positivo.
+	if strings.EqualFold(node.value("TRNTYPE"), "DEBIT") && amount > 0 {
+		amount = -amount
+	}
+	if amount == 0 {
+		return nil, ErrZeroAmount
+	}
+
+	name, memo := node.value("NAME"), node.value("MEMO")
+	description := memo
+	switch {
+	case memo == "":
+		description = name
+	case name != "" && !strings.Contains(memo, name):
+		description = name + " - " + memo
+	}
+
+	return &Entry{
+		Date:        date,
+		Amount:      amount,
+		Description: description,
+		ExternalID:  node.value("FITID"),
+	}, ""
+}
+
+// qualifyRepeatedIDs trata FITIDs repetidos no mesmo arquivo (há bancos que
+// repetem o identificador em lançamentos diferentes): cada ocorrência ganha
+// um sufixo estável, para não ser descartada como duplicada e continuar
+// reconhecida numa reimportação do mesmo arquivo.
+func qualifyRepeatedIDs(rows []Row) {
+	counts := map[string]int{}
+	for _, row := range rows {
+		if row.Entry != nil && row.Entry.ExternalID != "" {
+			counts[row.Entry.ExternalID]++
+		}
+	}
+
+	seen := map[string]int{}
+	for _, row := range rows {
+		if row.Entry == nil || counts[row.Entry.ExternalID] < 2 {
+			continue
+		}
+		id := row.Entry.ExternalID
+		seen[id]++
+		row.Entry.ExternalID = id + "#" + strconv.Itoa(seen[id])
+	}
+}
+
+// parseOFXDate lê "YYYYMMDD[HHMMSS[.XXX]][[-3:BRT]]", guardando só a data.
+func parseOFXDate(raw string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if len(raw) < 8 {
+		return time.Time{}, ErrInvalidOFX
+	}
+
+	return time.Parse("20060102", raw[:8])
+}
+
+// parseOFXAmount aceita ponto decimal (padrão) e vírgula, que alguns bancos
+// brasileiros usam.
+func parseOFXAmount(raw string) (money.Amount, error) {
+	raw = strings.TrimSpace(raw)
+	if strings.Contains(raw, ",") && !strings.Contains(raw, ".") {
+		return money.ParseLocalized(raw, ',')
+	}
+
+	return money.ParseLocalized(raw, '.')
+}
+
+// ofxAggregates são os agregados do OFX, que o SGML fecha explicitamente.
+// Qualquer outro elemento é uma folha, mesmo vazia (ex.: "<MEMO>" sem texto).
+var ofxAggregates = map[string]bool{
+	"OFX": true, "SIGNONMSGSRSV1": true, "SONRS": true, "STATUS": true, "FI": true,
+	"BANKMSGSRSV1": true, "STMTTRNRS": true, "STMTRS": true, "BANKACCTFROM": true, "BANKACCTTO": true,
+	"CREDITCARDMSGSRSV1": true, "CCSTMTTRNRS": true, "CCSTMTRS": true, "CCACCTFROM": true, "CCACCTTO": true,
+	"BANKTRANLIST": true, "STMTTRN": true, "PAYEE": true, "CURRENCY": true, "ORIGCURRENCY": true,
+	"LEDGERBAL": true, "AVAILBAL": true, "BALLIST": true, "BAL": true,
+}
+
+func parseOFXTree(text string, firstLine int, sgml bool) *ofxNode {
+	root := &ofxNode{name: "#root"}
+	stack := []*ofxNode{root}
+	line := firstLine
+
+	for len(text) > 0 {
+		open := strings.IndexByte(text, '<')
+		if open < 0 {
+			break
+		}
+
+		if content := strings.TrimSpace(text[:open]); content != "" {
+			current := stack[len(stack)-1]
+			current.text = unescapeOFX(content)
+		}
+		line += strings.Count(text[:open], "\n")
+		text = text[open+1:]
+
+		end := strings.IndexByte(text, '>')
+		if end < 0 {
+			break
+		}
+		tag := strings.TrimSpace(text[:end])
+		line += strings.Count(text[:end], "\n")
+		text = text[end+1:]
+
+		switch {
+		case tag == "" || tag[0] == '?' || tag[0] == '!':
+			continue
+		case tag[0] == '/':
+			name := strings.ToUpper(strings.TrimSpace(tag[1:]))
+			for i := len(stack) - 1; i > 0; i-- {
+				if stack[i].name == name {
+					stack = stack[:i]
+					break
+				}
+			}
+		default:
+			selfClosing := strings.HasSuffix(tag, "/")
+			name := strings.ToUpper(strings.Fields(strings.TrimSuffix(tag, "/"))[0])
+
+			// Uma folha SGML sem fechamento termina quando a próxima tag abre,
+			// tenha texto ou não.
+			if current := stack[len(stack)-1]; current != root && (current.text != "" || sgml && !ofxAggregates[current.name]) {
+				stack = stack[:len(stack)-1]
+			}
+
+			node := &ofxNode{name: name, line: line}
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, node)
+			if !selfClosing {
+				stack = append(stack, node)
+			}
+		}
+	}
+
+	return root
+}
+
+var ofxEntities = strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&", "&quot;", `"`, "&apos;", "'", "&nbsp;", " ")
+
+func unescapeOFX(s string) string {
+	return ofxEntities.Replace(s)
+}
@@ -0,0 +1,129 @@
+package statement
+
+import (
+	"nexa/internal/money"
+	"os"
+	"testing"
+	"time"
+)
+
+type expectedRow struct {
+	date        string
+	amount      money.Amount
+	description string
+	externalID  string
+	err         string
+}
+
+func checkRows(t *testing.T, rows []Row, expected []expectedRow) {
+	t.Helper()
+
+	if len(rows) != len(expected) {
+		t.Fatalf("got %d rows, want %d", len(rows), len(expected))
+	}
+
+	for i, want := range expected {
+		row := rows[i]
+		if want.err != "" {
+			if row.Error != want.err || row.Entry != nil {
+				t.Errorf("row %d: got error %q (entry %v), want %q", i, row.Error, row.Entry, want.err)
+			}
+			continue
+		}
+
+		if row.Entry == nil {
+			t.Errorf("row %d: unexpected error %q", i, row.Error)
+			continue
+		}
+		if got := row.Entry.Date.Format(time.DateOnly); got != want.date {
+			t.Errorf("row %d: date = %s, want %s", i, got, want.date)
+		}
+		if row.Entry.Amount != want.amount {
+			t.Errorf("row %d: amount = %d, want %d", i, row.Entry.Amount, want.amount)
+		}
+		if row.Entry.Description != want.description {
+			t.Errorf("row %d: description = %q, want %q", i, row.Entry.Description, want.description)
+		}
+		if row.Entry.ExternalID != want.externalID {
+			t.Errorf("row %d: externalID = %q, want %q", i, row.Entry.ExternalID, want.externalID)
+		}
+	}
+}
+
+func checkLedger(t *testing.T, ledger *Balance, amount money.Amount, date string) {
+	t.Helper()
+
+	if ledger == nil {
+		t.Fatal("missing ledger balance")
+	}
+	if ledger.Amount != amount || ledger.Date.Format(time.DateOnly) != date {
+		t.Errorf("ledger = %d on %s, want %d on %s", ledger.Amount, ledger.Date.Format(time.DateOnly), amount, date)
+	}
+}
+
+func parseFixture(t *testing.T, name string, parse func([]byte) (*Statement, error)) *Statement {
+	t.Helper()
+
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := parse(data)
+	if err != nil {
+		t.Fatalf("parse %s: %v", name, err)
+	}
+
+	return parsed
+}
+
+func TestParseOFXSGML(t *testing.T) {
+	parsed := parseFixture(t, "extrato-sgml.ofx", ParseOFX)
+
+	checkRows(t, parsed.Rows, []expectedRow{
+		{date: "2024-02-01", amount: -123456, description: "PIX TRANSF JOÃO DA SILVA", externalID: "20240201001"},
+		{date: "2024-02-02", amount: 500000, description: "SALARIO - ACME LTDA", externalID: "20240202001"},
+		// Débito com valor positivo e vírgula decimal; FITID "0" repetido.
+		{date: "2024-02-03", amount: -8990, description: "TAR PACOTE SERVIÇOS", externalID: "0#1"},
+		{date: "2024-02-03", amount: -1500, description: "IOF", externalID: "0#2"},
+		{err: ErrInvalidDate},
+	})
+	checkLedger(t, parsed.LedgerBalance, 366054, "2024-02-05")
+}
+
+func TestParseOFXXML(t *testing.T) {
+	parsed := parseFixture(t, "fatura-xml.ofx", ParseOFX)
+
+	checkRows(t, parsed.Rows, []expectedRow{
+		{date: "2024-03-01", amount: -4590, description: "Padaria & Café", externalID: "65e1f0a2-1c3b-4d5e-9f00-aa11bb22cc33"},
+		{date: "2024-03-02", amount: -12000, description: "Mercado - Parcela 1/3", externalID: "65e1f0a2-1c3b-4d5e-9f00-aa11bb22cc34"},
+		{date: "2024-03-04", amount: 16590, description: "Pagamento recebido", externalID: "65e1f0a2-1c3b-4d5e-9f00-aa11bb22cc35"},
+		{err: ErrInvalidAmount},
+	})
+	checkLedger(t, parsed.LedgerBalance, 0, "2024-03-05")
+}
+
+// Uma folha SGML vazia (<MEMO> sem texto) não pode engolir as tags seguintes.
+func TestParseOFXEmptyLeaf(t *testing.T) {
+	data := []byte("<OFX><STMTTRN><TRNTYPE>DEBIT<DTPOSTED>20240105<TRNAMT>-10.00<MEMO><FITID>ABC</STMTTRN>" +
+		"<STMTTRN><TRNTYPE>CREDIT<DTPOSTED>20240106<NAME><TRNAMT>25.00<FITID>DEF<MEMO>PIX</STMTTRN></OFX>")
+
+	parsed, err := ParseOFX(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkRows(t, parsed.Rows, []expectedRow{
+		{date: "2024-01-05", amount: -1000, externalID: "ABC"},
+		{date: "2024-01-06", amount: 2500, description: "PIX", externalID: "DEF"},
+	})
+	if parsed.LedgerBalance != nil {
+		t.Errorf("unexpected ledger balance %+v", parsed.LedgerBalance)
+	}
+}
+
+func TestParseOFXInvalid(t *testing.T) {
+	if _, err := ParseOFX([]byte("OFXHEADER:100\nsem corpo")); err != ErrInvalidOFX {
+		t.Errorf("err = %v, want ErrInvalidOFX", err)
+	}
+}
@@ -0,0 +1,195 @@
+package statement
+
+import (
+	"errors"
+	"nexa/internal/money"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidQIF = errors.New("invalid qif file")
+
+// qifAccountTypes são as seções de lançamentos que importamos; investimentos
+// e listas (!Type:Cat, !Type:Invst...) são ignorados.
+var qifAccountTypes = map[string]bool{
+	"bank":  true,
+	"cash":  true,
+	"ccard": true,
+	"oth a": true,
+	"oth l": true,
+}
+
+var qifDate = regexp.MustCompile(`^(\d{1,4})[/.-](\d{1,2})[/.'-]\s*(\d{1,4})$`)
+
+type qifRecord struct {
+	line   int
+	fields map[byte]string
+}
+
+// ParseQIF lê arquivos QIF de conta corrente, dinheiro e cartão. O QIF não
+// diz a ordem de dia e mês: se algum registro só fizer sentido como mm/dd, o
+// arquivo inteiro é lido assim; caso contrário vale o padrão dd/mm.
+func ParseQIF(data []byte) (*Statement, error) {
+	text, _ := Decode(data, "")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	var records []qifRecord
+	current := qifRecord{fields: map[byte]string{}}
+	importing, sawType := false, false
+
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			continue
+		}
+
+		if line[0] == '!' {
+			header := strings.ToLower(line)
+			if strings.HasPrefix(header, "!type:") {
+				sawType = true
+				importing = qifAccountTypes[strings.TrimSpace(strings.TrimPrefix(header, "!type:"))]
+			} else {
+				// !Account, !Option... abrem blocos que não são lançamentos.
+				importing = false
+			}
+			current = qifRecord{fields: map[byte]string{}}
+			continue
+		}
+
+		if line[0] == '^' {
+			if importing && len(current.fields) > 0 {
+				records = append(records, current)
+			}
+			current = qifRecord{fields: map[byte]string{}}
+			continue
+		}
+
+		if !importing {
+			continue
+		}
+		if len(current.fields) == 0 {
+			current.line = i + 1
+		}
+		// Divisões (S, E, $) ficam de fora: o lançamento entra pelo total.
+		if _, exists := current.fields[line[0]]; !exists {
+			current.fields[line[0]] = strings.TrimSpace(line[1:])
+		}
+	}
+
+	if !sawType {
+		return nil, ErrInvalidQIF
+	}
+
+	monthFirst := qifMonthFirst(records)
+
+	result := &Statement{Rows: make([]Row, 0, len(records))}
+	for _, record := range records {
+		row := Row{Line: record.line}
+		entry, code := parseQIFRecord(record, monthFirst)
+		if code != "" {
+			row.Error = code
+		} else {
+			row.Entry = entry
+		}
+		result.Rows = append(result.Rows, row)
+	}
+
+	return result, nil
+}
+
+func parseQIFRecord(record qifRecord, monthFirst bool) (*Entry, string) {
+	date, err := parseQIFDate(record.fields['D'], monthFirst)
+	if err != nil {
+		return nil, ErrInvalidDate
+	}
+
+	raw := record.fields['T']
+	if raw == "" {
+		raw = record.fields['U']
+	}
+	if raw == "" {
+		return nil, ErrZeroAmount
+	}
+	amount, err := money.ParseLocalized(raw, qifDecimalSeparator(raw))
+	if err != nil {
+		return nil, ErrInvalidAmount
+	}
+	if amount == 0 {
+		return nil, ErrZeroAmount
+	}
+
+	payee, memo := record.fields['P'], record.fields['M']
+	description := payee
+	switch {
+	case payee == "":
+		description = memo
+	case memo != "" && !strings.Contains(payee, memo):
+		description = payee + " - " + memo
+	}
+
+	return &Entry{Date: date, Amount: amount, Description: description}, ""
+}
+
+// qifDecimalSeparator olha o último separador: seguido de uma ou duas casas é
+// o decimal ("1.234,56" ou "1,234.56").
+func qifDecimalSeparator(raw string) byte {
+	last := strings.LastIndexAny(raw, ".,")
+	if last >= 0 && len(strings.TrimSpace(raw[last+1:])) <= 2 {
+		return raw[last]
+	}
+	return '.'
+}
+
+func qifMonthFirst(records []qifRecord) bool {
+	for _, record := range records {
+		parts := qifDate.FindStringSubmatch(record.fields['D'])
+		if parts == nil || len(parts[1]) == 4 {
+			continue
+		}
+		first, _ := strconv.Atoi(parts[1])
+		second, _ := strconv.Atoi(parts[2])
+		if second > 12 && first <= 12 {
+			return true
+		}
+		if first > 12 {
+			return false
+		}
+	}
+
+	return false
+}
+
+// parseQIFDate aceita "31/01/2024", "01/31/24", "1/31'24" (o apóstrofo marca
+// anos 2000 no Quicken) e "2024-01-31".
+func parseQIFDate(raw string, monthFirst bool) (time.Time, error) {
+	parts := qifDate.FindStringSubmatch(strings.ReplaceAll(strings.TrimSpace(raw), " ", ""))
+	if parts == nil {
+		return time.Time{}, ErrInvalidQIF
+	}
+
+	a, _ := strconv.Atoi(parts[1])
+	b, _ := strconv.Atoi(parts[2])
+	c, _ := strconv.Atoi(parts[3])
+
+	var year, month, day int
+	switch {
+	case len(parts[1]) == 4:
+		year, month, day = a, b, c
+	case monthFirst:
+		month, day, year = a, b, c
+	default:
+		day, month, year = a, b, c
+	}
+	if year < 100 {
+		year += 2000
+	}
+
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if date.Year() != year || int(date.Month()) != month || date.Day() != day {
+		return time.Time{}, ErrInvalidQIF
+	}
+
+	return date, nil
+}
@@ -0,0 +1,39 @@
+package statement
+
+import "testing"
+
+func TestParseQIF(t *testing.T) {
+	parsed := parseFixture(t, "extrato.qif", ParseQIF)
+
+	// A seção !Type:Cat do fim do arquivo é ignorada.
+	checkRows(t, parsed.Rows, []expectedRow{
+		{date: "2024-01-31", amount: -123456, description: "PIX TRANSF JOÃO - Aluguel"},
+		{date: "2024-02-01", amount: 500000, description: "SALARIO"},
+		{date: "2024-02-02", amount: -8990, description: "Tarifa"},
+		{err: ErrInvalidDate},
+	})
+	if parsed.LedgerBalance != nil {
+		t.Errorf("unexpected ledger balance %+v", parsed.LedgerBalance)
+	}
+}
+
+func TestParseQIFMonthFirst(t *testing.T) {
+	data := []byte("!Type:CCard\nD01/31/2024\nT-12.50\nPMercado\n^\nD02/01/2024\nT-3.00\nPCafe\n^\n")
+
+	parsed, err := ParseQIF(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 01/31 só faz sentido como mm/dd, então o arquivo todo é lido assim.
+	checkRows(t, parsed.Rows, []expectedRow{
+		{date: "2024-01-31", amount: -1250, description: "Mercado"},
+		{date: "2024-02-01", amount: -300, description: "Cafe"},
+	})
+}
+
+func TestParseQIFInvalid(t *testing.T) {
+	if _, err := ParseQIF([]byte("D01/01/2024\nT10\n^\n")); err != ErrInvalidQIF {
+		t.Errorf("err = %v, want ErrInvalidQIF", err)
+	}
+}
@@ -0,0 +1,173 @@
+// Package statement lê extratos bancários (CSV, OFX, QIF) e os transforma em
+// lançamentos prontos para importar em uma carteira.
+package statement
+
+import (
+	"bytes"
+	"nexa/internal/money"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+const (
+	FormatCSV = "csv"
+	FormatOFX = "ofx"
+	FormatQIF = "qif"
+)
+
+const (
+	EncodingUTF8        = "utf-8"
+	EncodingWindows1252 = "windows-1252"
+)
+
+// Códigos de erro por linha, devolvidos no preview.
+const (
+	ErrInvalidDate   = "INVALID_DATE"
+	ErrInvalidAmount = "INVALID_AMOUNT"
+	ErrMissingColumn = "MISSING_COLUMN"
+	ErrZeroAmount    = "ZERO_AMOUNT"
+)
+
+// Entry é um lançamento lido do extrato. Amount tem sinal: negativo é saída.
+type Entry struct {
+	Date        time.Time    `json:"date"`
+	Amount      money.Amount `json:"amount"`
+	Description string       `json:"description"`
+	ExternalID  string       `json:"externalId,omitempty"`
+}
+
+// Row é uma linha do arquivo: um lançamento ou o motivo de ter sido ignorada.
+type Row struct {
+	Line  int    `json:"line"`
+	Entry *Entry `json:"entry,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// Balance é o saldo informado pelo banco numa data (LEDGERBAL do OFX).
+type Balance struct {
+	Amount money.Amount `json:"amount"`
+	Date   time.Time    `json:"date"`
+}
+
+// Statement é um extrato lido: as linhas e, se o formato trouxer, o saldo.
+type Statement struct {
+	Rows          []Row
+	LedgerBalance *Balance
+}
+
+// Entries devolve só as linhas válidas.
+func Entries(rows []Row) []Entry {
+	entries := make([]Entry, 0, len(rows))
+	for _, row := range rows {
+		if row.Entry != nil {
+			entries = append(entries, *row.Entry)
+		}
+	}
+
+	return entries
+}
+
+// Decode converte o arquivo para UTF-8. Extratos de bancos brasileiros
+// costumam vir em Latin-1; o Windows-1252 é um superconjunto dele que também
+// cobre aspas tipográficas e o símbolo do euro.
+func Decode(data []byte, encoding string) (string, string) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	if encoding == "" {
+		encoding = EncodingUTF8
+		if !utf8.Valid(data) {
+			encoding = EncodingWindows1252
+		}
+	}
+
+	if encoding == EncodingWindows1252 {
+		decoded, err := charmap.Windows1252.NewDecoder().Bytes(data)
+		if err == nil {
+			return string(decoded), encoding
+		}
+	}
+
+	return strings.ToValidUTF8(string(data), "�"), EncodingUTF8
+}
+
+// DetectFormat identifica o formato pelo conteúdo e, em último caso, pela
+// extensão. Retorna "" se não reconhecer.
+func DetectFormat(filename string, data []byte) string {
+	head := strings.ToUpper(strings.TrimSpace(string(data[:min(len(data), 1024)])))
+	head = strings.TrimPrefix(head, "\ufeff")
+
+	switch {
+	case strings.HasPrefix(head, "OFXHEADER") || strings.Contains(head, "<OFX>"):
+		return FormatOFX
+	case strings.HasPrefix(head, "!TYPE:") || strings.HasPrefix(head, "!ACCOUNT") || strings.HasPrefix(head, "!OPTION"):
+		return FormatQIF
+	}
+
+	lower := strings.ToLower(filename)
+	switch {
+	case strings.HasSuffix(lower, ".ofx"):
+		return FormatOFX
+	case strings.HasSuffix(lower, ".qif"):
+		return FormatQIF
+	case strings.HasSuffix(lower, ".csv"), strings.HasSuffix(lower, ".txt"):
+		return FormatCSV
+	}
+
+	return ""
+}
+
+// MarkDuplicates indica quais entries já estão em existing (ou repetem uma
+// anterior do próprio arquivo pelo mesmo ExternalID). Sem ExternalID, compara
+// data, valor e descrição normalizada contando ocorrências: duas compras
+// iguais no mesmo dia só são duplicadas se o banco já tiver as duas.
+func MarkDuplicates(entries, existing []Entry) []bool {
+	seenIDs := map[string]bool{}
+	remaining := map[string]int{}
+	for _, entry := range existing {
+		if entry.ExternalID != "" {
+			seenIDs[entry.ExternalID] = true
+		}
+		remaining[fingerprint(entry)]++
+	}
+
+	duplicates := make([]bool, len(entries))
+	for i, entry := range entries {
+		if entry.ExternalID != "" {
+			if seenIDs[entry.ExternalID] {
+				duplicates[i] = true
+				continue
+			}
+			seenIDs[entry.ExternalID] = true
+		}
+
+		key := fingerprint(entry)
+		if remaining[key] > 0 {
+			remaining[key]--
+			duplicates[i] = true
+		}
+	}
+
+	return duplicates
+}
+
+func fingerprint(entry Entry) string {
+	description := strings.Join(strings.Fields(strings.ToLower(entry.Description)), " ")
+	return entry.Date.Format(time.DateOnly) + "|" + entry.Amount.String() + "|" + description
+}
+
+// DateRange devolve a menor e a maior data das entries.
+func DateRange(entries []Entry) (from, to time.Time) {
+	for i, entry := range entries {
+		if i == 0 || entry.Date.Before(from) {
+			from = entry.Date
+		}
+		if i == 0 || entry.Date.After(to) {
+			to = entry.Date
+		}
+	}
+
+	return from, to
+}
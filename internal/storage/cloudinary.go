@@ -0,0 +1,202 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"nexa/internal/config"
+	"nexa/internal/tracing"
+	"nexa/internal/utils"
+	"path"
+	"strings"
+	"time"
+)
+
+// CloudinaryStorage envia imagens como resource_type "image". Chaves com
+// extensão (ex.: "exports/<id>.zip") são arquivos comuns e vão como "raw",
+// que no Cloudinary mantém a extensão no public_id.
+type CloudinaryStorage struct {
+	cfg    config.CloudinaryConfig
+	client *http.Client
+}
+
+func NewCloudinaryStorage(cfg config.CloudinaryConfig) *CloudinaryStorage {
+	return &CloudinaryStorage{
+		cfg:    cfg,
+		client: tracing.NewHTTPClient(30 * time.Second),
+	}
+}
+
+type cloudinaryResponse struct {
+	SecureURL string `json:"secure_url"`
+	PublicID  string `json:"public_id"`
+	Result    string `json:"result"`
+	Error     struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (s *CloudinaryStorage) Put(ctx context.Context, key string, data io.Reader, _ string) (string, error) {
+	timestamp := fmt.Sprintf("%d", time.Now().Unix())
+	params := url.Values{}
+	params.Set("public_id", key)
+	params.Set("timestamp", timestamp)
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	_ = writer.WriteField("api_key", s.cfg.APIKey)
+	_ = writer.WriteField("public_id", key)
+	_ = writer.WriteField("timestamp", timestamp)
+	_ = writer.WriteField("signature", s.sign(params))
+
+	part, err := writer.CreateFormFile("file", key)
+	if err != nil {
+		return "", fmt.Errorf("error creating form file: %w", err)
+	}
+
+	if _, err := io.Copy(part, data); err != nil {
+		return "", fmt.Errorf("error writing image data: %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("error closing writer: %w", err)
+	}
+
+	result, err := s.post(ctx, resourceType(key), "upload", writer.FormDataContentType(), &body)
+	if err != nil {
+		return "", err
+	}
+
+	if result.SecureURL == "" {
+		return "", fmt.Errorf("empty secure URL in cloudinary response")
+	}
+
+	return result.SecureURL, nil
+}
+
+func (s *CloudinaryStorage) Delete(ctx context.Context, key string) error {
+	timestamp := fmt.Sprintf("%d", time.Now().Unix())
+	params := url.Values{}
+	params.Set("public_id", key)
+	params.Set("timestamp", timestamp)
+
+	form := url.Values{}
+	form.Set("public_id", key)
+	form.Set("timestamp", timestamp)
+	form.Set("api_key", s.cfg.APIKey)
+	form.Set("signature", s.sign(params))
+
+	result, err := s.post(ctx, resourceType(key), "destroy", "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+
+	if result.Result != "ok" && result.Result != "not found" {
+		return fmt.Errorf("erro ao deletar imagem do cloudinary: %s", result.Result)
+	}
+
+	return nil
+}
+
+func (s *CloudinaryStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL(key), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cloudinary request failed: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("cloudinary error [%d] fetching %s", resp.StatusCode, key)
+	}
+
+	return resp.Body, nil
+}
+
+func (s *CloudinaryStorage) URL(key string) string {
+	return fmt.Sprintf("https://res.cloudinary.com/%s/%s/upload/%s", s.cfg.CloudName, resourceType(key), key)
+}
+
+func (s *CloudinaryStorage) Key(rawURL string) string {
+	if !strings.Contains(rawURL, "res.cloudinary.com/"+s.cfg.CloudName+"/") {
+		return ""
+	}
+
+	if _, after, found := strings.Cut(rawURL, "/raw/upload/"); found {
+		if version, rest, found := strings.Cut(after, "/"); found && isVersion(version) {
+			return rest
+		}
+		return after
+	}
+
+	return utils.ExtractPublicID(rawURL)
+}
+
+func resourceType(key string) string {
+	if path.Ext(key) != "" {
+		return "raw"
+	}
+	return "image"
+}
+
+// isVersion reconhece o segmento "v<número>" que o Cloudinary põe nas URLs.
+func isVersion(segment string) bool {
+	if len(segment) < 2 || segment[0] != 'v' {
+		return false
+	}
+	for _, r := range segment[1:] {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// sign segue a regra do Cloudinary: parâmetros em ordem alfabética,
+// concatenados com o api_secret e assinados com SHA-1.
+func (s *CloudinaryStorage) sign(params url.Values) string {
+	unescaped, _ := url.QueryUnescape(params.Encode())
+
+	h := sha1.New()
+	h.Write([]byte(unescaped + s.cfg.APISecret))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (s *CloudinaryStorage) post(ctx context.Context, resourceType, action, contentType string, body io.Reader) (*cloudinaryResponse, error) {
+	endpoint := fmt.Sprintf("https://api.cloudinary.com/v1_1/%s/%s/%s", s.cfg.CloudName, resourceType, action)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, body)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cloudinary request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result cloudinaryResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("error decoding cloudinary response: %w", err)
+	}
+
+	if resp.StatusCode >= 400 || result.Error.Message != "" {
+		return nil, fmt.Errorf("cloudinary error [%d]: %s", resp.StatusCode, result.Error.Message)
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,238 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"nexa/internal/config"
+	"nexa/internal/tracing"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxSignedURLTTL é a validade máxima de uma URL pré-assinada no SigV4.
+const MaxSignedURLTTL = 7 * 24 * time.Hour
+
+// S3Storage fala com qualquer serviço compatível com S3 (AWS, MinIO, R2...)
+// usando path-style e assinatura SigV4, sem depender do SDK da AWS.
+type S3Storage struct {
+	cfg       config.S3Config
+	publicURL string
+	client    *http.Client
+}
+
+func NewS3Storage(cfg config.S3Config, publicURL string) *S3Storage {
+	if publicURL == "" {
+		publicURL = strings.TrimSuffix(cfg.Endpoint, "/") + "/" + cfg.Bucket
+	}
+
+	return &S3Storage{
+		cfg:       cfg,
+		publicURL: strings.TrimSuffix(publicURL, "/"),
+		client:    tracing.NewHTTPClient(30 * time.Second),
+	}
+}
+
+func (s *S3Storage) Put(ctx context.Context, key string, data io.Reader, contentType string) (string, error) {
+	payload, err := io.ReadAll(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to read object %s: %w", key, err)
+	}
+
+	req, err := s.newRequest(ctx, http.MethodPut, key, payload)
+	if err != nil {
+		return "", err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	if err := s.do(req); err != nil {
+		return "", fmt.Errorf("failed to put object %s: %w", key, err)
+	}
+
+	return s.URL(key), nil
+}
+
+func (s *S3Storage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object %s: %w", key, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("failed to get object %s: s3 error [%d]: %s", key, resp.StatusCode, string(body))
+	}
+
+	return resp.Body, nil
+}
+
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := s.do(req); err != nil {
+		return fmt.Errorf("failed to delete object %s: %w", key, err)
+	}
+
+	return nil
+}
+
+func (s *S3Storage) URL(key string) string {
+	return s.publicURL + "/" + key
+}
+
+func (s *S3Storage) Key(url string) string {
+	return keyFromPrefixedURL(s.publicURL, url)
+}
+
+// SignedURL pré-assina um GET de key (SigV4 na query string): o próprio S3
+// recusa o link depois de ttl, mesmo com o bucket privado. O link aponta para
+// o endpoint, não para STORAGE_PUBLIC_URL, que não validaria a assinatura.
+func (s *S3Storage) SignedURL(key string, ttl time.Duration) (string, error) {
+	return s.signedURL(key, ttl, time.Now().UTC())
+}
+
+func (s *S3Storage) signedURL(key string, ttl time.Duration, now time.Time) (string, error) {
+	if ttl < time.Second || ttl > MaxSignedURLTTL {
+		return "", fmt.Errorf("invalid signed URL TTL %s (expected 1s to %s)", ttl, MaxSignedURLTTL)
+	}
+
+	endpoint, err := url.Parse(strings.TrimSuffix(s.cfg.Endpoint, "/"))
+	if err != nil {
+		return "", fmt.Errorf("invalid S3 endpoint: %w", err)
+	}
+	endpoint.Path = "/" + s.cfg.Bucket + "/" + key
+
+	amzDate := now.Format("20060102T150405Z")
+	scope := now.Format("20060102") + "/" + s.cfg.Region + "/s3/aws4_request"
+
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	query.Set("X-Amz-Credential", s.cfg.AccessKeyID+"/"+scope)
+	query.Set("X-Amz-Date", amzDate)
+	query.Set("X-Amz-Expires", strconv.Itoa(int(ttl/time.Second)))
+	query.Set("X-Amz-SignedHeaders", "host")
+
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		endpoint.EscapedPath(),
+		query.Encode(),
+		"host:" + endpoint.Host + "\n",
+		"host",
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+
+	query.Set("X-Amz-Signature", s.signature(now, amzDate, scope, canonicalRequest))
+	endpoint.RawQuery = query.Encode()
+
+	return endpoint.String(), nil
+}
+
+func (s *S3Storage) do(req *http.Request) error {
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 && !(req.Method == http.MethodDelete && resp.StatusCode == http.StatusNotFound) {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("s3 error [%d]: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+func (s *S3Storage) newRequest(ctx context.Context, method, key string, payload []byte) (*http.Request, error) {
+	endpoint, err := url.Parse(strings.TrimSuffix(s.cfg.Endpoint, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 endpoint: %w", err)
+	}
+	endpoint.Path = "/" + s.cfg.Bucket + "/" + key
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint.String(), bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	s.sign(req, payload, time.Now().UTC())
+
+	return req, nil
+}
+
+// sign aplica AWS Signature Version 4 com os cabeçalhos host,
+// x-amz-content-sha256 e x-amz-date.
+func (s *S3Storage) sign(req *http.Request, payload []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(payload)
+
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		"host:" + req.URL.Host + "\n" +
+			"x-amz-content-sha256:" + payloadHash + "\n" +
+			"x-amz-date:" + amzDate + "\n",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.cfg.Region + "/s3/aws4_request"
+	signature := s.signature(now, amzDate, scope, canonicalRequest)
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.cfg.AccessKeyID, scope, signedHeaders, signature,
+	))
+}
+
+// signature assina a requisição canônica com a chave derivada do dia, da
+// região e do serviço, como pede o SigV4.
+func (s *S3Storage) signature(now time.Time, amzDate, scope, canonicalRequest string) string {
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretAccessKey), now.Format("20060102"))
+	key = hmacSHA256(key, s.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"nexa/internal/config"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ObjectStorage guarda arquivos enviados pelos usuários (fotos, banners...).
+// As chaves usam "/" como separador, ex.: "avatars/<idUser>-<timestamp>".
+type ObjectStorage interface {
+	Put(ctx context.Context, key string, data io.Reader, contentType string) (string, error)
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+	// Key faz o caminho inverso de URL. Retorna "" quando a URL não pertence
+	// a este armazenamento (ex.: um preset local ou outro provedor).
+	Key(url string) string
+}
+
+// URLSigner é implementado pelos storages que emitem links que expiram
+// sozinhos (hoje, o S3 com URLs pré-assinadas).
+type URLSigner interface {
+	SignedURL(key string, ttl time.Duration) (string, error)
+}
+
+// TemporaryURL devolve um link para key que expira em ttl quando o storage
+// assina URLs. Nos demais (Cloudinary, disco local), devolve a URL pública,
+// que vale até o arquivo ser apagado; o segredo do link é a própria chave.
+func TemporaryURL(s ObjectStorage, key string, ttl time.Duration) (string, error) {
+	if signer, ok := s.(URLSigner); ok {
+		return signer.SignedURL(key, ttl)
+	}
+
+	return s.URL(key), nil
+}
+
+func New(cfg config.StorageConfig, cloudinary config.CloudinaryConfig) (ObjectStorage, error) {
+	driver := cfg.Driver
+	if driver == "" && cloudinary.Enabled() {
+		driver = "cloudinary"
+	}
+
+	switch driver {
+	case "cloudinary":
+		if !cloudinary.Enabled() {
+			return nil, fmt.Errorf("storage driver cloudinary requires CLOUDINARY_* configuration")
+		}
+		return NewCloudinaryStorage(cloudinary), nil
+	case "s3":
+		return NewS3Storage(cfg.S3, cfg.PublicURL), nil
+	case "local", "":
+		return NewLocalStorage(cfg.LocalDir, cfg.PublicURL)
+	default:
+		return nil, fmt.Errorf("unsupported storage driver: %q", driver)
+	}
+}
+
+// DeleteImage apaga a imagem de url. Quando a chave termina em um dos
+// variants ("<pasta>/<variant>"), apaga todos os variants da pasta. URLs que
+// não pertencem ao storage (presets, outro provedor) são ignoradas.
+func DeleteImage(ctx context.Context, s ObjectStorage, url string, variants []string) error {
+	key := s.Key(url)
+	if key == "" {
+		return nil
+	}
+
+	keys := []string{key}
+	if folder, name := path.Split(key); folder != "" && slices.Contains(variants, name) {
+		keys = keys[:0]
+		for _, variant := range variants {
+			keys = append(keys, folder+variant)
+		}
+	}
+
+	var errs []error
+	for _, key := range keys {
+		if err := s.Delete(ctx, key); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func keyFromPrefixedURL(prefix, url string) string {
+	prefix = strings.TrimSuffix(prefix, "/") + "/"
+	if !strings.HasPrefix(url, prefix) {
+		return ""
+	}
+
+	return strings.TrimPrefix(url, prefix)
+}
@@ -0,0 +1,55 @@
+package tracing
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware abre um span de servidor por requisição, continuando o trace
+// recebido no cabeçalho traceparent, e o disponibiliza em c.UserContext().
+func Middleware(c *fiber.Ctx) error {
+	carrier := propagation.HeaderCarrier(http.Header{})
+	c.Request().Header.VisitAll(func(key, value []byte) {
+		carrier.Set(string(key), string(value))
+	})
+
+	ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), carrier)
+	ctx, span := Tracer().Start(ctx, c.Method()+" "+c.Path(),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(c.Method()),
+			semconv.URLPath(c.Path()),
+			semconv.UserAgentOriginal(c.Get(fiber.HeaderUserAgent)),
+		),
+	)
+	defer span.End()
+
+	c.SetUserContext(ctx)
+
+	err := c.Next()
+	if err != nil {
+		if handlerErr := c.App().ErrorHandler(c, err); handlerErr != nil {
+			_ = c.SendStatus(fiber.StatusInternalServerError)
+		}
+		span.RecordError(err)
+	}
+
+	status := c.Response().StatusCode()
+	route := c.Route().Path
+	span.SetName(c.Method() + " " + route)
+	span.SetAttributes(
+		semconv.HTTPRoute(route),
+		semconv.HTTPResponseStatusCode(status),
+	)
+	if status >= fiber.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(status))
+	}
+
+	return nil
+}
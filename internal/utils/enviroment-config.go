@@ -1,15 +1,12 @@
 package utils
 
-import (
-	"os"
-)
+import "nexa/internal/config"
 
-func InitMailServer() *MailServer {
-	server := os.Getenv("SMTP_SERVER")
-	port := os.Getenv("SMTP_PORT")
-	user := os.Getenv("SMTP_USER")
-	password := os.Getenv("SMTP_PASSWORD")
-	from := os.Getenv("SMTP_FROM")
+// InitMailServer retorna nil quando o SMTP não está configurado.
+func InitMailServer(cfg config.SMTPConfig) *MailServer {
+	if !cfg.Enabled() {
+		return nil
+	}
 
-	return NewMailServer(server, port, from, user, password)
+	return NewMailServer(cfg.Server, cfg.Port, cfg.From, cfg.User, cfg.Password)
 }
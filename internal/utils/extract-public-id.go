@@ -2,10 +2,20 @@ package utils
 
 import (
 	"path"
+	"regexp"
 	"strings"
 )
 
+var cloudinaryVersion = regexp.MustCompile(`^v\d+/`)
+
+// ExtractPublicID devolve o public_id de uma URL do Cloudinary, incluindo as
+// pastas (ex.: ".../upload/v123/avatars/abc.jpg" -> "avatars/abc").
 func ExtractPublicID(url string) string {
+	if _, after, found := strings.Cut(url, "/upload/"); found {
+		after = cloudinaryVersion.ReplaceAllString(after, "")
+		return strings.TrimSuffix(after, path.Ext(after))
+	}
+
 	parts := strings.Split(url, "/")
 	if len(parts) == 0 {
 		return ""
@@ -2,8 +2,8 @@ package utils
 
 import (
 	"fmt"
+	"html/template"
 	"path/filepath"
-	"text/template"
 )
 
 func ParseFile(filePath string) (*template.Template, error) {
@@ -1,15 +1,42 @@
 package utils
 
-import "strings"
+import (
+	"strings"
+	"unicode"
+)
 
+// nameParticles ficam em minúsculas no meio do nome ("Maria da Silva").
+var nameParticles = map[string]bool{
+	"da": true, "das": true, "de": true, "di": true, "do": true, "dos": true, "e": true,
+}
+
+// FormatName remove espaços repetidos e capitaliza cada palavra, respeitando
+// acentos e as partículas comuns em nomes brasileiros.
 func FormatName(name string) string {
 	words := strings.Fields(name)
 
 	for i, word := range words {
-		if len(word) > 0 {
-			words[i] = strings.ToUpper(string(word[0])) + strings.ToLower(word[1:])
+		lower := strings.ToLower(word)
+		if i > 0 && nameParticles[lower] {
+			words[i] = lower
+			continue
 		}
+
+		runes := []rune(lower)
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = string(runes)
 	}
 
-	return name
+	return strings.Join(words, " ")
+}
+
+// SplitName separa um nome completo em primeiro nome e sobrenome.
+func SplitName(name string) (string, string) {
+	first, last, _ := strings.Cut(FormatName(name), " ")
+	return first, last
+}
+
+// JoinName monta o nome de exibição a partir do primeiro nome e sobrenome.
+func JoinName(firstName, lastName string) string {
+	return strings.TrimSpace(firstName + " " + lastName)
 }
@@ -1,200 +1,400 @@
 package utils
 
 import (
-	"fmt"
 	"net/http"
 	"nexa/internal/model"
-	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-func EncodeRequestError(c *fiber.Ctx, errorType string, path ...string) error {
-	errorResponse, exists := HTTPErrors[errorType]
-	if !exists {
-		if err := c.SendStatus(404); err != nil {
-			return fmt.Errorf("failed to send 404 status: %w", err)
-		}
-
-		return fmt.Errorf("error not found: %s", errorType)
-	}
-
-	if len(path) != 0 {
-		errorResponse.Path = path[0]
-	}
-
-	if err := c.Status(errorResponse.StatusCode).JSON(errorResponse); err != nil {
-		return fmt.Errorf("failed to send JSON with status %d: %w", errorResponse.StatusCode, err)
-	}
-
-	return nil
-}
-
+// HTTPErrors define status, tipo e campo de cada erro. As mensagens ficam no
+// catálogo de i18n, indexadas pela mesma chave.
 var HTTPErrors = map[string]model.ErrorResponse{
 	"INVALID_ID_USER": {
 		StatusCode: 400,
 		Error:      "Bad Request",
-		Message:    "formato de IDUser inválido",
-		Timestamp:  time.Now(),
 	},
 	"REQUIRED_EMAIL": {
 		StatusCode: 400,
 		Error:      "Bad Request",
-		Message:    "email is required.",
-		Timestamp:  time.Now(),
 		Input:      "email",
 	},
 	"USER_ALREADY_IN_WAIT_LIST": {
 		StatusCode: 409,
 		Error:      "Conflict",
-		Message:    "email already registered",
-		Timestamp:  time.Now(),
 		Input:      "email",
 	},
 	"USER_NOT_ALLOWED": {
 		StatusCode: 401,
 		Error:      "Unauthorized",
-		Message:    "wait-list",
-		Timestamp:  time.Now(),
 	},
 	"USER_ALREADY_REGISTERED": {
 		StatusCode: 403,
 		Error:      "Forbidden",
-		Message:    "E-mail já está em uso",
-		Timestamp:  time.Now(),
 		Input:      "email",
 	},
 	"USER_NOT_ACTIVE": {
-		StatusCode: 400,
-		Error:      "Bad Request",
-		Message:    "This user is not active.",
-		Timestamp:  time.Now(),
-		Path:       "/login",
+		StatusCode: fiber.StatusForbidden,
+		Error:      "Forbidden",
 	},
 	"USER_ALREADY_ACTIVE": {
 		StatusCode: fiber.StatusBadRequest,
 		Error:      "Bad Request",
-		Message:    "Usuário já está ativo.",
-		Timestamp:  time.Now(),
 	},
 	"INVALID_PHOTO": {
 		StatusCode: http.StatusBadRequest,
 		Error:      "Bad Request",
-		Message:    "failed to get image",
-		Timestamp:  time.Now(),
 		Input:      "photo",
 	},
 	"INVALID_LOGIN_CREDENTIALS": {
+		StatusCode: http.StatusUnauthorized,
+		Error:      "Unauthorized",
+		Input:      "email/password",
+	},
+	"REQUIRED_LOGIN_CREDENTIALS": {
 		StatusCode: http.StatusBadRequest,
 		Error:      "Bad Request",
-		Message:    "Credenciais não correspondentes",
-		Timestamp:  time.Now(),
 		Input:      "email/password",
 	},
 	"INVALID_USER_ID": {
 		StatusCode: fiber.StatusBadRequest,
 		Error:      "Bad Request",
-		Message:    "Dados de entrada inválidos. Verifique o formato e tente novamente.",
-		Timestamp:  time.Now(),
 	},
 	"INTERNAL_SERVER_ERROR": {
 		StatusCode: fiber.StatusInternalServerError,
 		Error:      "Internal Server Error",
-		Message:    "Ocorreu um erro no servidor.",
-		Timestamp:  time.Now(),
 	},
 	"USER_NOT_FOUND": {
 		StatusCode: fiber.StatusNotFound,
 		Error:      "Not Found",
-		Message:    "Usuário não encontrado.",
-		Timestamp:  time.Now(),
 	},
 	"INVALID_USER_AUTHENTICATION_TOKEN": {
 		StatusCode: fiber.StatusBadRequest,
 		Error:      "Bad Request",
-		Message:    "Código inválido.",
-		Timestamp:  time.Now(),
 	},
 	"EXPIRED_AUTHENTICATION_TOKEN": {
 		StatusCode: fiber.StatusBadRequest,
 		Error:      "Bad Request",
-		Message:    "Token de autenticação expirado.",
-		Timestamp:  time.Now(),
 	},
 	"EMPTY_USER": {
 		StatusCode: 400,
 		Error:      "Bad Request",
-		Message:    "empty user object is not allowed",
-		Timestamp:  time.Now(),
 		Input:      "user",
 	},
 	"FUTURE_DATE": {
 		StatusCode: 400,
 		Error:      "Bad Request",
-		Message:    "a future date is not allowed",
-		Timestamp:  time.Now(),
 		Input:      "date",
 	},
 	"MORE_THAN_500_CHARS": {
 		StatusCode: 400,
 		Error:      "Bad Request",
-		Message:    "O nome deve conter no máximo 500 caracteres",
-		Timestamp:  time.Now(),
 		Input:      "name",
 	},
 	"INVALID_AGE": {
 		StatusCode: 400,
 		Error:      "Bad Request",
-		Message:    "Idade mínima de 18 anos.",
-		Timestamp:  time.Now(),
 		Input:      "date",
 	},
 	"INVALID_DATE_FORMAT": {
 		StatusCode: 400,
 		Error:      "Bad Request",
-		Message:    "Formato de data inválido.",
-		Timestamp:  time.Now(),
 		Input:      "date",
 	},
 	"INVALID_NAME": {
 		StatusCode: 400,
 		Error:      "Bad Request",
-		Message:    "Nome não aceita números ou caracteres especiais.",
-		Timestamp:  time.Now(),
+		Input:      "name",
+	},
+	"NAME_TOO_LONG": {
+		StatusCode: 400,
+		Error:      "Bad Request",
 		Input:      "name",
 	},
 	"REQUIRED_NAME": {
 		StatusCode: 400,
 		Error:      "Bad Request",
-		Message:    "name is required",
-		Timestamp:  time.Now(),
 		Input:      "name",
 	},
 	"INVALID_PASSWORD": {
 		StatusCode: 400,
 		Error:      "Bad Request",
-		Message:    "A senha deve ter no mínimo 8 caracteres, sendo uma letra maiúscula, uma letra minúscula, um caractere especial e um número",
-		Timestamp:  time.Now(),
+		Input:      "password",
+	},
+	"BREACHED_PASSWORD": {
+		StatusCode: 400,
+		Error:      "Bad Request",
+		Input:      "password",
+	},
+	"PASSWORD_CONTAINS_PERSONAL_INFO": {
+		StatusCode: 400,
+		Error:      "Bad Request",
+		Input:      "password",
+	},
+	"LOW_ENTROPY_PASSWORD": {
+		StatusCode: 400,
+		Error:      "Bad Request",
 		Input:      "password",
 	},
 	"EMAIL_ALEADY_REGISTERED": {
 		StatusCode: http.StatusBadRequest,
 		Error:      "Bad Request",
-		Message:    "E-mail já está em uso",
-		Timestamp:  time.Now(),
 		Input:      "email",
 	},
 	"INVALID_EMAIL": {
 		StatusCode: http.StatusBadRequest,
 		Error:      "Bad Request",
-		Message:    "E-mail inválido",
-		Timestamp:  time.Now(),
 		Input:      "email",
 	},
 	"INVALID_BODY_FORMAT": {
 		StatusCode: http.StatusBadRequest,
 		Error:      "Bad Request",
-		Message:    "Formato de JSON inválido",
-		Timestamp:  time.Now(),
+	},
+	"REQUIRED_AUTHENTICATION_CODE": {
+		StatusCode: http.StatusBadRequest,
+		Error:      "Bad Request",
+		Input:      "code",
+	},
+	"INVALID_IMAGE_FORMAT": {
+		StatusCode: http.StatusBadRequest,
+		Error:      "Bad Request",
+		Input:      "image",
+	},
+	"INVALID_BANNER": {
+		StatusCode: http.StatusBadRequest,
+		Error:      "Bad Request",
+		Input:      "banner",
+	},
+	"REQUIRED_USERNAME": {
+		StatusCode: http.StatusBadRequest,
+		Error:      "Bad Request",
+		Input:      "username",
+	},
+	"INVALID_USERNAME": {
+		StatusCode: http.StatusBadRequest,
+		Error:      "Bad Request",
+		Input:      "username",
+	},
+	"USERNAME_ALREADY_TAKEN": {
+		StatusCode: http.StatusConflict,
+		Error:      "Conflict",
+		Input:      "username",
+	},
+	"REQUIRED_PASSWORD": {
+		StatusCode: http.StatusBadRequest,
+		Error:      "Bad Request",
+		Input:      "password",
+	},
+	"SAME_EMAIL": {
+		StatusCode: http.StatusBadRequest,
+		Error:      "Bad Request",
+		Input:      "email",
+	},
+	"REQUIRED_CODE": {
+		StatusCode: http.StatusBadRequest,
+		Error:      "Bad Request",
+		Input:      "code",
+	},
+	"NO_PENDING_EMAIL_CHANGE": {
+		StatusCode: http.StatusConflict,
+		Error:      "Conflict",
+	},
+	"EXPIRED_EMAIL_CHANGE_CODE": {
+		StatusCode: http.StatusGone,
+		Error:      "Gone",
+		Input:      "code",
+	},
+	"REQUIRED_USERNAME_OR_EMAIL": {
+		StatusCode: http.StatusBadRequest,
+		Error:      "Bad Request",
+	},
+	"INVALID_AVATAR_PRESET": {
+		StatusCode: http.StatusBadRequest,
+		Error:      "Bad Request",
+		Input:      "photoUrl",
+	},
+	"INVALID_BANNER_PRESET": {
+		StatusCode: http.StatusBadRequest,
+		Error:      "Bad Request",
+		Input:      "path",
+	},
+	"IMAGE_TOO_LARGE": {
+		StatusCode: http.StatusRequestEntityTooLarge,
+		Error:      "Request Entity Too Large",
+		Input:      "image",
+	},
+	"IMAGE_UPLOAD_FAILED": {
+		StatusCode: http.StatusBadGateway,
+		Error:      "Bad Gateway",
+	},
+	"DATA_EXPORT_NOT_FOUND": {
+		StatusCode: http.StatusNotFound,
+		Error:      "Not Found",
+	},
+	"REQUIRED_WALLET": {
+		StatusCode: http.StatusBadRequest,
+		Error:      "Bad Request",
+		Input:      "walletId",
+	},
+	"WALLET_NOT_FOUND": {
+		StatusCode: http.StatusNotFound,
+		Error:      "Not Found",
+		Input:      "walletId",
+	},
+	"INVALID_BANK": {
+		StatusCode: http.StatusBadRequest,
+		Error:      "Bad Request",
+		Input:      "bank",
+	},
+	"REQUIRED_STATEMENT_FILE": {
+		StatusCode: http.StatusBadRequest,
+		Error:      "Bad Request",
+		Input:      "file",
+	},
+	"STATEMENT_FILE_TOO_LARGE": {
+		StatusCode: http.StatusRequestEntityTooLarge,
+		Error:      "Request Entity Too Large",
+		Input:      "file",
+	},
+	"UNSUPPORTED_STATEMENT_FORMAT": {
+		StatusCode: http.StatusUnsupportedMediaType,
+		Error:      "Unsupported Media Type",
+		Input:      "file",
+	},
+	"INVALID_STATEMENT_FILE": {
+		StatusCode: http.StatusUnprocessableEntity,
+		Error:      "Unprocessable Entity",
+		Input:      "file",
+	},
+	"INVALID_IMPORT_MAPPING": {
+		StatusCode: http.StatusBadRequest,
+		Error:      "Bad Request",
+		Input:      "mapping",
+	},
+	"STATEMENT_IMPORT_NOT_FOUND": {
+		StatusCode: http.StatusNotFound,
+		Error:      "Not Found",
+	},
+	"NO_LEDGER_BALANCE": {
+		StatusCode: http.StatusUnprocessableEntity,
+		Error:      "Unprocessable Entity",
+		Input:      "reconcile",
+	},
+	"IMPORT_ALREADY_COMMITTED": {
+		StatusCode: http.StatusConflict,
+		Error:      "Conflict",
+	},
+	"INVALID_EXPORT_FORMAT": {
+		StatusCode: http.StatusBadRequest,
+		Error:      "Bad Request",
+		Input:      "format",
+	},
+	"INVALID_DATE_RANGE": {
+		StatusCode: http.StatusBadRequest,
+		Error:      "Bad Request",
+		Input:      "from/to",
+	},
+	"INVALID_CATEGORY": {
+		StatusCode: http.StatusBadRequest,
+		Error:      "Bad Request",
+		Input:      "categoryId",
+	},
+	"INVALID_TRANSACTION_TYPE": {
+		StatusCode: http.StatusBadRequest,
+		Error:      "Bad Request",
+		Input:      "type",
+	},
+	"INVALID_AMOUNT": {
+		StatusCode: http.StatusBadRequest,
+		Error:      "Bad Request",
+		Input:      "amount",
+	},
+	"INVALID_PAYMENT_METHOD": {
+		StatusCode: http.StatusBadRequest,
+		Error:      "Bad Request",
+		Input:      "paymentMethod",
+	},
+	"INVALID_RECURRENCE": {
+		StatusCode: http.StatusBadRequest,
+		Error:      "Bad Request",
+		Input:      "frequency",
+	},
+	"RECURRING_TRANSACTION_NOT_FOUND": {
+		StatusCode: http.StatusNotFound,
+		Error:      "Not Found",
+	},
+	"OCCURRENCE_NOT_FOUND": {
+		StatusCode: http.StatusNotFound,
+		Error:      "Not Found",
+		Input:      "date",
+	},
+	"SAME_WALLET_TRANSFER": {
+		StatusCode: http.StatusBadRequest,
+		Error:      "Bad Request",
+		Input:      "toWalletId",
+	},
+	"TRANSFER_NOT_FOUND": {
+		StatusCode: http.StatusNotFound,
+		Error:      "Not Found",
+	},
+	"INVALID_MONTH": {
+		StatusCode: http.StatusBadRequest,
+		Error:      "Bad Request",
+		Input:      "month",
+	},
+	"INVALID_WALLET_NAME": {
+		StatusCode: http.StatusBadRequest,
+		Error:      "Bad Request",
+		Input:      "name",
+	},
+	"INVALID_CURRENCY": {
+		StatusCode: http.StatusBadRequest,
+		Error:      "Bad Request",
+		Input:      "currency",
+	},
+	"INVALID_EXCHANGE_RATE": {
+		StatusCode: http.StatusBadRequest,
+		Error:      "Bad Request",
+		Input:      "rate",
+	},
+	"EXCHANGE_RATE_NOT_FOUND": {
+		StatusCode: http.StatusUnprocessableEntity,
+		Error:      "Unprocessable Entity",
+		Input:      "currency",
+	},
+	"TOO_MANY_EXCHANGE_RATES": {
+		StatusCode: http.StatusRequestEntityTooLarge,
+		Error:      "Request Entity Too Large",
+	},
+	"REQUIRED_RATES_FILE": {
+		StatusCode: http.StatusBadRequest,
+		Error:      "Bad Request",
+		Input:      "file",
+	},
+	"RATES_FILE_TOO_LARGE": {
+		StatusCode: http.StatusRequestEntityTooLarge,
+		Error:      "Request Entity Too Large",
+		Input:      "file",
+	},
+	"INVALID_RATES_FILE": {
+		StatusCode: http.StatusUnprocessableEntity,
+		Error:      "Unprocessable Entity",
+		Input:      "file",
+	},
+	"UNAUTHORIZED": {
+		StatusCode: http.StatusUnauthorized,
+		Error:      "Unauthorized",
+	},
+	"INVALID_TOKEN": {
+		StatusCode: http.StatusUnauthorized,
+		Error:      "Unauthorized",
+	},
+	"FORBIDDEN_USER": {
+		StatusCode: http.StatusForbidden,
+		Error:      "Forbidden",
+	},
+	"FORBIDDEN_ADMIN": {
+		StatusCode: http.StatusForbidden,
+		Error:      "Forbidden",
 	},
 }
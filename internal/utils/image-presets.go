@@ -0,0 +1,34 @@
+package utils
+
+import "slices"
+
+// Rotas onde os presets embutidos (assets/avatars e assets/banners) são servidos.
+const (
+	AvatarPresetsRoute = "/avatars"
+	BannerPresetsRoute = "/banners"
+)
+
+// AvatarPresets e BannerPresets são as imagens que o usuário pode escolher
+// sem fazer upload. O valor salvo no usuário é o path público do preset.
+var AvatarPresets = []string{
+	AvatarPresetsRoute + "/azul.svg",
+	AvatarPresetsRoute + "/cinza.svg",
+	AvatarPresetsRoute + "/laranja.svg",
+	AvatarPresetsRoute + "/verde.svg",
+}
+
+var BannerPresets = []string{
+	BannerPresetsRoute + "/aurora.svg",
+	BannerPresetsRoute + "/floresta.svg",
+	BannerPresetsRoute + "/grafite.svg",
+	BannerPresetsRoute + "/oceano.svg",
+	BannerPresetsRoute + "/por-do-sol.svg",
+}
+
+func IsAvatarPreset(path string) bool {
+	return slices.Contains(AvatarPresets, path)
+}
+
+func IsBannerPreset(path string) bool {
+	return slices.Contains(BannerPresets, path)
+}
@@ -1,18 +1,33 @@
 package utils
 
 import (
+	"bytes"
+	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"net"
 	"net/smtp"
+	"nexa/internal/i18n"
+	"nexa/internal/metrics"
+	"nexa/internal/tracing"
+	"sync"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
+var ErrMailServerClosed = errors.New("mail server is closed")
+
 type MailServer struct {
 	Server string
 	Port   string
 	Auth   smtp.Auth
 	From   string
+
+	mu       sync.Mutex
+	closed   bool
+	inFlight sync.WaitGroup
 }
 
 func NewMailServer(server, port, from, user, password string) *MailServer {
@@ -26,7 +41,47 @@ func NewMailServer(server, port, from, user, password string) *MailServer {
 	}
 }
 
-func (ms *MailServer) SendEmailHTML(subject, html string, to []string) error {
+// SendTemplateEmail renderiza assets/email/<locale>/<templateName> com data e
+// envia com o assunto traduzido de subjectCode. Aceita receiver nil (SMTP não
+// configurado) e retorna erro nesse caso.
+func (ms *MailServer) SendTemplateEmail(ctx context.Context, locale, templateName, subjectCode, to string, data any) error {
+	if ms == nil {
+		return fmt.Errorf("mail server is not configured")
+	}
+
+	template, err := ParseFile(i18n.EmailTemplatePath(locale, templateName))
+	if err != nil {
+		return err
+	}
+
+	var body bytes.Buffer
+	if err := template.Execute(&body, data); err != nil {
+		return err
+	}
+
+	return ms.SendEmailHTML(ctx, i18n.Translate(locale, subjectCode), body.String(), []string{to})
+}
+
+func (ms *MailServer) SendEmailHTML(ctx context.Context, subject, html string, to []string) (err error) {
+	_, span := tracing.Start(ctx, "smtp.send",
+		attribute.String("smtp.server", ms.Server),
+		attribute.Int("smtp.recipients", len(to)),
+	)
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+		metrics.RecordEmail(err == nil)
+	}()
+
+	ms.mu.Lock()
+	if ms.closed {
+		ms.mu.Unlock()
+		return ErrMailServerClosed
+	}
+	ms.inFlight.Add(1)
+	ms.mu.Unlock()
+	defer ms.inFlight.Done()
+
 	client, err := ms.connect()
 	if err != nil {
 		return fmt.Errorf("failed to connect to mail server: %s", err)
@@ -64,6 +119,39 @@ func (ms *MailServer) SendEmailHTML(subject, html string, to []string) error {
 	return nil
 }
 
+// Close recusa novos envios e aguarda os envios em andamento terminarem,
+// limitado pelo prazo do contexto.
+func (ms *MailServer) Close(ctx context.Context) error {
+	ms.mu.Lock()
+	ms.closed = true
+	ms.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		ms.inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("timed out waiting for pending emails: %w", ctx.Err())
+	}
+}
+
+// Ping verifica apenas se o servidor SMTP aceita conexões TCP.
+func (ms *MailServer) Ping(ctx context.Context) error {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+
+	conn, err := dialer.DialContext(ctx, "tcp", ms.Server+":"+ms.Port)
+	if err != nil {
+		return fmt.Errorf("mail server unreachable: %s", err)
+	}
+
+	return conn.Close()
+}
+
 func (ms *MailServer) connect() (*smtp.Client, error) {
 	conn, err := ms.newConnection()
 	if err != nil {
@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"errors"
+	"net/http"
+	"nexa/internal/i18n"
+	"nexa/internal/logger"
+	"nexa/internal/model"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequestError é o erro retornado pelos handlers. Code é uma chave de
+// HTTPErrors; Cause guarda o erro original apenas para log e nunca é
+// enviado ao cliente.
+type RequestError struct {
+	Code    string
+	Cause   error
+	Details []string
+	IDUser  string
+}
+
+func NewRequestError(code string, cause ...error) *RequestError {
+	return &RequestError{
+		Code:  code,
+		Cause: errors.Join(cause...),
+	}
+}
+
+// NewRequestErrors agrupa várias violações: a primeira define o status e a
+// mensagem principal, todas aparecem em Details.
+func NewRequestErrors(codes []string) *RequestError {
+	return &RequestError{
+		Code:    codes[0],
+		Details: codes,
+	}
+}
+
+func (e *RequestError) WithUserID(idUser string) *RequestError {
+	e.IDUser = idUser
+	return e
+}
+
+func (e *RequestError) Error() string {
+	if e.Cause != nil {
+		return e.Code + ": " + e.Cause.Error()
+	}
+	return e.Code
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Cause
+}
+
+// ErrorHandler é o fiber.Config.ErrorHandler da aplicação: todo erro vira o
+// envelope model.ErrorResponse com o path da requisição e o horário atual.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	response := buildErrorResponse(err, i18n.FromCtx(c))
+	response.Path = c.Path()
+	response.Timestamp = time.Now()
+
+	if response.StatusCode >= fiber.StatusInternalServerError {
+		logger.FromCtx(c).Error().Err(err).Msg("request failed")
+	}
+
+	return c.Status(response.StatusCode).JSON(response)
+}
+
+func buildErrorResponse(err error, locale string) model.ErrorResponse {
+	var requestError *RequestError
+	if errors.As(err, &requestError) {
+		response, exists := HTTPErrors[requestError.Code]
+		if !exists {
+			return internalServerError(locale)
+		}
+
+		response.Code = requestError.Code
+		response.Message = i18n.Translate(locale, requestError.Code)
+		response.IDUser = requestError.IDUser
+		for _, code := range requestError.Details {
+			response.Details = append(response.Details, model.ErrorDetail{
+				Code:    code,
+				Message: i18n.Translate(locale, code),
+			})
+		}
+
+		return response
+	}
+
+	var fiberError *fiber.Error
+	if errors.As(err, &fiberError) {
+		return model.ErrorResponse{
+			StatusCode: fiberError.Code,
+			Error:      http.StatusText(fiberError.Code),
+			Message:    fiberError.Message,
+		}
+	}
+
+	return internalServerError(locale)
+}
+
+func internalServerError(locale string) model.ErrorResponse {
+	response := HTTPErrors["INTERNAL_SERVER_ERROR"]
+	response.Code = "INTERNAL_SERVER_ERROR"
+	response.Message = i18n.Translate(locale, response.Code)
+	return response
+}